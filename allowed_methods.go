@@ -0,0 +1,249 @@
+package rest
+
+import (
+	"context"
+	"net/http"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/ehsoc/rest/encdec"
+)
+
+// HTTPMethods is the list of the HTTP methods supported by the resources, in the order used for the Allow header.
+var HTTPMethods = []string{
+	http.MethodGet,
+	http.MethodPost,
+	http.MethodPut,
+	http.MethodPatch,
+	http.MethodDelete,
+	http.MethodHead,
+	http.MethodOptions,
+	http.MethodConnect,
+	http.MethodTrace,
+}
+
+// EnableAutoHead enables an automatic HEAD method for the resources with a GET method and without a declared HEAD method.
+// The HEAD method runs the GET method handler, discarding the response body.
+// As the middleware, it will be applied to the methods declared after the call, and will be passed down to the child resources.
+func (rs *ResourceCollection) EnableAutoHead() {
+	rs.autoHead = true
+}
+
+// EnableAutoOptions enables an automatic OPTIONS method for the resources without a declared OPTIONS method.
+// The OPTIONS method responds with a 204 (No Content) code and the Allow header listing the resource methods.
+// As the middleware, it will be applied to the methods declared after the call, and will be passed down to the child resources.
+func (rs *ResourceCollection) EnableAutoOptions() {
+	rs.autoOptions = true
+}
+
+// AllowedMethods returns the HTTP methods of the resource, including the automatic HEAD and OPTIONS methods if enabled,
+// in the HTTPMethods order.
+func (rs *Resource) AllowedMethods() []string {
+	rs.checkNilMethods()
+	allowed := []string{}
+	for httpMethod := range rs.methods {
+		allowed = append(allowed, httpMethod)
+	}
+
+	for httpMethod := range rs.autoMethods {
+		allowed = append(allowed, httpMethod)
+	}
+
+	sort.Slice(allowed, func(i, j int) bool {
+		if methodOrder(allowed[i]) == methodOrder(allowed[j]) {
+			return allowed[i] < allowed[j]
+		}
+		return methodOrder(allowed[i]) < methodOrder(allowed[j])
+	})
+
+	return allowed
+}
+
+// NotAllowedMethods returns the HTTPMethods that are not allowed by the resource.
+// A ServerGenerator implementation should route them to the MethodNotAllowedHandler,
+// so the response doesn't depend on the router. It returns an empty slice if the resource has no methods.
+func (rs *Resource) NotAllowedMethods() []string {
+	allowed := rs.AllowedMethods()
+	notAllowed := []string{}
+
+	if len(allowed) == 0 {
+		return notAllowed
+	}
+
+	for _, httpMethod := range HTTPMethods {
+		if !containsString(allowed, httpMethod) {
+			notAllowed = append(notAllowed, httpMethod)
+		}
+	}
+
+	return notAllowed
+}
+
+// MethodNotAllowedHandler returns a handler responding with a 405 (Method Not Allowed) code
+// and the Allow header listing the resource allowed methods.
+// The CORS preflight requests to the resource methods with a CORS policy are answered by the handler.
+func (rs *Resource) MethodNotAllowedHandler() http.Handler {
+	return rs.preflightHandler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Allow", strings.Join(rs.AllowedMethods(), ", "))
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	}))
+}
+
+// methodOrder returns the position of the method in HTTPMethods, other methods are placed after them.
+func methodOrder(httpMethod string) int {
+	for i, m := range HTTPMethods {
+		if m == httpMethod {
+			return i
+		}
+	}
+
+	return len(HTTPMethods)
+}
+
+// containsString reports whether the value is in values, like a method or a header name in a list.
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+
+	return false
+}
+
+// autoMethodOptions are the resource options, at the moment of enabling an automatic method, to be applied to it.
+type autoMethodOptions struct {
+	middleware       []Middleware
+	errorMappings    errorMappingCollection
+	corsPolicy       *CORSPolicy
+	contentEncodings *ContentEncodings
+	cachePolicy      *CachePolicy
+	// securityMiddleware is the resource core security middleware replacement, nil for the default one
+	securityMiddleware Middleware
+}
+
+// addAutoMethods registers the automatic methods enabled in the resource after adding the method.
+// The automatic methods are built when the resource methods are requested, so they reflect
+// the changes done to the declared methods after being added.
+func (rs *Resource) addAutoMethods(httpMethod string) {
+	switch httpMethod {
+	case http.MethodHead, http.MethodOptions:
+		// a declared method replaces the automatic one
+		delete(rs.autoMethods, httpMethod)
+		return
+	}
+
+	if rs.autoHead && httpMethod == http.MethodGet {
+		if _, ok := rs.methods[http.MethodHead]; !ok {
+			rs.autoMethods[http.MethodHead] = autoMethodOptions{}
+		}
+	}
+
+	if rs.autoOptions {
+		if _, ok := rs.methods[http.MethodOptions]; !ok {
+			rs.autoMethods[http.MethodOptions] = autoMethodOptions{
+				middleware:         append([]Middleware{}, rs.middleware...),
+				errorMappings:      append(errorMappingCollection{}, rs.errorMappings...),
+				corsPolicy:         rs.corsPolicy,
+				contentEncodings:   rs.contentEncodings,
+				cachePolicy:        rs.cachePolicy,
+				securityMiddleware: rs.overWriteCoreSecurityMiddleware,
+			}
+		}
+	}
+}
+
+// autoMethod builds the automatic HEAD or OPTIONS method.
+// The HEAD method is a copy of the GET method, with the middleware already applied.
+func (rs *Resource) autoMethod(httpMethod string, options autoMethodOptions) Method {
+	if httpMethod == http.MethodHead {
+		head := *rs.methods[http.MethodGet]
+		head.HTTPMethod = http.MethodHead
+		head.Handler = discardBodyHandler(head.Handler)
+
+		return head
+	}
+
+	return rs.autoOptionsMethod(options)
+}
+
+func (rs *Resource) autoOptionsMethod(options autoMethodOptions) Method {
+	declared := []*Method{}
+	for _, m := range rs.methods {
+		declared = append(declared, m)
+	}
+
+	sort.Slice(declared, func(i, j int) bool {
+		return methodOrder(strings.ToUpper(declared[i].HTTPMethod)) < methodOrder(strings.ToUpper(declared[j].HTTPMethod))
+	})
+
+	operation := OperationFunc(func(i Input) (interface{}, bool, error) {
+		i.ResponseHeader().Set("Allow", strings.Join(rs.AllowedMethods(), ", "))
+		return nil, true, nil
+	})
+	response := NewResponse(http.StatusNoContent).
+		WithDescription("Allowed methods").
+		WithHeader("Allow", ResponseHeader{Description: "The allowed methods of the resource", Type: reflect.String})
+	// the method has no request body nor response body, so it has no content types to negotiate,
+	// and it never responds with the unsupported media type and not acceptable responses
+	ct := NewContentTypes()
+	ct.UnsupportedMediaTypeResponse = Response{}
+	ct.NotAcceptableResponse = Response{}
+	m := NewMethod(http.MethodOptions, NewMethodOperation(operation, response), ct)
+	m.Summary = "Allowed methods"
+	// the URI parameters are part of the path of every method
+	for _, d := range declared {
+		for _, p := range d.Parameters() {
+			if p.HTTPType == URIParameter {
+				m.AddParameter(p)
+			}
+		}
+	}
+
+	// the resource options are applied as AddMethod does, and the problem details setting of the API
+	// is shared by all the declared methods
+	m.middleware = options.middleware
+	m.errorMappings = options.errorMappings
+	m.corsPolicy = options.corsPolicy
+	m.contentEncodings = options.contentEncodings
+	m.cachePolicy = options.cachePolicy
+	m.problemDetails = declared[0].problemDetails
+	m.negotiationMw = m.allowedMethodsMiddleware
+	m.securityMw = m.securityMiddleware
+	if options.securityMiddleware != nil {
+		m.securityMw = options.securityMiddleware
+	}
+
+	m.buildDefaultCoreMiddlewareStack()
+	m.buildHandler()
+
+	return *m
+}
+
+// allowedMethodsMiddleware replaces the negotiation middleware of the automatic OPTIONS method,
+// so it answers with the Allow header whatever the Accept header and the request body are.
+// The error responses, like the security ones, are written with a text encoder.
+func (m *Method) allowedMethodsMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := context.WithValue(r.Context(), EncoderDecoderContextKey("encoder"), encdec.TextEncoder{})
+		ctx = context.WithValue(ctx, InputContextKey("body"), &requestBodyCache{})
+		ctx = context.WithValue(ctx, InputContextKey("responseheader"), w.Header())
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+// discardBodyHandler runs the next handler, discarding the response body.
+func discardBodyHandler(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		next.ServeHTTP(discardBodyWriter{w}, r)
+	})
+}
+
+type discardBodyWriter struct {
+	http.ResponseWriter
+}
+
+func (w discardBodyWriter) Write(b []byte) (int, error) {
+	return len(b), nil
+}
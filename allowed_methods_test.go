@@ -0,0 +1,228 @@
+package rest_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/ehsoc/rest"
+)
+
+func TestAllowedMethods(t *testing.T) {
+	ct := mustGetJSONContentType()
+	t.Run("declared methods", func(t *testing.T) {
+		r := rest.NewResource("car")
+		r.Delete(moTest, ct)
+		r.Post(moTest, ct)
+		r.Get(moTest, ct)
+		assertStringSlice(t, r.AllowedMethods(), []string{"GET", "POST", "DELETE"})
+		assertStringSlice(t, r.NotAllowedMethods(), []string{"PUT", "PATCH", "HEAD", "OPTIONS", "CONNECT", "TRACE"})
+		if len(r.Methods()) != 3 {
+			t.Errorf("got: %v want: %v", len(r.Methods()), 3)
+		}
+	})
+	t.Run("no methods", func(t *testing.T) {
+		r := rest.NewResource("car")
+		assertStringSlice(t, r.AllowedMethods(), []string{})
+		assertStringSlice(t, r.NotAllowedMethods(), []string{})
+	})
+	t.Run("automatic methods", func(t *testing.T) {
+		r := rest.NewResource("car")
+		r.EnableAutoHead()
+		r.EnableAutoOptions()
+		r.Post(moTest, ct)
+		assertStringSlice(t, r.AllowedMethods(), []string{"POST", "OPTIONS"})
+		r.Get(moTest, ct)
+		assertStringSlice(t, r.AllowedMethods(), []string{"GET", "POST", "HEAD", "OPTIONS"})
+		if len(r.Methods()) != 4 {
+			t.Errorf("got: %v want: %v", len(r.Methods()), 4)
+		}
+	})
+	t.Run("declared methods replace the automatic ones", func(t *testing.T) {
+		r := rest.NewResource("car")
+		r.EnableAutoHead()
+		r.EnableAutoOptions()
+		r.Get(moTest, ct)
+		r.Head(moTest, ct).WithSummary("declared")
+		r.Options(moTest, ct).WithSummary("declared")
+		for _, m := range r.Methods() {
+			if m.HTTPMethod != http.MethodGet && m.Summary != "declared" {
+				t.Errorf("expecting declared %s method", m.HTTPMethod)
+			}
+		}
+		assertStringSlice(t, r.AllowedMethods(), []string{"GET", "HEAD", "OPTIONS"})
+	})
+	t.Run("inherited by child resources", func(t *testing.T) {
+		api := rest.API{}
+		api.EnableAutoOptions()
+		api.Resource("car", func(r *rest.Resource) {
+			r.Resource("wheels", func(r *rest.Resource) {
+				r.Put(moTest, ct)
+			})
+		})
+		wheels := api.Resources()[0].Resources()[0]
+		assertStringSlice(t, wheels.AllowedMethods(), []string{"PUT", "OPTIONS"})
+	})
+}
+
+func TestMethodNotAllowedHandler(t *testing.T) {
+	r := rest.NewResource("car")
+	r.Get(moTest, mustGetJSONContentType())
+	r.Put(moTest, mustGetJSONContentType())
+	req, _ := http.NewRequest(http.MethodDelete, "/", nil)
+	resp := httptest.NewRecorder()
+	r.MethodNotAllowedHandler().ServeHTTP(resp, req)
+	assertResponseCode(t, resp, http.StatusMethodNotAllowed)
+	assertStringEqual(t, resp.Header().Get("Allow"), "GET, PUT")
+}
+
+func TestAutoMethodsHandlers(t *testing.T) {
+	getOperation := rest.OperationFunc(func(i rest.Input) (interface{}, bool, error) {
+		i.ResponseHeader().Set("X-Car", "1")
+		return Car{ID: 1}, true, nil
+	})
+	called := []string{}
+	r := rest.NewResource("car")
+	r.EnableAutoHead()
+	r.EnableAutoOptions()
+	r.Use(func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			called = append(called, r.Method)
+			next.ServeHTTP(w, r)
+		})
+	})
+	r.Get(rest.NewMethodOperation(getOperation, rest.NewResponse(200).WithOperationResultBody(Car{})), mustGetJSONContentType())
+	methods := map[string]rest.Method{}
+	for _, m := range r.Methods() {
+		methods[m.HTTPMethod] = m
+	}
+	t.Run("head", func(t *testing.T) {
+		req, _ := http.NewRequest(http.MethodHead, "/", nil)
+		resp := httptest.NewRecorder()
+		methods[http.MethodHead].ServeHTTP(resp, req)
+		assertResponseCode(t, resp, http.StatusOK)
+		assertStringEqual(t, resp.Header().Get("X-Car"), "1")
+		assertStringEqual(t, resp.Header().Get("Content-Type"), "application/json")
+		if resp.Body.Len() != 0 {
+			t.Errorf("was not expecting a body, got: %q", resp.Body.String())
+		}
+	})
+	t.Run("options", func(t *testing.T) {
+		req, _ := http.NewRequest(http.MethodOptions, "/", nil)
+		resp := httptest.NewRecorder()
+		methods[http.MethodOptions].ServeHTTP(resp, req)
+		assertResponseCode(t, resp, http.StatusNoContent)
+		assertStringEqual(t, resp.Header().Get("Allow"), "GET, HEAD, OPTIONS")
+	})
+	assertStringSlice(t, called, []string{http.MethodHead, http.MethodOptions})
+}
+
+func TestAutoOptionsInheritance(t *testing.T) {
+	api := rest.API{ProblemDetails: true}
+	api.EnableAutoOptions()
+	api.MapError(rest.ErrorIs(ErrNotFound), rest.NewResponse(404))
+	api.UseContentEncodings(rest.NewContentEncodings())
+	api.UseCachePolicy(rest.CachePolicy{Public: true, MaxAge: 60})
+	api.Resource("car", func(r *rest.Resource) {
+		r.Get(moTest, mustGetJSONContentType())
+	})
+	api.GenerateServer(MethodGenStub{})
+	var options rest.Method
+	for _, m := range api.Resources()[0].Methods() {
+		if m.HTTPMethod == http.MethodOptions {
+			options = m
+		}
+	}
+	assertStringSlice(t, options.GetContentEncodings(), []string{"gzip", "deflate"})
+	codes := []int{}
+	for _, resp := range options.Responses() {
+		codes = append(codes, resp.Code())
+	}
+	if !containsCode(codes, 404) {
+		t.Errorf("expecting the mapped error response, got: %v", codes)
+	}
+	t.Run("cache policy", func(t *testing.T) {
+		req, _ := http.NewRequest(http.MethodOptions, "/", nil)
+		resp := httptest.NewRecorder()
+		options.ServeHTTP(resp, req)
+		assertResponseCode(t, resp, http.StatusNoContent)
+		assertStringEqual(t, resp.Header().Get("Cache-Control"), "public, max-age=60")
+	})
+	t.Run("problem details", func(t *testing.T) {
+		for _, resp := range options.Responses() {
+			if _, ok := resp.Body().(*rest.ProblemDetails); ok != (resp.Code() >= 400) {
+				t.Errorf("response %d: got problem details body: %v", resp.Code(), ok)
+			}
+		}
+	})
+}
+
+func TestAutoOptionsNegotiation(t *testing.T) {
+	ct := mustGetJSONContentType()
+	ct.RequireBody()
+	r := rest.NewResource("car")
+	r.EnableAutoOptions()
+	r.OverwriteCoreSecurityMiddleware(func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("X-Security", "custom")
+			next.ServeHTTP(w, r)
+		})
+	})
+	r.Post(moTest, ct).WithRequestBody("car", Car{})
+	var options rest.Method
+	for _, m := range r.Methods() {
+		if m.HTTPMethod == http.MethodOptions {
+			options = m
+		}
+	}
+	tt := []struct {
+		name   string
+		header map[string]string
+		body   string
+	}{
+		{"no body", nil, ""},
+		{"unacceptable", map[string]string{"Accept": "application/xml"}, ""},
+		{"unsupported body", map[string]string{"Content-Type": "application/xml"}, "<car/>"},
+	}
+	for _, test := range tt {
+		t.Run(test.name, func(t *testing.T) {
+			req, _ := http.NewRequest(http.MethodOptions, "/", nil)
+			if test.body != "" {
+				req, _ = http.NewRequest(http.MethodOptions, "/", strings.NewReader(test.body))
+			}
+			for k, v := range test.header {
+				req.Header.Set(k, v)
+			}
+			resp := httptest.NewRecorder()
+			options.ServeHTTP(resp, req)
+			assertResponseCode(t, resp, http.StatusNoContent)
+			assertStringEqual(t, resp.Header().Get("Allow"), "POST, OPTIONS")
+			assertStringEqual(t, resp.Header().Get("X-Security"), "custom")
+		})
+	}
+	codes := []int{}
+	for _, resp := range options.Responses() {
+		codes = append(codes, resp.Code())
+	}
+	if containsCode(codes, 400) || containsCode(codes, 406) || containsCode(codes, 415) {
+		t.Errorf("not expecting negotiation responses, got: %v", codes)
+	}
+}
+
+func containsCode(codes []int, code int) bool {
+	for _, c := range codes {
+		if c == code {
+			return true
+		}
+	}
+	return false
+}
+
+func assertStringSlice(t *testing.T, got, want []string) {
+	t.Helper()
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got: %v want: %v", got, want)
+	}
+}
@@ -17,6 +17,10 @@ type API struct {
 	Title       string
 	Host        string
 	BasePath    string
+	// ProblemDetails enables the RFC 7807 problem details body (application/problem+json) for all the error responses
+	// without a body written by the methods handlers, including the negotiation, security, validation and internal
+	// server error responses. It is applied to the methods by the GenerateServer and GenerateSpec methods.
+	ProblemDetails bool
 	ResourceCollection
 }
 
@@ -36,12 +40,14 @@ func NewAPI(basePath, hostname, title, version string) API {
 // GenerateSpec will generate the API specification using APISpecGenerator interface implementation (g),
 // and will write into a io.Writer implementation (w)
 func (a API) GenerateSpec(w io.Writer, g APISpecGenerator) {
+	setProblemDetails(a.resources, a.ProblemDetails)
 	g.GenerateAPISpec(w, a)
 }
 
 // GenerateServer generates a http.Handler using a ServerGenerator implementation (g)
 func (a API) GenerateServer(g ServerGenerator) http.Handler {
 	resourcesCheck(a.resources)
+	setProblemDetails(a.resources, a.ProblemDetails)
 	server := g.GenerateServer(a)
 
 	return inputGetFunctionsMiddleware(g.GetURIParam(), server)
@@ -61,6 +67,8 @@ func resourcesCheck(res map[string]Resource) {
 				httpResponseCodeCheck(resp.Code(), m.HTTPMethod, resource.path)
 				parameterOperationCheck(m, resource.path)
 			}
+
+			requestBodyDecoderCheck(m, resource.path)
 		}
 
 		resourcesCheck(resource.resources)
@@ -75,6 +83,13 @@ func httpResponseCodeCheck(code int, httpMethod string, path string) {
 	}
 }
 
+// A method with a request body, or a required one, needs a decoder to read it.
+func requestBodyDecoderCheck(m *Method, path string) {
+	if (m.RequestBody.Body != nil || m.contentTypes.BodyRequired()) && !m.contentTypes.AcceptsBody() {
+		panic(fmt.Sprintf("GenerateServer check error: resource %s method %s has a request body, but no decoder.", path, m.HTTPMethod))
+	}
+}
+
 func parameterOperationCheck(m *Method, path string) {
 	if m.MethodOperation.Operation == nil {
 		panic(fmt.Sprintf("GenerateServer check error: resource %s method %s doesn't have an operation.", path, m.HTTPMethod))
@@ -8,6 +8,7 @@ import (
 	"testing"
 
 	"github.com/ehsoc/rest"
+	"github.com/ehsoc/rest/encdec"
 )
 
 type GenStub struct {
@@ -56,3 +57,30 @@ func TestGenerateSpec(t *testing.T) {
 		t.Errorf("Expecting function called")
 	}
 }
+
+func TestRequestBodyDecoderCheck(t *testing.T) {
+	operation := rest.OperationFunc(func(i rest.Input) (interface{}, bool, error) {
+		return nil, true, nil
+	})
+	ct := rest.NewContentTypes()
+	ct.AddEncoder("application/json", encdec.JSONEncoder{}, true)
+	t.Run("request body without decoder", func(t *testing.T) {
+		api := rest.API{}
+		api.Resource("car", func(r *rest.Resource) {
+			r.Post(rest.NewMethodOperation(operation, rest.NewResponse(201)), ct).WithRequestBody("car", Car{})
+		})
+		defer func() {
+			if recover() == nil {
+				t.Errorf("The code did not panic")
+			}
+		}()
+		api.GenerateServer(&GenStub{})
+	})
+	t.Run("no request body", func(t *testing.T) {
+		api := rest.API{}
+		api.Resource("car", func(r *rest.Resource) {
+			r.Post(rest.NewMethodOperation(operation, rest.NewResponse(201)), ct)
+		})
+		api.GenerateServer(&GenStub{})
+	})
+}
@@ -0,0 +1,137 @@
+package rest
+
+import (
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// CachePolicy describes the Cache-Control header of the successful responses of a method (RFC 7234).
+// The durations are in seconds, and a zero duration is omitted.
+type CachePolicy struct {
+	// Public allows any cache to store the response, even if it is normally not cacheable, like an authenticated response.
+	Public bool
+	// Private allows only the client cache to store the response.
+	Private bool
+	// NoCache requires the caches to validate the stored response with the server before using it.
+	NoCache bool
+	// NoStore forbids any cache to store the response.
+	NoStore bool
+	// MustRevalidate forbids the caches to use a stale response without validating it with the server.
+	MustRevalidate bool
+	// MaxAge is the time the response is fresh.
+	MaxAge int
+	// SharedMaxAge is the time the response is fresh for the shared caches, overriding MaxAge.
+	SharedMaxAge int
+	// StaleWhileRevalidate is the time a stale response can be used while it is validated in the background (RFC 5861).
+	StaleWhileRevalidate int
+	// StaleIfError is the time a stale response can be used if the server responds with an error (RFC 5861).
+	StaleIfError int
+	// Immutable means that the response will not change while it is fresh (RFC 8246).
+	Immutable bool
+}
+
+// String returns the Cache-Control header value of the policy.
+func (p CachePolicy) String() string {
+	directives := []string{}
+	flags := []struct {
+		set       bool
+		directive string
+	}{
+		{p.Public, "public"},
+		{p.Private, "private"},
+		{p.NoCache, "no-cache"},
+		{p.NoStore, "no-store"},
+		{p.MustRevalidate, "must-revalidate"},
+	}
+
+	for _, f := range flags {
+		if f.set {
+			directives = append(directives, f.directive)
+		}
+	}
+
+	durations := []struct {
+		seconds   int
+		directive string
+	}{
+		{p.MaxAge, "max-age"},
+		{p.SharedMaxAge, "s-maxage"},
+		{p.StaleWhileRevalidate, "stale-while-revalidate"},
+		{p.StaleIfError, "stale-if-error"},
+	}
+
+	for _, d := range durations {
+		if d.seconds > 0 {
+			directives = append(directives, d.directive+"="+strconv.Itoa(d.seconds))
+		}
+	}
+
+	if p.Immutable {
+		directives = append(directives, "immutable")
+	}
+
+	return strings.Join(directives, ", ")
+}
+
+// UseCachePolicy sets the cache policy of the methods declared after the call.
+// The policy is inherited by the child resources, which can set their own, and a method can set its own with WithCachePolicy.
+func (rs *ResourceCollection) UseCachePolicy(policy CachePolicy) {
+	rs.cachePolicy = &policy
+}
+
+// WithCachePolicy sets the cache policy of the method, setting the Cache-Control header of the successful responses,
+// unless the Operation sets it with Input.ResponseHeader.
+func (m *Method) WithCachePolicy(policy CachePolicy) *Method {
+	m.cachePolicy = &policy
+	return m
+}
+
+// setCacheControl sets the Cache-Control header of the cache policy, if there is one and the header is not set.
+func (m *Method) setCacheControl(header http.Header) {
+	if m.cachePolicy == nil || header.Get("Cache-Control") != "" {
+		return
+	}
+
+	if policy := m.cachePolicy.String(); policy != "" {
+		header.Set("Cache-Control", policy)
+	}
+}
+
+// notModified evaluates the If-None-Match and If-Modified-Since preconditions of a GET or HEAD request (RFC 7232 section 6)
+// with the ETag and Last-Modified response headers, and returns true if the response is not modified.
+// The If-Modified-Since header is ignored if the request has an If-None-Match header.
+func notModified(r *http.Request, header http.Header) bool {
+	if ifNoneMatch := r.Header.Get("If-None-Match"); ifNoneMatch != "" {
+		return matchesETag(ifNoneMatch, header.Get("ETag"), true)
+	}
+
+	ifModifiedSince, err := http.ParseTime(r.Header.Get("If-Modified-Since"))
+	if err != nil {
+		return false
+	}
+
+	lastModified, err := http.ParseTime(header.Get("Last-Modified"))
+	if err != nil {
+		return false
+	}
+
+	return !lastModified.Truncate(time.Second).After(ifModifiedSince)
+}
+
+// cacheResponses returns the responses with the Cache-Control header of the cache policy in the successful responses.
+func (m *Method) cacheResponses(responses []Response) []Response {
+	if m.cachePolicy == nil || m.cachePolicy.String() == "" {
+		return responses
+	}
+
+	for i := range responses {
+		if responses[i].code >= 200 && responses[i].code <= 299 || responses[i].code == http.StatusNotModified {
+			responses[i] = responses[i].WithHeader("Cache-Control", ResponseHeader{Description: m.cachePolicy.String(), Type: reflect.String})
+		}
+	}
+
+	return responses
+}
@@ -0,0 +1,140 @@
+package rest_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/ehsoc/rest"
+)
+
+func TestCachePolicyString(t *testing.T) {
+	tt := []struct {
+		policy rest.CachePolicy
+		want   string
+	}{
+		{rest.CachePolicy{}, ""},
+		{rest.CachePolicy{Public: true, MaxAge: 60}, "public, max-age=60"},
+		{rest.CachePolicy{Private: true, NoCache: true}, "private, no-cache"},
+		{rest.CachePolicy{NoStore: true}, "no-store"},
+		{rest.CachePolicy{MaxAge: 60, SharedMaxAge: 600, StaleWhileRevalidate: 30, StaleIfError: 86400}, "max-age=60, s-maxage=600, stale-while-revalidate=30, stale-if-error=86400"},
+		{rest.CachePolicy{MustRevalidate: true, MaxAge: 31536000, Immutable: true}, "must-revalidate, max-age=31536000, immutable"},
+	}
+	for _, test := range tt {
+		t.Run(test.want, func(t *testing.T) {
+			assertStringEqual(t, test.policy.String(), test.want)
+		})
+	}
+}
+
+func TestUseCachePolicy(t *testing.T) {
+	mo := rest.NewMethodOperation(rest.OperationFunc(func(i rest.Input) (interface{}, bool, error) {
+		return nil, i.Request.URL.Query().Get("found") != "false", nil
+	}), rest.NewResponse(200)).WithFailResponse(rest.NewResponse(404))
+	ct := mustGetJSONContentType()
+	api := rest.API{}
+	api.UseCachePolicy(rest.CachePolicy{Public: true, MaxAge: 60})
+	api.Resource("car", func(r *rest.Resource) {
+		r.Get(mo, ct)
+		r.Resource("inherited", func(r *rest.Resource) {
+			r.Get(mo, ct)
+		})
+		r.Resource("private", func(r *rest.Resource) {
+			r.UseCachePolicy(rest.CachePolicy{Private: true, NoCache: true})
+			r.Get(mo, ct)
+			r.Post(mo, ct).WithCachePolicy(rest.CachePolicy{NoStore: true})
+		})
+	})
+	car := api.Resources()[0]
+	resources := map[string]rest.Resource{"car": car}
+	for _, res := range car.Resources() {
+		resources[res.Path()] = res
+	}
+	tt := []struct {
+		resource   string
+		httpMethod string
+		query      string
+		want       string
+	}{
+		{"car", "GET", "", "public, max-age=60"},
+		{"car", "GET", "?found=false", ""},
+		{"inherited", "GET", "", "public, max-age=60"},
+		{"private", "GET", "", "private, no-cache"},
+		{"private", "POST", "", "no-store"},
+	}
+	for _, test := range tt {
+		t.Run(test.resource+" "+test.httpMethod+test.query, func(t *testing.T) {
+			var method rest.Method
+			res := resources[test.resource]
+			for _, m := range res.Methods() {
+				if m.HTTPMethod == test.httpMethod {
+					method = m
+				}
+			}
+			req, _ := http.NewRequest(test.httpMethod, "/"+test.query, nil)
+			resp := httptest.NewRecorder()
+			method.ServeHTTP(resp, req)
+			assertStringEqual(t, resp.Header().Get("Cache-Control"), test.want)
+		})
+	}
+	t.Run("operation header", func(t *testing.T) {
+		operation := rest.OperationFunc(func(i rest.Input) (interface{}, bool, error) {
+			i.ResponseHeader().Set("Cache-Control", "no-store")
+			return nil, true, nil
+		})
+		m := rest.NewMethod("GET", rest.NewMethodOperation(operation, rest.NewResponse(200)), ct).
+			WithCachePolicy(rest.CachePolicy{MaxAge: 60})
+		req, _ := http.NewRequest("GET", "/", nil)
+		resp := httptest.NewRecorder()
+		m.ServeHTTP(resp, req)
+		assertStringEqual(t, resp.Header().Get("Cache-Control"), "no-store")
+	})
+	t.Run("responses", func(t *testing.T) {
+		m := rest.NewMethod("GET", mo, ct).WithCachePolicy(rest.CachePolicy{MaxAge: 60})
+		for _, resp := range m.Responses() {
+			header, ok := resp.Headers()["Cache-Control"]
+			if ok != (resp.Code() == 200) {
+				t.Errorf("response %d: got Cache-Control header: %v", resp.Code(), ok)
+			}
+			if ok {
+				assertStringEqual(t, header.Description, "max-age=60")
+			}
+		}
+	})
+}
+
+func TestLastModified(t *testing.T) {
+	lastModified := time.Date(2020, time.November, 10, 23, 0, 30, 500, time.UTC)
+	operation := rest.OperationFunc(func(i rest.Input) (interface{}, bool, error) {
+		i.SetLastModified(lastModified)
+		i.ResponseHeader().Set("ETag", `"v1"`)
+		return Car{ID: 1}, true, nil
+	})
+	m := rest.NewMethod("GET", rest.NewMethodOperation(operation, rest.NewResponse(200).WithOperationResultBody(Car{})), mustGetJSONContentType()).
+		WithCachePolicy(rest.CachePolicy{MaxAge: 60})
+	tt := []struct {
+		name     string
+		headers  map[string]string
+		wantCode int
+	}{
+		{"no precondition", nil, 200},
+		{"not modified since", map[string]string{"If-Modified-Since": "Tue, 10 Nov 2020 23:00:30 GMT"}, 304},
+		{"not modified since later", map[string]string{"If-Modified-Since": "Wed, 11 Nov 2020 23:00:00 GMT"}, 304},
+		{"modified since", map[string]string{"If-Modified-Since": "Tue, 10 Nov 2020 23:00:29 GMT"}, 200},
+		{"invalid date", map[string]string{"If-Modified-Since": "yesterday"}, 200},
+		{"If-None-Match precedence", map[string]string{"If-Modified-Since": "Wed, 11 Nov 2020 23:00:00 GMT", "If-None-Match": `"v0"`}, 200},
+	}
+	for _, test := range tt {
+		t.Run(test.name, func(t *testing.T) {
+			resp := serveETag(m, "GET", test.headers)
+			assertResponseCode(t, resp, test.wantCode)
+			assertStringEqual(t, resp.Header().Get("Last-Modified"), "Tue, 10 Nov 2020 23:00:30 GMT")
+			assertStringEqual(t, resp.Header().Get("Cache-Control"), "max-age=60")
+		})
+	}
+	t.Run("not a GET method", func(t *testing.T) {
+		resp := serveETag(m, "POST", map[string]string{"If-Modified-Since": "Wed, 11 Nov 2020 23:00:00 GMT"})
+		assertResponseCode(t, resp, 200)
+	})
+}
@@ -0,0 +1,444 @@
+package rest
+
+import (
+	"compress/flate"
+	"compress/gzip"
+	"context"
+	"errors"
+	"io"
+	"mime"
+	"net/http"
+	"strings"
+
+	"github.com/ehsoc/rest/httputil"
+)
+
+// ContentCoding is a content coding (RFC 7231 section 3.1.2.1) implementation, like gzip.
+// NewWriter returns a writer that compresses the data written to w, and NewReader returns a reader that decompresses r.
+// If the writer has a Flush method, like gzip.Writer, it is called when the response is flushed.
+type ContentCoding interface {
+	NewWriter(w io.Writer) (io.WriteCloser, error)
+	NewReader(r io.Reader) (io.ReadCloser, error)
+}
+
+// GzipCoding is the gzip ContentCoding.
+type GzipCoding struct {
+	// Level is the compression level, gzip.DefaultCompression if zero.
+	Level int
+}
+
+// NewWriter implements ContentCoding
+func (g GzipCoding) NewWriter(w io.Writer) (io.WriteCloser, error) {
+	if g.Level == 0 {
+		return gzip.NewWriter(w), nil
+	}
+
+	return gzip.NewWriterLevel(w, g.Level)
+}
+
+// NewReader implements ContentCoding
+func (g GzipCoding) NewReader(r io.Reader) (io.ReadCloser, error) {
+	return gzip.NewReader(r)
+}
+
+// DeflateCoding is the deflate ContentCoding, a zlib stream without the zlib header as most clients expect.
+type DeflateCoding struct {
+	// Level is the compression level, flate.DefaultCompression if zero.
+	Level int
+}
+
+// NewWriter implements ContentCoding
+func (d DeflateCoding) NewWriter(w io.Writer) (io.WriteCloser, error) {
+	if d.Level == 0 {
+		return flate.NewWriter(w, flate.DefaultCompression)
+	}
+
+	return flate.NewWriter(w, d.Level)
+}
+
+// NewReader implements ContentCoding
+func (d DeflateCoding) NewReader(r io.Reader) (io.ReadCloser, error) {
+	return flate.NewReader(r), nil
+}
+
+// DefaultMaxDecodedSize is the MaxDecodedSize of NewContentEncodings, 10 MiB.
+const DefaultMaxDecodedSize = 10 << 20
+
+// ContentEncodings contains the available content codings of the responses and request bodies.
+// The response coding is negotiated with the Accept-Encoding header, and the request body
+// is decoded with the coding of the Content-Encoding header.
+type ContentEncodings struct {
+	codings map[string]ContentCoding
+	// names are the coding names in order of preference
+	names []string
+	// MinSize is the minimum size in bytes of a response body to be compressed.
+	MinSize int
+	// ExcludedTypes are the media types of the responses that are not compressed, like the already compressed images.
+	// Wildcards like "image/*" are allowed.
+	ExcludedTypes []string
+	// UnsupportedEncodingResponse is the response when the request body has an unavailable content coding.
+	UnsupportedEncodingResponse Response
+	// MaxDecodedSize is the maximum size in bytes of a decoded request body, so a small compressed body
+	// can't expand without limit. Reading past it fails with ErrorDecodedBodyTooLarge,
+	// and the DecodedBodyTooLargeResponse is written. Zero means no limit.
+	MaxDecodedSize int64
+	// DecodedBodyTooLargeResponse is the response when the decoded request body exceeds the MaxDecodedSize.
+	DecodedBodyTooLargeResponse Response
+}
+
+// NewContentEncodings creates a new ContentEncodings instance with the gzip and deflate codings,
+// a MinSize of 1024 bytes, the image, audio, video and compressed archive media types excluded,
+// and a MaxDecodedSize of DefaultMaxDecodedSize with a 413 (Request Entity Too Large) response.
+func NewContentEncodings() ContentEncodings {
+	ce := ContentEncodings{
+		MinSize:        1024,
+		MaxDecodedSize: DefaultMaxDecodedSize,
+		ExcludedTypes: []string{"image/*", "audio/*", "video/*", "application/zip", "application/gzip",
+			"application/x-gzip", "application/zstd", "application/x-7z-compressed", "application/x-rar-compressed"},
+		UnsupportedEncodingResponse: NewResponse(http.StatusUnsupportedMediaType),
+		DecodedBodyTooLargeResponse: NewResponse(http.StatusRequestEntityTooLarge),
+	}
+	ce.Add("gzip", GzipCoding{})
+	ce.Add("deflate", DeflateCoding{})
+
+	return ce
+}
+
+// Add adds a content coding, like "br" or "zstd". The codings added first are preferred when the client accepts
+// several codings with the same quality value. If the name was already added, the coding is replaced.
+func (ce *ContentEncodings) Add(name string, coding ContentCoding) {
+	name = strings.ToLower(name)
+	if ce.codings == nil {
+		ce.codings = make(map[string]ContentCoding)
+	}
+
+	if _, ok := ce.codings[name]; !ok {
+		ce.names = append(ce.names, name)
+	}
+
+	ce.codings[name] = coding
+}
+
+// Names returns the names of the available content codings in order of preference.
+func (ce *ContentEncodings) Names() []string {
+	return append([]string{}, ce.names...)
+}
+
+// negotiate returns the name of the preferred coding accepted by the Accept-Encoding header value,
+// or an empty string for the identity coding.
+func (ce *ContentEncodings) negotiate(acceptEncoding string) string {
+	codings, err := httputil.ParseMediaTypesStrict(acceptEncoding)
+	if err != nil {
+		return ""
+	}
+
+	best := ""
+	bestQuality := 0.0
+
+	for _, name := range ce.names {
+		quality, explicit := 0.0, false
+
+		for _, c := range codings {
+			if c.Name == name {
+				quality, explicit = c.Quality(), true
+			} else if c.Name == "*" && !explicit {
+				quality = c.Quality()
+			}
+		}
+
+		if quality > bestQuality {
+			best, bestQuality = name, quality
+		}
+	}
+
+	return best
+}
+
+// excludes reports whether the responses with the contentType are not compressed.
+func (ce *ContentEncodings) excludes(contentType string) bool {
+	name, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return false
+	}
+
+	for _, excluded := range ce.ExcludedTypes {
+		if (httputil.MediaType{Name: strings.ToLower(excluded)}).Matches(name) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// UseContentEncodings enables the content codings of the methods declared after the call.
+// The content encodings are inherited by the child resources, and a method can set its own with WithContentEncodings.
+func (rs *ResourceCollection) UseContentEncodings(ce ContentEncodings) {
+	rs.contentEncodings = &ce
+}
+
+// WithContentEncodings sets the content codings of the method, like a MinSize threshold for this method.
+func (m *Method) WithContentEncodings(ce ContentEncodings) *Method {
+	m.contentEncodings = &ce
+	return m
+}
+
+// GetContentEncodings gets the names of the method's content codings in order of preference.
+func (m *Method) GetContentEncodings() []string {
+	if m.contentEncodings == nil {
+		return nil
+	}
+
+	return m.contentEncodings.Names()
+}
+
+// decodedBodyTooLargeResponse returns the DecodedBodyTooLargeResponse if err is an ErrorDecodedBodyTooLarge,
+// a 413 (Request Entity Too Large) response if it is not declared.
+func (m *Method) decodedBodyTooLargeResponse(err error) (Response, bool) {
+	var tooLargeErr *ErrorDecodedBodyTooLarge
+	if m.contentEncodings == nil || !errors.As(err, &tooLargeErr) {
+		return Response{}, false
+	}
+
+	if m.contentEncodings.DecodedBodyTooLargeResponse.code == 0 {
+		return NewResponse(http.StatusRequestEntityTooLarge), true
+	}
+
+	return m.contentEncodings.DecodedBodyTooLargeResponse, true
+}
+
+// contentEncodingResponses adds the DecodedBodyTooLargeResponse to the responses of a method that decodes request bodies
+// with a MaxDecodedSize.
+func (m *Method) contentEncodingResponses(responses []Response) []Response {
+	if m.contentEncodings == nil || m.contentEncodings.MaxDecodedSize == 0 || len(m.GetDecoderMediaTypes()) == 0 {
+		return responses
+	}
+
+	response, _ := m.decodedBodyTooLargeResponse(&ErrorDecodedBodyTooLarge{})
+	if !hasResponseCode(responses, response.code) {
+		responses = append(responses, response)
+	}
+
+	return responses
+}
+
+// decodeRequest returns a copy of the request with a body that decodes the Content-Encoding of the request.
+// It returns false if the content coding is not available.
+func (ce *ContentEncodings) decodeRequest(r *http.Request) (*http.Request, bool) {
+	name := strings.ToLower(strings.TrimSpace(r.Header.Get("Content-Encoding")))
+	if name == "" || name == "identity" {
+		return r, true
+	}
+
+	coding, ok := ce.codings[name]
+	if !ok {
+		return r, false
+	}
+
+	r = r.Clone(r.Context())
+	if r.Body != nil && r.Body != http.NoBody {
+		r.Body = &decodingReader{coding: coding, body: r.Body, maxSize: ce.MaxDecodedSize}
+		r.ContentLength = -1
+	}
+
+	r.Header.Del("Content-Encoding")
+	r.Header.Del("Content-Length")
+
+	return r, true
+}
+
+// decodingReader decodes the request body on the first read, so a malformed body is a decoding error of the body.
+// If maxSize is not zero, reading more than maxSize decoded bytes fails with ErrorDecodedBodyTooLarge.
+type decodingReader struct {
+	coding  ContentCoding
+	body    io.ReadCloser
+	reader  io.ReadCloser
+	maxSize int64
+	read    int64
+}
+
+func (d *decodingReader) Read(p []byte) (int, error) {
+	if d.reader == nil {
+		reader, err := d.coding.NewReader(d.body)
+		if err != nil {
+			return 0, err
+		}
+
+		d.reader = reader
+	}
+
+	if d.maxSize == 0 {
+		return d.reader.Read(p)
+	}
+
+	if d.read > d.maxSize {
+		return 0, &ErrorDecodedBodyTooLarge{d.maxSize}
+	}
+
+	// one byte past the limit is read to tell a body of maxSize bytes from a larger one
+	if int64(len(p)) > d.maxSize-d.read+1 {
+		p = p[:d.maxSize-d.read+1]
+	}
+
+	n, err := d.reader.Read(p)
+	d.read += int64(n)
+
+	if d.read > d.maxSize {
+		return n - int(d.read-d.maxSize), &ErrorDecodedBodyTooLarge{d.maxSize}
+	}
+
+	return n, err
+}
+
+func (d *decodingReader) Close() error {
+	if d.reader != nil {
+		d.reader.Close()
+	}
+
+	return d.body.Close()
+}
+
+// encodingResponseWriter compresses the response body with the negotiated coding.
+// The body is buffered until it reaches the MinSize or the response is flushed, and then the coding
+// is applied if the response has a body, a Content-Type that is not excluded and no Content-Encoding.
+type encodingResponseWriter struct {
+	http.ResponseWriter
+	encodings *ContentEncodings
+	name      string
+	code      int
+	buf       []byte
+	// decided means that the header was written, and writer is the compressor if the coding was applied
+	decided bool
+	writer  io.WriteCloser
+	// bodyETag is the entity tag computed by WithETag hashing the uncompressed body,
+	// that is weakened if the body is compressed, unlike an entity tag set by the Operation.
+	bodyETag string
+}
+
+func (e *encodingResponseWriter) WriteHeader(code int) {
+	if e.code == 0 {
+		e.code = code
+	}
+}
+
+func (e *encodingResponseWriter) Write(p []byte) (int, error) {
+	if e.code == 0 {
+		e.code = http.StatusOK
+	}
+
+	if e.decided {
+		if e.writer != nil {
+			return e.writer.Write(p)
+		}
+
+		return e.ResponseWriter.Write(p)
+	}
+
+	e.buf = append(e.buf, p...)
+	if len(e.buf) >= e.encodings.MinSize {
+		if err := e.decide(true); err != nil {
+			return 0, err
+		}
+	}
+
+	return len(p), nil
+}
+
+// Flush sends the buffered body, compressed if the coding can be applied, so the streamed items are not held back.
+func (e *encodingResponseWriter) Flush() {
+	if !e.decided {
+		if e.code == 0 {
+			e.code = http.StatusOK
+		}
+
+		if e.decide(true) != nil {
+			return
+		}
+	}
+
+	if f, ok := e.writer.(interface{ Flush() error }); ok {
+		f.Flush()
+	}
+
+	if f, ok := e.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// decide writes the header, and the buffered body with the coding if compress is true and the coding can be applied.
+func (e *encodingResponseWriter) decide(compress bool) error {
+	e.decided = true
+	header := e.ResponseWriter.Header()
+
+	if compress && len(e.buf) > 0 && e.code != http.StatusNoContent && e.code != http.StatusNotModified &&
+		header.Get("Content-Encoding") == "" && !e.encodings.excludes(header.Get("Content-Type")) {
+		writer, err := e.encodings.codings[e.name].NewWriter(e.ResponseWriter)
+		if err == nil {
+			header.Set("Content-Encoding", e.name)
+			header.Del("Content-Length")
+			// the compressed representation is not byte-for-byte the same as the hashed one
+			if etag := header.Get("ETag"); etag != "" && etag == e.bodyETag && !strings.HasPrefix(etag, "W/") {
+				header.Set("ETag", "W/"+etag)
+			}
+			e.writer = writer
+		}
+	}
+
+	e.ResponseWriter.WriteHeader(e.code)
+
+	buf := e.buf
+	e.buf = nil
+
+	if e.writer != nil {
+		_, err := e.writer.Write(buf)
+		return err
+	}
+
+	_, err := e.ResponseWriter.Write(buf)
+
+	return err
+}
+
+// close writes the buffered body without coding if it didn't reach the MinSize, and closes the compressor.
+func (e *encodingResponseWriter) close() {
+	if !e.decided {
+		if e.code == 0 {
+			return
+		}
+
+		e.decide(false)
+	}
+
+	if e.writer != nil {
+		e.writer.Close()
+	}
+}
+
+// contentEncodingMiddleware decodes the request body, and compresses the response body with the negotiated coding.
+func (m *Method) contentEncodingMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ce := m.contentEncodings
+		if ce == nil {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		w.Header().Add("Vary", "Accept-Encoding")
+
+		r, ok := ce.decodeRequest(r)
+		if !ok {
+			err := &ErrorUnsupportedContentEncoding{r.Header.Get("Content-Encoding")}
+			m.writeResponseFallBack(w, r, m.problemResponse(ce.UnsupportedEncodingResponse).render(nil, false, err))
+			return
+		}
+
+		name := ce.negotiate(r.Header.Get("Accept-Encoding"))
+		if name == "" {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		ew := &encodingResponseWriter{ResponseWriter: w, encodings: ce, name: name}
+		defer ew.close()
+		next.ServeHTTP(ew, r.WithContext(context.WithValue(r.Context(), InputContextKey("encodingwriter"), ew)))
+	})
+}
@@ -0,0 +1,299 @@
+package rest_test
+
+import (
+	"bytes"
+	"compress/flate"
+	"compress/gzip"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/ehsoc/rest"
+	"github.com/ehsoc/rest/encdec"
+)
+
+var largeText = strings.Repeat("rest ", 500)
+
+func textMethod(body string) *rest.Method {
+	operation := rest.OperationFunc(func(i rest.Input) (interface{}, bool, error) {
+		return body, true, nil
+	})
+	ct := rest.NewContentTypes()
+	ct.AddEncoder("text/plain", encdec.TextEncoder{}, true)
+	ct.AddEncoder("image/svg+xml", encdec.TextEncoder{}, false)
+	return rest.NewMethod("GET", rest.NewMethodOperation(operation, rest.NewResponse(200).WithOperationResultBody("")), ct)
+}
+
+func decodeResponseBody(t *testing.T, resp *httptest.ResponseRecorder) string {
+	t.Helper()
+	var reader io.Reader = resp.Body
+	switch resp.Header().Get("Content-Encoding") {
+	case "gzip":
+		gzipReader, err := gzip.NewReader(resp.Body)
+		assertNoErrorFatal(t, err)
+		reader = gzipReader
+	case "deflate":
+		reader = flate.NewReader(resp.Body)
+	}
+	b, err := ioutil.ReadAll(reader)
+	assertNoErrorFatal(t, err)
+	return string(b)
+}
+
+func TestContentEncodingNegotiation(t *testing.T) {
+	m := textMethod(largeText).WithContentEncodings(rest.NewContentEncodings())
+	tt := []struct {
+		acceptEncoding string
+		want           string
+	}{
+		{"gzip", "gzip"},
+		{"deflate", "deflate"},
+		{"deflate, gzip", "gzip"},
+		{"gzip;q=0.5, deflate", "deflate"},
+		{"*", "gzip"},
+		{"gzip;q=0, *", "deflate"},
+		{"br", ""},
+		{"identity", ""},
+		{"", ""},
+	}
+	for _, test := range tt {
+		t.Run(test.acceptEncoding, func(t *testing.T) {
+			req, _ := http.NewRequest("GET", "/", nil)
+			req.Header.Set("Accept-Encoding", test.acceptEncoding)
+			resp := httptest.NewRecorder()
+			m.ServeHTTP(resp, req)
+			assertResponseCode(t, resp, 200)
+			assertStringEqual(t, resp.Header().Get("Content-Encoding"), test.want)
+			assertStringEqual(t, resp.Header().Get("Vary"), "Accept-Encoding")
+			assertStringEqual(t, decodeResponseBody(t, resp), largeText)
+		})
+	}
+}
+
+func TestContentEncodingExclusions(t *testing.T) {
+	ce := rest.NewContentEncodings()
+	ce.MinSize = 100
+	tt := []struct {
+		name   string
+		body   string
+		accept string
+		want   string
+	}{
+		{"large body", largeText, "text/plain", "gzip"},
+		{"body smaller than MinSize", "rest", "text/plain", ""},
+		{"excluded type", largeText, "image/svg+xml", ""},
+	}
+	for _, test := range tt {
+		t.Run(test.name, func(t *testing.T) {
+			req, _ := http.NewRequest("GET", "/", nil)
+			req.Header.Set("Accept", test.accept)
+			req.Header.Set("Accept-Encoding", "gzip")
+			resp := httptest.NewRecorder()
+			textMethod(test.body).WithContentEncodings(ce).ServeHTTP(resp, req)
+			assertStringEqual(t, resp.Header().Get("Content-Encoding"), test.want)
+			assertStringEqual(t, decodeResponseBody(t, resp), test.body)
+		})
+	}
+}
+
+type upperCoding struct{}
+
+type upperWriter struct {
+	io.Writer
+}
+
+func (u upperWriter) Write(p []byte) (int, error) {
+	return u.Writer.Write(bytes.ToUpper(p))
+}
+
+func (u upperWriter) Close() error {
+	return nil
+}
+
+func (upperCoding) NewWriter(w io.Writer) (io.WriteCloser, error) {
+	return upperWriter{w}, nil
+}
+
+func (upperCoding) NewReader(r io.Reader) (io.ReadCloser, error) {
+	return ioutil.NopCloser(r), nil
+}
+
+func TestContentEncodingRegistry(t *testing.T) {
+	ce := rest.NewContentEncodings()
+	ce.Add("x-upper", upperCoding{})
+	assertStringSlice(t, ce.Names(), []string{"gzip", "deflate", "x-upper"})
+	req, _ := http.NewRequest("GET", "/", nil)
+	req.Header.Set("Accept-Encoding", "gzip;q=0.5, x-upper")
+	resp := httptest.NewRecorder()
+	textMethod(largeText).WithContentEncodings(ce).ServeHTTP(resp, req)
+	assertStringEqual(t, resp.Header().Get("Content-Encoding"), "x-upper")
+	assertStringEqual(t, resp.Body.String(), strings.ToUpper(largeText))
+}
+
+func TestContentEncodingRequestBody(t *testing.T) {
+	operation := rest.OperationFunc(func(i rest.Input) (interface{}, bool, error) {
+		car := Car{}
+		err := i.BodyDecoder.Decode(i.Request.Body, &car)
+		return car, true, err
+	})
+	m := rest.NewMethod("POST", rest.NewMethodOperation(operation, rest.NewResponse(200).WithOperationResultBody(Car{})), mustGetJSONContentType())
+	m.WithRequestBody("car", Car{}).WithContentEncodings(rest.NewContentEncodings())
+	gzipBody := func(s string) *bytes.Buffer {
+		buf := new(bytes.Buffer)
+		w := gzip.NewWriter(buf)
+		w.Write([]byte(s))
+		w.Close()
+		return buf
+	}
+	t.Run("gzip body", func(t *testing.T) {
+		req, _ := http.NewRequest("POST", "/", gzipBody(`{"id":1,"brand":"Fiat"}`))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Content-Encoding", "gzip")
+		resp := httptest.NewRecorder()
+		m.ServeHTTP(resp, req)
+		assertResponseCode(t, resp, 200)
+		assertStringEqual(t, resp.Body.String(), "{\"id\":1,\"brand\":\"Fiat\",\"colors\":null}\n")
+		assertStringEqual(t, req.Header.Get("Content-Encoding"), "gzip")
+	})
+	t.Run("malformed gzip body", func(t *testing.T) {
+		req, _ := http.NewRequest("POST", "/", bytes.NewBufferString(`{"id":1}`))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Content-Encoding", "gzip")
+		resp := httptest.NewRecorder()
+		m.ServeHTTP(resp, req)
+		assertResponseCode(t, resp, 500)
+	})
+	t.Run("unsupported coding", func(t *testing.T) {
+		req, _ := http.NewRequest("POST", "/", bytes.NewBufferString(`{"id":1}`))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Content-Encoding", "br")
+		resp := httptest.NewRecorder()
+		m.ServeHTTP(resp, req)
+		assertResponseCode(t, resp, 415)
+	})
+	t.Run("max decoded size", func(t *testing.T) {
+		operation := rest.OperationFunc(func(i rest.Input) (interface{}, bool, error) {
+			body, err := i.DecodeBody()
+			return body, true, err
+		})
+		body := `{"id":1,"brand":"` + strings.Repeat("a", 100) + `"}`
+		ce := rest.NewContentEncodings()
+		assertTrue(t, ce.MaxDecodedSize == rest.DefaultMaxDecodedSize)
+		tt := []struct {
+			name     string
+			maxSize  int64
+			strict   bool
+			wantCode int
+		}{
+			{"no limit", 0, false, 200},
+			{"below the limit", int64(len(body)) + 1, false, 200},
+			{"at the limit", int64(len(body)), false, 200},
+			{"past the limit", int64(len(body)) - 1, false, 413},
+			{"strict body past the limit", int64(len(body)) - 1, true, 413},
+		}
+		for _, test := range tt {
+			t.Run(test.name, func(t *testing.T) {
+				ce.MaxDecodedSize = test.maxSize
+				m := rest.NewMethod("POST", rest.NewMethodOperation(operation, rest.NewResponse(200).WithOperationResultBody(Car{})), mustGetJSONContentType())
+				m.WithRequestBody("car", Car{}).WithContentEncodings(ce)
+				if test.strict {
+					m.WithStrictRequestBody(rest.NewResponse(422))
+				}
+				codes := []int{}
+				for _, resp := range m.Responses() {
+					codes = append(codes, resp.Code())
+				}
+				if containsCode(codes, 413) != (test.maxSize > 0) {
+					t.Errorf("got responses: %v", codes)
+				}
+				req, _ := http.NewRequest("POST", "/", gzipBody(body))
+				req.Header.Set("Content-Type", "application/json")
+				req.Header.Set("Content-Encoding", "gzip")
+				resp := httptest.NewRecorder()
+				m.ServeHTTP(resp, req)
+				assertResponseCode(t, resp, test.wantCode)
+			})
+		}
+		t.Run("read error", func(t *testing.T) {
+			ce.MaxDecodedSize = 10
+			called := false
+			operation := rest.OperationFunc(func(i rest.Input) (interface{}, bool, error) {
+				called = true
+				b, err := ioutil.ReadAll(i.Request.Body)
+				assertStringEqual(t, string(b), body[:10])
+				assertStringEqual(t, err.Error(), "rest: the decoded request body exceeds the maximum size of 10 bytes")
+				return nil, true, nil
+			})
+			m := rest.NewMethod("POST", rest.NewMethodOperation(operation, rest.NewResponse(204)), mustGetJSONContentType()).WithContentEncodings(ce)
+			req, _ := http.NewRequest("POST", "/", gzipBody(body))
+			req.Header.Set("Content-Type", "application/json")
+			req.Header.Set("Content-Encoding", "gzip")
+			m.ServeHTTP(httptest.NewRecorder(), req)
+			assertTrue(t, called)
+		})
+	})
+}
+
+func TestContentEncodingStream(t *testing.T) {
+	operation := rest.OperationFunc(func(i rest.Input) (interface{}, bool, error) {
+		cars := make(chan Car, 2)
+		cars <- Car{ID: 1}
+		cars <- Car{ID: 2}
+		close(cars)
+		return cars, true, nil
+	})
+	ct := rest.NewContentTypes()
+	ct.AddEncoder("application/x-ndjson", encdec.NDJSONEncoder{}, true)
+	m := rest.NewMethod("GET", rest.NewMethodOperation(operation, rest.NewResponse(200).WithOperationResultBody([]Car{})), ct)
+	m.WithContentEncodings(rest.NewContentEncodings())
+	req, _ := http.NewRequest("GET", "/", nil)
+	req.Header.Set("Accept-Encoding", "gzip")
+	resp := httptest.NewRecorder()
+	m.ServeHTTP(resp, req)
+	// the stream is compressed below the MinSize, as the items are flushed
+	assertStringEqual(t, resp.Header().Get("Content-Encoding"), "gzip")
+	assertTrue(t, resp.Flushed)
+	want := "{\"id\":1,\"brand\":\"\",\"colors\":null}\n{\"id\":2,\"brand\":\"\",\"colors\":null}\n"
+	assertStringEqual(t, decodeResponseBody(t, resp), want)
+}
+
+func TestUseContentEncodings(t *testing.T) {
+	ce := rest.NewContentEncodings()
+	ce.MinSize = 0
+	mo := rest.NewMethodOperation(rest.OperationFunc(func(i rest.Input) (interface{}, bool, error) {
+		return Car{ID: 1}, true, nil
+	}), rest.NewResponse(200).WithOperationResultBody(Car{}))
+	api := rest.API{}
+	api.UseContentEncodings(ce)
+	api.Resource("car", func(r *rest.Resource) {
+		r.Get(mo, mustGetJSONContentType())
+		r.Resource("ping", func(r *rest.Resource) {
+			r.Get(mo, mustGetJSONContentType())
+		})
+	})
+	car := api.Resources()[0]
+	for _, res := range []rest.Resource{car, car.Resources()[0]} {
+		m := res.Methods()[0]
+		assertStringSlice(t, m.GetContentEncodings(), []string{"gzip", "deflate"})
+		req, _ := http.NewRequest("GET", "/", nil)
+		req.Header.Set("Accept-Encoding", "deflate")
+		resp := httptest.NewRecorder()
+		m.ServeHTTP(resp, req)
+		assertStringEqual(t, resp.Header().Get("Content-Encoding"), "deflate")
+	}
+	t.Run("no content encodings", func(t *testing.T) {
+		m := rest.NewMethod("GET", moTest, mustGetJSONContentType())
+		if m.GetContentEncodings() != nil {
+			t.Errorf("not expecting content encodings")
+		}
+		req, _ := http.NewRequest("GET", "/", nil)
+		req.Header.Set("Accept-Encoding", "gzip")
+		resp := httptest.NewRecorder()
+		m.ServeHTTP(resp, req)
+		assertStringEqual(t, resp.Header().Get("Content-Encoding"), "")
+		assertStringEqual(t, resp.Header().Get("Vary"), "")
+	})
+}
@@ -1,6 +1,10 @@
 package rest
 
 import (
+	"mime"
+	"sort"
+	"strings"
+
 	"github.com/ehsoc/rest/encdec"
 )
 
@@ -12,6 +16,11 @@ type ContentTypes struct {
 	defaultEncoder               string
 	defaultDecoder               string
 	UnsupportedMediaTypeResponse Response
+	// NotAcceptableResponse is the response when the Accept header doesn't accept any of the available content types.
+	NotAcceptableResponse Response
+	// MissingRequestBodyResponse is the response when the request body is required, see RequireBody, and the request has no body.
+	MissingRequestBodyResponse Response
+	bodyRequired               bool
 }
 
 // NewContentTypes will create a new ContentTypes instance
@@ -20,7 +29,30 @@ func NewContentTypes() ContentTypes {
 
 	var decoderContentTypes = make(map[string]encdec.Decoder)
 
-	return ContentTypes{encoderContentTypes, decoderContentTypes, "", "", NewResponse(415)}
+	return ContentTypes{
+		encoderContentTypes:          encoderContentTypes,
+		decoderContentTypes:          decoderContentTypes,
+		UnsupportedMediaTypeResponse: NewResponse(415),
+		NotAcceptableResponse:        NewResponse(406),
+		MissingRequestBodyResponse:   NewResponse(400),
+	}
+}
+
+// RequireBody sets the request body as required: a request must have a body of one of the decoder MIME types,
+// otherwise the MissingRequestBodyResponse is returned.
+// Without decoders no request body is accepted, and a request with a body gets the UnsupportedMediaTypeResponse.
+func (h *ContentTypes) RequireBody() {
+	h.bodyRequired = true
+}
+
+// BodyRequired returns true if the request body is required, see RequireBody.
+func (h *ContentTypes) BodyRequired() bool {
+	return h.bodyRequired
+}
+
+// AcceptsBody returns true if there is at least one decoder, so a request body is accepted.
+func (h *ContentTypes) AcceptsBody() bool {
+	return len(h.decoderContentTypes) > 0
 }
 
 // Add adds a EncoderDecoder.
@@ -52,22 +84,137 @@ func (h *ContentTypes) AddDecoder(mimeType string, decoder encdec.Decoder, isDef
 	}
 }
 
-// GetEncoder gets the encoder with the provided mimeType as key
+// GetEncoder gets the encoder with the provided mimeType as key.
+// If there is no encoder for the exact mimeType, the media type is matched as in matchMediaType,
+// so "application/vnd.acme.pet+json; indent=2" gets the "application/json" encoder.
+// The media type parameters are passed to an encdec.OptionsEncoder encoder as options.
 func (h *ContentTypes) GetEncoder(mimeType string) (encdec.Encoder, error) {
 	if ed, ok := h.encoderContentTypes[mimeType]; ok {
 		return ed, nil
 	}
+
+	name, params, err := mime.ParseMediaType(mimeType)
+	if err != nil {
+		return nil, ErrorNoDefaultContentTypeIsSet
+	}
+
+	keys := make([]string, 0, len(h.encoderContentTypes))
+	for k := range h.encoderContentTypes {
+		keys = append(keys, k)
+	}
+
+	if key, ok := matchMediaType(keys, name, params); ok {
+		return withEncoderOptions(h.encoderContentTypes[key], params), nil
+	}
+
 	return nil, ErrorNoDefaultContentTypeIsSet
 }
 
-// GetDecoder gets the decoder with the provided mimeType as key
+// GetDecoder gets the decoder with the provided mimeType as key.
+// If there is no decoder for the exact mimeType, the media type is matched as in matchMediaType,
+// so "application/json; charset=utf-8" gets the "application/json" decoder.
 func (h *ContentTypes) GetDecoder(mimeType string) (encdec.Decoder, error) {
 	if ed, ok := h.decoderContentTypes[mimeType]; ok {
 		return ed, nil
 	}
+
+	name, params, err := mime.ParseMediaType(mimeType)
+	if err != nil {
+		return nil, ErrorNoDefaultContentTypeIsSet
+	}
+
+	keys := make([]string, 0, len(h.decoderContentTypes))
+	for k := range h.decoderContentTypes {
+		keys = append(keys, k)
+	}
+
+	if key, ok := matchMediaType(keys, name, params); ok {
+		return h.decoderContentTypes[key], nil
+	}
+
 	return nil, ErrorNoDefaultContentTypeIsSet
 }
 
+// matchMediaType returns the key, a registered MIME type, that matches the media type name and params.
+// A key matches if it has the same name, or the name of the structured syntax suffix of the media type
+// ("application/json" for "application/vnd.acme.pet+json"), and all the key parameters are in params with the same value.
+// The keys with the same name are preferred over the suffix ones, and then the keys with more parameters.
+func matchMediaType(keys []string, name string, params map[string]string) (string, bool) {
+	sort.Strings(keys)
+
+	best := ""
+	bestScore := -1
+
+	for _, key := range keys {
+		score, ok := mediaTypeScore(key, name, params)
+		if ok && score > bestScore {
+			best = key
+			bestScore = score
+		}
+	}
+
+	return best, bestScore >= 0
+}
+
+// mediaTypeScore returns the match score of the key with the media type name and params,
+// and false if the key doesn't match.
+func mediaTypeScore(key, name string, params map[string]string) (int, bool) {
+	keyName, keyParams, err := mime.ParseMediaType(key)
+	if err != nil {
+		return 0, false
+	}
+
+	score := 0
+
+	switch keyName {
+	case name:
+		score = 1 << 16
+	case structuredSuffixName(name):
+	default:
+		return 0, false
+	}
+
+	for k, v := range keyParams {
+		if !strings.EqualFold(params[k], v) {
+			return 0, false
+		}
+	}
+
+	return score + len(keyParams), true
+}
+
+// structuredSuffixName returns the media type name of a structured syntax suffix (RFC 6839),
+// like "application/json" for "application/vnd.acme.pet+json", or an empty string if there is no suffix.
+func structuredSuffixName(name string) string {
+	i := strings.LastIndex(name, "+")
+	if i < 0 || !strings.Contains(name, "/") {
+		return ""
+	}
+
+	return "application/" + name[i+1:]
+}
+
+// withEncoderOptions passes the media type parameters, except the quality value, to an encdec.OptionsEncoder encoder.
+func withEncoderOptions(encoder encdec.Encoder, params map[string]string) encdec.Encoder {
+	optionsEncoder, ok := encoder.(encdec.OptionsEncoder)
+	if !ok {
+		return encoder
+	}
+
+	options := map[string]string{}
+	for k, v := range params {
+		if k != "q" {
+			options[k] = v
+		}
+	}
+
+	if len(options) == 0 {
+		return encoder
+	}
+
+	return optionsEncoder.WithOptions(options)
+}
+
 // GetDefaultEncoder gets default encoder.
 func (h *ContentTypes) GetDefaultEncoder() (string, encdec.Encoder, error) {
 	if ed, ok := h.encoderContentTypes[h.defaultEncoder]; ok {
@@ -77,10 +224,18 @@ func (h *ContentTypes) GetDefaultEncoder() (string, encdec.Encoder, error) {
 }
 
 // GetDefaultDecoder gets the default decoder.
+// If no default decoder is set and there is only one decoder, that one is the default.
 func (h *ContentTypes) GetDefaultDecoder() (string, encdec.Decoder, error) {
-	if ed, ok := h.decoderContentTypes[h.defaultEncoder]; ok {
+	if ed, ok := h.decoderContentTypes[h.defaultDecoder]; ok {
 		return h.defaultDecoder, ed, nil
 	}
+
+	if len(h.decoderContentTypes) == 1 {
+		for mimeType, ed := range h.decoderContentTypes {
+			return mimeType, ed, nil
+		}
+	}
+
 	return "", nil, ErrorNoDefaultContentTypeIsSet
 }
 
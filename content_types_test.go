@@ -3,6 +3,8 @@ package rest_test
 import (
 	"bytes"
 	"io"
+	"net/http"
+	"net/http/httptest"
 	"testing"
 
 	"github.com/ehsoc/rest"
@@ -113,3 +115,129 @@ func TestGetDefaultEncoderDecoder(t *testing.T) {
 		}
 	})
 }
+
+func TestMediaTypeMatching(t *testing.T) {
+	json := &EncodeDecoderSpy{}
+	jsonV2 := &EncodeDecoderSpy{}
+	xml := &EncodeDecoderSpy{}
+	ct := rest.NewContentTypes()
+	ct.Add("application/json", json, true)
+	ct.Add("application/json; version=2", jsonV2, false)
+	ct.Add("application/xml", xml, false)
+	tt := []struct {
+		mimeType string
+		want     *EncodeDecoderSpy
+	}{
+		{"application/json", json},
+		{"application/json; charset=utf-8", json},
+		{"application/json; charset=utf-8; version=2", jsonV2},
+		{"application/json; version=3", json},
+		{"application/vnd.acme.pet+json", json},
+		{"application/vnd.acme.pet+json; version=2", jsonV2},
+		{"application/problem+json", json},
+		{"application/atom+xml", xml},
+		{"text/xml", nil},
+		{"application/vnd.acme.pet+yaml", nil},
+		{"application/", nil},
+	}
+	for _, test := range tt {
+		t.Run(test.mimeType, func(t *testing.T) {
+			enc, encErr := ct.GetEncoder(test.mimeType)
+			dec, decErr := ct.GetDecoder(test.mimeType)
+			if test.want == nil {
+				if encErr == nil || decErr == nil {
+					t.Errorf("expecting errors, got: %v %v", encErr, decErr)
+				}
+				return
+			}
+			if enc != test.want || dec != test.want {
+				t.Errorf("got: %p %p want: %p", enc, dec, test.want)
+			}
+		})
+	}
+}
+
+func TestGetEncoderWithOptions(t *testing.T) {
+	ct := rest.NewContentTypes()
+	ct.Add("application/json", encdec.JSONEncoderDecoder{}, true)
+	enc, err := ct.GetEncoder("application/vnd.acme.pet+json; indent=2")
+	assertNoErrorFatal(t, err)
+	buf := new(bytes.Buffer)
+	enc.Encode(buf, Car{ID: 1})
+	if !bytes.Contains(buf.Bytes(), []byte("\n  \"id\": 1")) {
+		t.Errorf("expecting indented JSON, got: %s", buf.String())
+	}
+}
+
+func TestDecoderOnlyContentTypes(t *testing.T) {
+	t.Run("default decoder", func(t *testing.T) {
+		ct := rest.NewContentTypes()
+		ct.AddDecoder("multipart/form-data", &EncodeDecoderSpy{}, true)
+		ct.AddDecoder("application/x-www-form-urlencoded", &EncodeDecoderSpy{}, false)
+		got, _, err := ct.GetDefaultDecoder()
+		if err != nil {
+			t.Fatalf("Not expecting error: %v", err)
+		}
+		assertStringEqual(t, got, "multipart/form-data")
+	})
+	t.Run("single decoder is the default", func(t *testing.T) {
+		ct := rest.NewContentTypes()
+		ct.AddDecoder("multipart/form-data", &EncodeDecoderSpy{}, false)
+		got, _, err := ct.GetDefaultDecoder()
+		if err != nil {
+			t.Fatalf("Not expecting error: %v", err)
+		}
+		assertStringEqual(t, got, "multipart/form-data")
+	})
+	t.Run("no default decoder", func(t *testing.T) {
+		ct := rest.NewContentTypes()
+		ct.AddDecoder("multipart/form-data", &EncodeDecoderSpy{}, false)
+		ct.AddDecoder("application/x-www-form-urlencoded", &EncodeDecoderSpy{}, false)
+		_, _, err := ct.GetDefaultDecoder()
+		if err == nil {
+			t.Errorf("Was expecting error.")
+		}
+	})
+}
+
+func TestRequestBodyContentTypes(t *testing.T) {
+	operation := rest.OperationFunc(func(i rest.Input) (interface{}, bool, error) {
+		return nil, true, nil
+	})
+	encoderOnly := rest.NewContentTypes()
+	encoderOnly.AddEncoder("application/json", encdec.JSONEncoder{}, true)
+	required := mustGetJSONContentType()
+	required.RequireBody()
+	tt := []struct {
+		name     string
+		ct       rest.ContentTypes
+		body     io.Reader
+		wantCode int
+	}{
+		{"no body accepted", encoderOnly, nil, 200},
+		{"no body accepted with body", encoderOnly, bytes.NewBufferString(`{}`), 415},
+		{"optional body", mustGetJSONContentType(), nil, 200},
+		{"required body", required, bytes.NewBufferString(`{}`), 200},
+		{"missing required body", required, nil, 400},
+	}
+	for _, test := range tt {
+		t.Run(test.name, func(t *testing.T) {
+			m := rest.NewMethod("POST", rest.NewMethodOperation(operation, rest.NewResponse(200)), test.ct)
+			req, _ := http.NewRequest("POST", "/", test.body)
+			req.Header.Set("Content-Type", "application/json")
+			resp := httptest.NewRecorder()
+			m.ServeHTTP(resp, req)
+			assertResponseCode(t, resp, test.wantCode)
+		})
+	}
+	t.Run("accepts body", func(t *testing.T) {
+		assertTrue(t, !encoderOnly.AcceptsBody())
+		assertTrue(t, required.AcceptsBody())
+	})
+	t.Run("responses", func(t *testing.T) {
+		m := rest.NewMethod("POST", rest.NewMethodOperation(operation, rest.NewResponse(200)), required)
+		assertTrue(t, hasCode(m.Responses(), 400))
+		m = rest.NewMethod("POST", rest.NewMethodOperation(operation, rest.NewResponse(200)), mustGetJSONContentType())
+		assertTrue(t, !hasCode(m.Responses(), 400))
+	})
+}
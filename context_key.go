@@ -8,6 +8,8 @@ type EncoderDecoderContextKey string
 // The values are set by the Negotiator implementation.
 type ContentTypeContextKey string
 
-// InputContextKey is the type used to pass the URI Parameter function through the Context of the request.
-// The values are set by the GenerateServer method of the API type.
+// InputContextKey is the type used to pass the URI Parameter function, the decoded request body,
+// the response header and the compressing response writer through the Context of the request.
+// The URI Parameter function is set by the GenerateServer method of the API type,
+// and the request body cache, response header and compressing response writer by the Method handler.
 type InputContextKey string
@@ -0,0 +1,201 @@
+package rest
+
+import (
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// CORSPolicy describes the Cross-Origin Resource Sharing (CORS) policy of the methods of a resource.
+// The headers allowed in a preflight request are computed from the method declaration: the header parameters,
+// the security schemes headers and the Content-Type header if the method declares a request body.
+type CORSPolicy struct {
+	// AllowedOrigins are the origins allowed to make cross-origin requests. Use "*" to allow any origin.
+	AllowedOrigins []string
+	// AllowedHeaders are the request headers allowed in addition to the headers computed from the method declaration.
+	AllowedHeaders []string
+	// ExposedHeaders are the response headers that the browser will expose to the client.
+	ExposedHeaders []string
+	// AllowCredentials allows the requests with credentials, like cookies or the Authorization header.
+	// If true, the origin of the request is sent in the Access-Control-Allow-Origin header instead of "*".
+	AllowCredentials bool
+	// MaxAge is the number of seconds that a preflight response can be cached. Not sent if zero.
+	MaxAge int
+}
+
+// UseCORS sets the CORS policy of the methods declared after the call.
+// The policy will be passed down to the child resources, that can set their own policy.
+func (rs *ResourceCollection) UseCORS(policy CORSPolicy) {
+	rs.corsPolicy = &policy
+}
+
+func (p *CORSPolicy) allowsAnyOrigin() bool {
+	for _, o := range p.AllowedOrigins {
+		if o == "*" {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (p *CORSPolicy) allowsOrigin(origin string) bool {
+	if p.allowsAnyOrigin() {
+		return true
+	}
+
+	for _, o := range p.AllowedOrigins {
+		if strings.EqualFold(o, origin) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// setOriginHeaders sets the headers of an allowed origin, for both preflight and actual requests.
+func (p *CORSPolicy) setOriginHeaders(w http.ResponseWriter, origin string) {
+	if p.allowsAnyOrigin() && !p.AllowCredentials {
+		w.Header().Set("Access-Control-Allow-Origin", "*")
+	} else {
+		w.Header().Set("Access-Control-Allow-Origin", origin)
+		w.Header().Add("Vary", "Origin")
+	}
+
+	if p.AllowCredentials {
+		w.Header().Set("Access-Control-Allow-Credentials", "true")
+	}
+}
+
+// corsMiddleware sets the CORS headers in the response to an actual cross-origin request.
+func (m *Method) corsMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		origin := r.Header.Get("Origin")
+		if origin != "" && m.corsPolicy.allowsOrigin(origin) {
+			m.corsPolicy.setOriginHeaders(w, origin)
+
+			if len(m.corsPolicy.ExposedHeaders) > 0 {
+				w.Header().Set("Access-Control-Expose-Headers", strings.Join(m.corsPolicy.ExposedHeaders, ", "))
+			}
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+// corsAllowedHeaders returns the canonical names of the request headers allowed by the method CORS policy.
+func (m *Method) corsAllowedHeaders() []string {
+	names := append([]string{}, m.corsPolicy.AllowedHeaders...)
+
+	for _, p := range m.Parameters() {
+		if p.HTTPType == HeaderParameter {
+			names = append(names, p.Name)
+		}
+	}
+
+	for _, s := range m.SecurityCollection {
+		for _, ss := range s.SecuritySchemes {
+			switch {
+			case ss.Type == APIKeySecurityType && ss.Parameter.HTTPType == HeaderParameter:
+				names = append(names, ss.Parameter.Name)
+			case ss.Type == BasicSecurityType || ss.Type == OAuth2SecurityType || ss.Type == BearerSecurityType:
+				names = append(names, "Authorization")
+			}
+		}
+	}
+
+	if m.RequestBody.Body != nil {
+		names = append(names, "Content-Type")
+	}
+
+	allowed := []string{}
+	for _, name := range names {
+		name = http.CanonicalHeaderKey(strings.TrimSpace(name))
+		if name != "" && !containsString(allowed, name) {
+			allowed = append(allowed, name)
+		}
+	}
+
+	sort.Strings(allowed)
+
+	return allowed
+}
+
+func isPreflightRequest(r *http.Request) bool {
+	return r.Method == http.MethodOptions && r.Header.Get("Origin") != "" && r.Header.Get("Access-Control-Request-Method") != ""
+}
+
+// corsMethod returns the method with a CORS policy that will handle the httpMethod requests, or nil if there is none.
+func (rs *Resource) corsMethod(httpMethod string) *Method {
+	m, ok := rs.methods[httpMethod]
+	if !ok && httpMethod == http.MethodHead {
+		if _, auto := rs.autoMethods[http.MethodHead]; auto {
+			m = rs.methods[http.MethodGet]
+		}
+	}
+
+	if m == nil || m.corsPolicy == nil {
+		return nil
+	}
+
+	return m
+}
+
+// preflightHandler answers the CORS preflight requests to the methods of the resource with a CORS policy,
+// and passes any other request to the next handler.
+func (rs *Resource) preflightHandler(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !isPreflightRequest(r) {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		m := rs.corsMethod(strings.ToUpper(r.Header.Get("Access-Control-Request-Method")))
+		if m == nil {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		origin := r.Header.Get("Origin")
+		allowedHeaders := m.corsAllowedHeaders()
+
+		if !m.corsPolicy.allowsOrigin(origin) || !allowsHeaders(allowedHeaders, r.Header.Get("Access-Control-Request-Headers")) {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+
+		m.corsPolicy.setOriginHeaders(w, origin)
+
+		methods := []string{}
+		for _, httpMethod := range rs.AllowedMethods() {
+			if rs.corsMethod(httpMethod) != nil {
+				methods = append(methods, httpMethod)
+			}
+		}
+
+		w.Header().Set("Access-Control-Allow-Methods", strings.Join(methods, ", "))
+
+		if len(allowedHeaders) > 0 {
+			w.Header().Set("Access-Control-Allow-Headers", strings.Join(allowedHeaders, ", "))
+		}
+
+		if m.corsPolicy.MaxAge > 0 {
+			w.Header().Set("Access-Control-Max-Age", strconv.Itoa(m.corsPolicy.MaxAge))
+		}
+
+		w.WriteHeader(http.StatusNoContent)
+	})
+}
+
+// allowsHeaders reports if every header of the comma separated requestHeaders list is allowed.
+func allowsHeaders(allowed []string, requestHeaders string) bool {
+	for _, h := range strings.Split(requestHeaders, ",") {
+		h = http.CanonicalHeaderKey(strings.TrimSpace(h))
+		if h != "" && !containsString(allowed, h) {
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,163 @@
+package rest_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/ehsoc/rest"
+)
+
+func corsResource() rest.Resource {
+	ct := mustGetJSONContentType()
+	apiKey := rest.NewAPIKeySecurityScheme("api_key", rest.NewHeaderParameter("X-Api-Key", reflect.String), rest.SecurityOperation{
+		Authenticator: rest.AuthenticatorFunc(func(i rest.Input) rest.AuthError {
+			return nil
+		}),
+		FailedAuthenticationResponse: rest.NewResponse(401),
+		FailedAuthorizationResponse:  rest.NewResponse(403),
+	})
+	api := rest.API{}
+	api.UseCORS(rest.CORSPolicy{
+		AllowedOrigins: []string{"https://example.com"},
+		ExposedHeaders: []string{"Location"},
+		MaxAge:         600,
+	})
+	api.Resource("car", func(r *rest.Resource) {
+		r.Get(moTest, ct).WithParameter(rest.NewHeaderParameter("x-trace", reflect.String))
+		r.Post(moTest, ct).WithRequestBody("car", Car{}).WithSecurity(apiKey)
+	})
+	return api.Resources()[0]
+}
+
+func preflightRequest(origin, method, headers string) *http.Request {
+	req, _ := http.NewRequest(http.MethodOptions, "/", nil)
+	req.Header.Set("Origin", origin)
+	req.Header.Set("Access-Control-Request-Method", method)
+	if headers != "" {
+		req.Header.Set("Access-Control-Request-Headers", headers)
+	}
+	return req
+}
+
+func TestCORSPreflight(t *testing.T) {
+	car := corsResource()
+	tt := []struct {
+		name        string
+		req         *http.Request
+		wantCode    int
+		wantHeaders map[string]string
+	}{
+		{
+			"post with body and api key",
+			preflightRequest("https://example.com", "POST", "content-type, x-api-key"),
+			http.StatusNoContent,
+			map[string]string{
+				"Access-Control-Allow-Origin":  "https://example.com",
+				"Access-Control-Allow-Methods": "GET, POST",
+				"Access-Control-Allow-Headers": "Content-Type, X-Api-Key",
+				"Access-Control-Max-Age":       "600",
+				"Vary":                         "Origin",
+			},
+		},
+		{
+			"get with header parameter",
+			preflightRequest("https://example.com", "GET", "X-Trace"),
+			http.StatusNoContent,
+			map[string]string{"Access-Control-Allow-Headers": "X-Trace"},
+		},
+		{
+			"header not declared",
+			preflightRequest("https://example.com", "GET", "X-Api-Key"),
+			http.StatusForbidden,
+			map[string]string{"Access-Control-Allow-Origin": ""},
+		},
+		{
+			"origin not allowed",
+			preflightRequest("https://evil.com", "GET", ""),
+			http.StatusForbidden,
+			map[string]string{"Access-Control-Allow-Origin": ""},
+		},
+		{
+			"method not declared",
+			preflightRequest("https://example.com", "DELETE", ""),
+			http.StatusMethodNotAllowed,
+			map[string]string{"Access-Control-Allow-Origin": "", "Allow": "GET, POST"},
+		},
+	}
+	for _, test := range tt {
+		t.Run(test.name, func(t *testing.T) {
+			resp := httptest.NewRecorder()
+			car.MethodNotAllowedHandler().ServeHTTP(resp, test.req)
+			assertResponseCode(t, resp, test.wantCode)
+			for k, v := range test.wantHeaders {
+				assertStringEqual(t, resp.Header().Get(k), v)
+			}
+		})
+	}
+}
+
+func TestCORSWithoutPolicy(t *testing.T) {
+	r := rest.NewResource("car")
+	r.Get(moTest, mustGetJSONContentType())
+	resp := httptest.NewRecorder()
+	r.MethodNotAllowedHandler().ServeHTTP(resp, preflightRequest("https://example.com", "GET", ""))
+	assertResponseCode(t, resp, http.StatusMethodNotAllowed)
+	assertStringEqual(t, resp.Header().Get("Access-Control-Allow-Origin"), "")
+}
+
+func TestCORSActualRequest(t *testing.T) {
+	api := rest.API{}
+	api.UseCORS(rest.CORSPolicy{AllowedOrigins: []string{"https://example.com"}, ExposedHeaders: []string{"Location"}})
+	ct := mustGetJSONContentType()
+	api.Resource("car", func(r *rest.Resource) {
+		r.Get(moTest, ct)
+		r.Resource("wheels", func(r *rest.Resource) {
+			r.UseCORS(rest.CORSPolicy{AllowedOrigins: []string{"*"}, AllowCredentials: true})
+			r.Get(moTest, ct)
+		})
+	})
+	api.Resource("bike", func(r *rest.Resource) {
+		r.Get(moTest, ct)
+	})
+	methods := map[string]rest.Method{}
+	var collect func(prefix string, resources []rest.Resource)
+	collect = func(prefix string, resources []rest.Resource) {
+		for _, r := range resources {
+			for _, m := range r.Methods() {
+				methods[prefix+r.Path()] = m
+			}
+			collect(prefix+r.Path()+"/", r.Resources())
+		}
+	}
+	collect("", api.Resources())
+	tt := []struct {
+		resource        string
+		origin          string
+		wantOrigin      string
+		wantCredentials string
+		wantExpose      string
+	}{
+		{"car", "https://example.com", "https://example.com", "", "Location"},
+		{"car", "https://evil.com", "", "", ""},
+		{"car", "", "", "", ""},
+		{"car/wheels", "https://any.com", "https://any.com", "true", ""},
+		{"bike", "https://example.com", "https://example.com", "", "Location"},
+	}
+	for _, test := range tt {
+		t.Run(test.resource+" "+test.origin, func(t *testing.T) {
+			req, _ := http.NewRequest(http.MethodGet, "/", nil)
+			if test.origin != "" {
+				req.Header.Set("Origin", test.origin)
+			}
+			resp := httptest.NewRecorder()
+			m := methods[test.resource]
+			m.ServeHTTP(resp, req)
+			assertResponseCode(t, resp, 200)
+			assertStringEqual(t, resp.Header().Get("Access-Control-Allow-Origin"), test.wantOrigin)
+			assertStringEqual(t, resp.Header().Get("Access-Control-Allow-Credentials"), test.wantCredentials)
+			assertStringEqual(t, resp.Header().Get("Access-Control-Expose-Headers"), test.wantExpose)
+		})
+	}
+}
@@ -2,7 +2,9 @@ package rest
 
 import (
 	"errors"
+	"mime"
 	"net/http"
+	"sort"
 	"strings"
 
 	"github.com/ehsoc/rest/encdec"
@@ -14,18 +16,130 @@ type DefaultNegotiator struct {
 }
 
 // NegotiateEncoder resolves the MIME type and Encoder to be used by the Handler to process the response.
+// The Accept header is negotiated as in RFC 7231: the available MIME types are ranked by the quality value (q)
+// of the most specific media range that matches them, including "*/*" and "type/*" wildcards,
+// and a quality value of 0 excludes the MIME type. Ties are resolved by the media range specificity,
+// the position in the header, and the default encoder.
+// Vendor media types like "application/vnd.acme.pet+json" match the available MIME type of their structured syntax suffix,
+// and the parameters of the media range are passed to an encdec.OptionsEncoder encoder, like "application/json; indent=2".
+// If none of the available MIME types is acceptable, an ErrorNotAcceptable error is returned.
 func (d DefaultNegotiator) NegotiateEncoder(r *http.Request, cts *ContentTypes) (string, encdec.Encoder, error) {
 	accept := r.Header.Get("Accept")
-	if strings.Trim(accept, "") != "" {
-		mediaTypes := httputil.ParseMediaTypes(accept)
-		for _, mediaType := range mediaTypes {
-			enc, err := cts.GetEncoder(mediaType.Name)
-			if err == nil {
-				return mediaType.Name, enc, nil
-			}
+	if strings.TrimSpace(accept) == "" {
+		return cts.GetDefaultEncoder()
+	}
+
+	mediaRanges, err := httputil.ParseMediaTypesStrict(accept)
+	if err != nil {
+		return "", nil, &ErrorNotAcceptable{accept, err}
+	}
+
+	if len(mediaRanges) == 0 {
+		return cts.GetDefaultEncoder()
+	}
+
+	best := acceptMatch{index: -1}
+
+	for _, key := range cts.encoderMIMETypes() {
+		m := bestAcceptMatch(key, mediaRanges)
+		if m.index < 0 || m.quality == 0 {
+			continue
 		}
+
+		if best.index < 0 || m.betterThan(best) {
+			best = m
+		}
+	}
+
+	if best.index < 0 {
+		return "", nil, &ErrorNotAcceptable{accept, nil}
+	}
+
+	return best.negotiated(cts)
+}
+
+// acceptMatch is an available MIME type and the media range of the Accept header that matches it.
+type acceptMatch struct {
+	key         string
+	mediaRange  httputil.MediaType
+	quality     float64
+	specificity int
+	// score is the match score of the key with a media range without wildcards, see mediaTypeScore
+	score int
+	// index is the position of the media range in the Accept header, -1 means no match
+	index int
+}
+
+func (m acceptMatch) betterThan(other acceptMatch) bool {
+	if m.quality != other.quality {
+		return m.quality > other.quality
+	}
+
+	if m.specificity != other.specificity {
+		return m.specificity > other.specificity
+	}
+
+	if m.index != other.index {
+		return m.index < other.index
 	}
-	return cts.GetDefaultEncoder()
+
+	return m.score > other.score
+}
+
+// negotiated returns the MIME type and the encoder of the match.
+// A media range without wildcards is the MIME type of the response, with the parameters of the available MIME type,
+// and its parameters are passed to the encoder as options.
+func (m acceptMatch) negotiated(cts *ContentTypes) (string, encdec.Encoder, error) {
+	enc := cts.encoderContentTypes[m.key]
+	if m.specificity < 2 {
+		return m.key, enc, nil
+	}
+
+	_, keyParams, _ := mime.ParseMediaType(m.key)
+	mimeType := m.mediaRange.Name
+
+	if len(keyParams) > 0 {
+		mimeType = mime.FormatMediaType(mimeType, keyParams)
+	}
+
+	return mimeType, withEncoderOptions(enc, m.mediaRange.Params), nil
+}
+
+// bestAcceptMatch returns the most specific media range matching the available MIME type key.
+// The structured syntax suffix is only matched for the vendor, personal and unregistered media types.
+func bestAcceptMatch(key string, mediaRanges []httputil.MediaType) acceptMatch {
+	match := acceptMatch{key: key, index: -1}
+	keyName, _, err := mime.ParseMediaType(key)
+
+	if err != nil {
+		return match
+	}
+
+	for i, mr := range mediaRanges {
+		if mr.Specificity() < 2 && !mr.Matches(keyName) {
+			continue
+		}
+
+		score, ok := 0, true
+		if mr.Specificity() >= 2 {
+			score, ok = mediaTypeScore(key, mr.Name, mr.Params)
+		}
+
+		// standard tree media types with a suffix, like application/xhtml+xml, have their own semantics
+		if !ok || (mr.Specificity() >= 2 && keyName != mr.Name && !isVendorTree(mr.Name)) {
+			continue
+		}
+
+		if match.index < 0 || mr.Specificity() > match.specificity {
+			match.score = score
+			match.mediaRange = mr
+			match.quality = mr.Quality()
+			match.specificity = mr.Specificity()
+			match.index = i
+		}
+	}
+
+	return match
 }
 
 // NegotiateDecoder resolves the MIME type and Decoder to be used by the Handler to process the request.
@@ -34,9 +148,13 @@ func (d DefaultNegotiator) NegotiateDecoder(r *http.Request, cts *ContentTypes)
 	// Only if it is a non-empty and not nil Body we will require a Content-Type header
 	if r.Body != http.NoBody && r.Body != nil {
 		if strings.Trim(ct, "") != "" {
-			mediaTypes := httputil.ParseMediaTypes(ct)
+			mediaTypes, err := httputil.ParseMediaTypesStrict(ct)
+			if err != nil {
+				return "", nil, errors.New("unavailable decoder")
+			}
+
 			for _, mediaType := range mediaTypes {
-				enc, err := cts.GetDecoder(mediaType.Name)
+				enc, err := cts.GetDecoder(mime.FormatMediaType(mediaType.Name, mediaType.Params))
 				if err == nil {
 					return mediaType.Name, enc, nil
 				}
@@ -46,3 +164,37 @@ func (d DefaultNegotiator) NegotiateDecoder(r *http.Request, cts *ContentTypes)
 	}
 	return cts.GetDefaultDecoder()
 }
+
+// encoderMIMETypes returns the available encoder MIME types, the default first and then sorted by name.
+func (h *ContentTypes) encoderMIMETypes() []string {
+	mimeTypes := []string{}
+	for mimeType := range h.encoderContentTypes {
+		mimeTypes = append(mimeTypes, mimeType)
+	}
+
+	sort.Slice(mimeTypes, func(i, j int) bool {
+		if (mimeTypes[i] == h.defaultEncoder) != (mimeTypes[j] == h.defaultEncoder) {
+			return mimeTypes[i] == h.defaultEncoder
+		}
+		return mimeTypes[i] < mimeTypes[j]
+	})
+
+	return mimeTypes
+}
+
+// isVendorTree reports whether the media type is in the vendor (vnd.), personal (prs.) or unregistered (x.) trees (RFC 6838).
+func isVendorTree(name string) bool {
+	i := strings.Index(name, "/")
+	if i < 0 {
+		return false
+	}
+
+	subtype := name[i+1:]
+	for _, prefix := range []string{"vnd.", "prs.", "x.", "x-"} {
+		if strings.HasPrefix(subtype, prefix) {
+			return true
+		}
+	}
+
+	return false
+}
@@ -22,6 +22,13 @@ var testNegotiateEncoder = []struct {
 	{"image/jpeg, application/octet-stream, application/xml ,image/gif", "application/octet-stream"},
 	{"", "application/json"},
 	{"*/*", "application/json"},
+	{"application/xml;q=0.5, application/json;q=0.4", "application/xml"},
+	{"application/*;q=0.5, application/json;q=0.4, application/octet-stream;q=0.1", "application/xml"},
+	{"application/*, application/json;q=0", "application/octet-stream"},
+	{"*/*;q=0.1, application/json;q=0", "application/octet-stream"},
+	{"application/*", "application/json"},
+	{"text/*, application/xml;q=0.8", "application/xml"},
+	{"APPLICATION/XML", "application/xml"},
 }
 
 func TestNegotiateEncoder(t *testing.T) {
@@ -38,6 +45,57 @@ func TestNegotiateEncoder(t *testing.T) {
 			}
 		})
 	}
+	t.Run("structured suffix and parameters", func(t *testing.T) {
+		jsonV2 := &EncodeDecoderSpy{}
+		cts := mustGetCTS()
+		cts.AddEncoder("application/json; version=2", jsonV2, false)
+		tt := []struct {
+			accept    string
+			wantType  string
+			wantOther bool
+		}{
+			{"application/vnd.acme.pet+json", "application/vnd.acme.pet+json", false},
+			{"application/vnd.acme.pet+json; version=2", "application/vnd.acme.pet+json; version=2", true},
+			{"application/json; version=2, application/json;q=0.5", "application/json; version=2", true},
+			{"application/json; charset=utf-8", "application/json", false},
+			{"application/xhtml+xml, application/xml;q=0.9", "application/xml", false},
+		}
+		for _, test := range tt {
+			n := rest.DefaultNegotiator{}
+			request, _ := http.NewRequest(http.MethodGet, "/", nil)
+			request.Header.Set("Accept", test.accept)
+			got, enc, err := n.NegotiateEncoder(request, &cts)
+			assertNoErrorFatal(t, err)
+			if got != test.wantType {
+				t.Errorf("got:%s want:%s", got, test.wantType)
+			}
+			if (enc == jsonV2) != test.wantOther {
+				t.Errorf("%s: got encoder: %T", test.accept, enc)
+			}
+		}
+	})
+	t.Run("encoder options", func(t *testing.T) {
+		n := rest.DefaultNegotiator{}
+		request, _ := http.NewRequest(http.MethodGet, "/", nil)
+		request.Header.Set("Accept", "application/json; indent=2")
+		got, enc, err := n.NegotiateEncoder(request, &cts)
+		assertNoErrorFatal(t, err)
+		assertStringEqual(t, got, "application/json")
+		if enc != (encdec.JSONEncoder{Indent: "  "}) {
+			t.Errorf("got: %#v", enc)
+		}
+	})
+	t.Run("not acceptable", func(t *testing.T) {
+		for _, accept := range []string{"text/html", "application/json;q=0, application/xml;q=0, application/octet-stream;q=0", "*/*;q=0", "application/"} {
+			n := rest.DefaultNegotiator{}
+			request, _ := http.NewRequest(http.MethodPost, "/", nil)
+			request.Header.Set("Accept", accept)
+			_, _, err := n.NegotiateEncoder(request, &cts)
+			if _, ok := err.(*rest.ErrorNotAcceptable); !ok {
+				t.Errorf("%s: got: %T want: %T", accept, err, &rest.ErrorNotAcceptable{})
+			}
+		}
+	})
 	t.Run("no default content-type", func(t *testing.T) {
 		n := rest.DefaultNegotiator{}
 		request, _ := http.NewRequest(http.MethodPost, "/", nil)
@@ -0,0 +1,134 @@
+package encdec
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"reflect"
+)
+
+// CSVEncoder implements Encoder to encode a slice of structs on CSV format (RFC 4180).
+// Each struct is a record, and the first record is the header with the field names.
+// The name of a field is the field `csv` tag, or the field name if there is no tag.
+// A field with the `csv:"-"` tag is ignored. The field values are formatted as with fmt.Sprint,
+// and a nil pointer is an empty value.
+type CSVEncoder struct {
+	// Comma is the field delimiter, ',' if zero.
+	Comma rune
+	// NoHeader omits the header record.
+	NoHeader bool
+}
+
+// Encode implements method of Encoder interface.
+// v must be a slice or array of structs or pointers to structs, or a pointer to one.
+// An empty slice of interfaces is encoded as nothing.
+func (c CSVEncoder) Encode(w io.Writer, v interface{}) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() == reflect.Ptr && !rv.IsNil() {
+		rv = rv.Elem()
+	}
+
+	if rv.Kind() != reflect.Slice && rv.Kind() != reflect.Array {
+		return ErrorCSVEncoderNoStructSlice
+	}
+
+	elemType := rv.Type().Elem()
+	if elemType.Kind() == reflect.Ptr {
+		elemType = elemType.Elem()
+	}
+
+	// an empty slice of interfaces, like the items of an empty iterator, has no fields for the header
+	if elemType.Kind() == reflect.Interface && rv.Len() == 0 {
+		return nil
+	}
+
+	if elemType.Kind() != reflect.Struct {
+		return ErrorCSVEncoderNoStructSlice
+	}
+
+	fields, header := csvFields(elemType)
+	writer := csv.NewWriter(w)
+
+	if c.Comma != 0 {
+		writer.Comma = c.Comma
+	}
+
+	if !c.NoHeader {
+		if err := writer.Write(header); err != nil {
+			return err
+		}
+	}
+
+	for i := 0; i < rv.Len(); i++ {
+		elem := rv.Index(i)
+		if elem.Kind() == reflect.Ptr {
+			if elem.IsNil() {
+				continue
+			}
+
+			elem = elem.Elem()
+		}
+
+		record := make([]string, len(fields))
+		for j, field := range fields {
+			record[j] = csvValue(elem.Field(field))
+		}
+
+		if err := writer.Write(record); err != nil {
+			return err
+		}
+	}
+
+	writer.Flush()
+
+	return writer.Error()
+}
+
+// WithOptions implements OptionsEncoder, the "header" parameter of the text/csv media type (RFC 4180)
+// with the "absent" value omits the header record.
+func (c CSVEncoder) WithOptions(options map[string]string) Encoder {
+	switch options["header"] {
+	case "absent":
+		c.NoHeader = true
+	case "present":
+		c.NoHeader = false
+	}
+
+	return c
+}
+
+// csvFields returns the indexes and the names of the encoded fields of the struct type t.
+func csvFields(t reflect.Type) ([]int, []string) {
+	fields := []int{}
+	names := []string{}
+
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		name := field.Tag.Get("csv")
+
+		if field.PkgPath != "" || name == "-" {
+			continue
+		}
+
+		if name == "" {
+			name = field.Name
+		}
+
+		fields = append(fields, i)
+		names = append(names, name)
+	}
+
+	return fields, names
+}
+
+func csvValue(v reflect.Value) string {
+	if v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			return ""
+		}
+
+		v = v.Elem()
+	}
+
+	return fmt.Sprint(v.Interface())
+}
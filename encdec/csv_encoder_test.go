@@ -0,0 +1,83 @@
+package encdec_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/ehsoc/rest/encdec"
+)
+
+type CSVPet struct {
+	ID       int64 `csv:"id"`
+	Name     string
+	Tags     []string `csv:"tags"`
+	Weight   *float64 `csv:"weight"`
+	Internal string   `csv:"-"`
+}
+
+func TestCSVEncoder(t *testing.T) {
+	weight := 12.5
+	pets := []CSVPet{{1, "Rex", nil, &weight, "foo"}, {2, "Tom, the cat", []string{"cat"}, nil, ""}}
+	want := "id,Name,tags,weight\n1,Rex,[],12.5\n2,\"Tom, the cat\",[cat],\n"
+	tt := []struct {
+		name string
+		v    interface{}
+	}{
+		{"slice", pets},
+		{"pointer to slice", &pets},
+		{"slice of pointers", []*CSVPet{&pets[0], nil, &pets[1]}},
+		{"array", [2]CSVPet{pets[0], pets[1]}},
+	}
+	for _, test := range tt {
+		t.Run(test.name, func(t *testing.T) {
+			buf := new(bytes.Buffer)
+			err := encdec.CSVEncoder{}.Encode(buf, test.v)
+			if err != nil {
+				t.Fatalf("not expecting error: %v", err)
+			}
+			if buf.String() != want {
+				t.Errorf("got:%q want:%q", buf.String(), want)
+			}
+		})
+	}
+	t.Run("not a slice of structs", func(t *testing.T) {
+		for _, v := range []interface{}{pets[0], []string{"Rex"}, []interface{}{pets[0]}, nil} {
+			err := encdec.CSVEncoder{}.Encode(new(bytes.Buffer), v)
+			if err != encdec.ErrorCSVEncoderNoStructSlice {
+				t.Errorf("got:%v want:%v", err, encdec.ErrorCSVEncoderNoStructSlice)
+			}
+		}
+	})
+	t.Run("empty slice of interfaces", func(t *testing.T) {
+		buf := new(bytes.Buffer)
+		err := encdec.CSVEncoder{}.Encode(buf, []interface{}{})
+		if err != nil || buf.Len() != 0 {
+			t.Errorf("got:%q, %v want an empty body", buf.String(), err)
+		}
+	})
+}
+
+func TestCSVEncoderWithOptions(t *testing.T) {
+	pets := []CSVPet{{ID: 1, Name: "Rex"}}
+	var optionsEncoder encdec.OptionsEncoder = encdec.CSVEncoder{Comma: ';'}
+	tt := []struct {
+		header string
+		want   string
+	}{
+		{"absent", "1;Rex;[];\n"},
+		{"present", "id;Name;tags;weight\n1;Rex;[];\n"},
+		{"", "id;Name;tags;weight\n1;Rex;[];\n"},
+	}
+	for _, test := range tt {
+		t.Run(test.header, func(t *testing.T) {
+			buf := new(bytes.Buffer)
+			err := optionsEncoder.WithOptions(map[string]string{"header": test.header}).Encode(buf, pets)
+			if err != nil {
+				t.Fatalf("not expecting error: %v", err)
+			}
+			if buf.String() != test.want {
+				t.Errorf("got:%q want:%q", buf.String(), test.want)
+			}
+		})
+	}
+}
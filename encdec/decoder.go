@@ -18,3 +18,8 @@ type DecoderFunc func(r io.Reader, v interface{}) error
 func (e DecoderFunc) Decode(r io.Reader, v interface{}) error {
 	return e(r, v)
 }
+
+// StrictDecoder is implemented by a Decoder that can reject the input fields that are not present in v.
+type StrictDecoder interface {
+	DecodeStrict(r io.Reader, v interface{}) error
+}
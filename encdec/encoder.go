@@ -1,6 +1,9 @@
 package encdec
 
-import "io"
+import (
+	"context"
+	"io"
+)
 
 // Encoder purpose is to provide a common interface wraping around encoder libraries
 // so that they can easily be passed as arguments values to be executed by other methods.
@@ -18,3 +21,16 @@ type EncoderFunc func(w io.Writer, v interface{}) error
 func (e EncoderFunc) Encode(w io.Writer, v interface{}) error {
 	return e(w, v)
 }
+
+// OptionsEncoder is implemented by an Encoder that can be configured with the parameters of the negotiated media type,
+// like the indent in "application/json; indent=2". WithOptions returns the configured Encoder,
+// ignoring the options that it doesn't support.
+type OptionsEncoder interface {
+	WithOptions(options map[string]string) Encoder
+}
+
+// ContextEncoder is implemented by an Encoder that can stop encoding when the context is done,
+// like the streaming encoders, that write the items of an Iterator or a channel as they are produced.
+type ContextEncoder interface {
+	EncodeContext(ctx context.Context, w io.Writer, v interface{}) error
+}
@@ -4,3 +4,7 @@ import "errors"
 
 var ErrorTextDecoderNoString = errors.New("v is not a string")
 var ErrorTextDecoderNoValidPointer = errors.New("v is not a valid pointer")
+var ErrorFormDecoderNoStruct = errors.New("v is not a pointer to a struct")
+var ErrorFormDecoderUnknownKey = errors.New("unknown key")
+var ErrorFormDecoderUnsupportedType = errors.New("unsupported field type")
+var ErrorCSVEncoderNoStructSlice = errors.New("v is not a slice of structs")
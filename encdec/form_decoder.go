@@ -0,0 +1,142 @@
+package encdec
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/url"
+	"reflect"
+	"strconv"
+)
+
+// FormDecoder implements Decoder to decode the application/x-www-form-urlencoded format.
+// The form values are stored in the fields of the struct pointed by v, matching the field `form` tag,
+// or the field name if there is no tag. A field with the `form:"-"` tag is ignored.
+// The supported field types are string, bool, integers, floats, pointers to them and slices of them.
+// v can also be a *url.Values.
+type FormDecoder struct{}
+
+// Decode implements Decode method of interface Decoder
+func (f FormDecoder) Decode(r io.Reader, v interface{}) error {
+	return decodeForm(r, v, false)
+}
+
+// DecodeStrict is like Decode, but returns an error if the form has a key that doesn't match any field of v.
+func (f FormDecoder) DecodeStrict(r io.Reader, v interface{}) error {
+	return decodeForm(r, v, true)
+}
+
+func decodeForm(r io.Reader, v interface{}, strict bool) error {
+	b, err := ioutil.ReadAll(r)
+	if err != nil {
+		return err
+	}
+
+	values, err := url.ParseQuery(string(b))
+	if err != nil {
+		return err
+	}
+
+	if dst, ok := v.(*url.Values); ok && dst != nil {
+		*dst = values
+		return nil
+	}
+
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() || rv.Elem().Kind() != reflect.Struct {
+		return ErrorFormDecoderNoStruct
+	}
+
+	rv = rv.Elem()
+	known := map[string]bool{}
+
+	for i := 0; i < rv.NumField(); i++ {
+		field := rv.Type().Field(i)
+		name := field.Tag.Get("form")
+
+		if field.PkgPath != "" || name == "-" {
+			continue
+		}
+
+		if name == "" {
+			name = field.Name
+		}
+
+		known[name] = true
+
+		fieldValues, ok := values[name]
+		if !ok || len(fieldValues) == 0 {
+			continue
+		}
+
+		if err := setFormValue(rv.Field(i), fieldValues); err != nil {
+			return fmt.Errorf("form key '%s': %w", name, err)
+		}
+	}
+
+	if strict {
+		for key := range values {
+			if !known[key] {
+				return fmt.Errorf("form key '%s': %w", key, ErrorFormDecoderUnknownKey)
+			}
+		}
+	}
+
+	return nil
+}
+
+// setFormValue sets the form values into v. v gets the first value, unless it is a slice.
+func setFormValue(v reflect.Value, values []string) error {
+	switch v.Kind() {
+	case reflect.Slice:
+		slice := reflect.MakeSlice(v.Type(), len(values), len(values))
+		for i, value := range values {
+			if err := setFormValue(slice.Index(i), []string{value}); err != nil {
+				return err
+			}
+		}
+
+		v.Set(slice)
+	case reflect.Ptr:
+		elem := reflect.New(v.Type().Elem())
+		if err := setFormValue(elem.Elem(), values); err != nil {
+			return err
+		}
+
+		v.Set(elem)
+	case reflect.String:
+		v.SetString(values[0])
+	case reflect.Bool:
+		b, err := strconv.ParseBool(values[0])
+		if err != nil {
+			return err
+		}
+
+		v.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(values[0], 10, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+
+		v.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, err := strconv.ParseUint(values[0], 10, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+
+		v.SetUint(n)
+	case reflect.Float32, reflect.Float64:
+		n, err := strconv.ParseFloat(values[0], v.Type().Bits())
+		if err != nil {
+			return err
+		}
+
+		v.SetFloat(n)
+	default:
+		return fmt.Errorf("%w: %s", ErrorFormDecoderUnsupportedType, v.Type())
+	}
+
+	return nil
+}
@@ -0,0 +1,94 @@
+package encdec_test
+
+import (
+	"bytes"
+	"errors"
+	"net/url"
+	"reflect"
+	"testing"
+
+	"github.com/ehsoc/rest/encdec"
+)
+
+type FormPet struct {
+	Name     string   `form:"name"`
+	Age      int      `form:"age"`
+	Weight   float64  `form:"weight"`
+	Vaccined bool     `form:"vaccined"`
+	Tags     []string `form:"tags"`
+	OwnerID  *uint    `form:"ownerId"`
+	Status   string
+	Internal string `form:"-"`
+}
+
+func TestFormDecoder(t *testing.T) {
+	ownerID := uint(7)
+	want := FormPet{"Rex", 3, 12.5, true, []string{"dog", "big"}, &ownerID, "available", ""}
+	gotPet := FormPet{}
+	body := "name=Rex&age=3&weight=12.5&vaccined=true&tags=dog&tags=big&ownerId=7&Status=available&Internal=foo"
+
+	decoder := encdec.FormDecoder{}
+
+	err := decoder.Decode(bytes.NewBufferString(body), &gotPet)
+	if err != nil {
+		t.Fatalf("not expecting error: %v", err)
+	}
+
+	if !reflect.DeepEqual(gotPet, want) {
+		t.Errorf("got:%v want:%v", gotPet, want)
+	}
+}
+
+func TestFormDecoderErrors(t *testing.T) {
+	decoder := encdec.FormDecoder{}
+	tt := []struct {
+		name    string
+		body    string
+		v       interface{}
+		wantErr error
+	}{
+		{"not a pointer", "name=Rex", FormPet{}, encdec.ErrorFormDecoderNoStruct},
+		{"not a struct", "name=Rex", new(string), encdec.ErrorFormDecoderNoStruct},
+		{"unsupported type", "Owner=Bob", &struct{ Owner map[string]string }{}, encdec.ErrorFormDecoderUnsupportedType},
+		{"invalid value", "age=old", &FormPet{}, nil},
+	}
+	for _, test := range tt {
+		t.Run(test.name, func(t *testing.T) {
+			err := decoder.Decode(bytes.NewBufferString(test.body), test.v)
+			if err == nil {
+				t.Fatalf("expecting error")
+			}
+			if test.wantErr != nil && !errors.Is(err, test.wantErr) {
+				t.Errorf("got:%v want:%v", err, test.wantErr)
+			}
+		})
+	}
+}
+
+func TestFormDecoderStrict(t *testing.T) {
+	var decoder encdec.StrictDecoder = encdec.FormDecoder{}
+	t.Run("known keys", func(t *testing.T) {
+		err := decoder.DecodeStrict(bytes.NewBufferString("name=Rex"), &FormPet{})
+		if err != nil {
+			t.Fatalf("not expecting error: %v", err)
+		}
+	})
+	t.Run("unknown key", func(t *testing.T) {
+		err := decoder.DecodeStrict(bytes.NewBufferString("name=Rex&color=brown"), &FormPet{})
+		if !errors.Is(err, encdec.ErrorFormDecoderUnknownKey) {
+			t.Errorf("got:%v want:%v", err, encdec.ErrorFormDecoderUnknownKey)
+		}
+	})
+}
+
+func TestFormDecoderValues(t *testing.T) {
+	values := url.Values{}
+	err := encdec.FormDecoder{}.Decode(bytes.NewBufferString("name=Rex&tags=dog&tags=big"), &values)
+	if err != nil {
+		t.Fatalf("not expecting error: %v", err)
+	}
+	want := url.Values{"name": {"Rex"}, "tags": {"dog", "big"}}
+	if !reflect.DeepEqual(values, want) {
+		t.Errorf("got:%v want:%v", values, want)
+	}
+}
@@ -0,0 +1,111 @@
+package encdec
+
+import (
+	"context"
+	"io"
+	"reflect"
+)
+
+// Iterator is a sequence of items produced one by one, like the rows of a database query,
+// that can be returned as a body to be written by a streaming encoder without building the whole list.
+// Next advances to the next item, and returns false when there are no more items or an error happened.
+// If the Iterator implements io.Closer, it is closed after the iteration.
+type Iterator interface {
+	Next() bool
+	Item() interface{}
+	Err() error
+}
+
+// IsStream returns true if v is an Iterator or a receive channel.
+func IsStream(v interface{}) bool {
+	if _, ok := v.(Iterator); ok {
+		return true
+	}
+
+	rv := reflect.ValueOf(v)
+
+	return rv.Kind() == reflect.Chan && rv.Type().ChanDir()&reflect.RecvDir != 0
+}
+
+// EachItem calls fn for each item of v, an Iterator, a receive channel, a slice or an array,
+// until there are no more items, fn returns an error or ctx is done.
+// It returns false if v is not any of them.
+func EachItem(ctx context.Context, v interface{}, fn func(item interface{}) error) (bool, error) {
+	if it, ok := v.(Iterator); ok {
+		return true, eachIteratorItem(ctx, it, fn)
+	}
+
+	rv := reflect.ValueOf(v)
+
+	switch {
+	case rv.Kind() == reflect.Chan && rv.Type().ChanDir()&reflect.RecvDir != 0:
+		return true, eachChannelItem(ctx, rv, fn)
+	case rv.Kind() == reflect.Slice || rv.Kind() == reflect.Array:
+		for i := 0; i < rv.Len(); i++ {
+			if err := ctx.Err(); err != nil {
+				return true, err
+			}
+
+			if err := fn(rv.Index(i).Interface()); err != nil {
+				return true, err
+			}
+		}
+
+		return true, nil
+	}
+
+	return false, nil
+}
+
+func eachIteratorItem(ctx context.Context, it Iterator, fn func(item interface{}) error) error {
+	if closer, ok := it.(io.Closer); ok {
+		defer closer.Close()
+	}
+
+	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		if !it.Next() {
+			return it.Err()
+		}
+
+		if err := fn(it.Item()); err != nil {
+			return err
+		}
+	}
+}
+
+func eachChannelItem(ctx context.Context, ch reflect.Value, fn func(item interface{}) error) error {
+	cases := []reflect.SelectCase{
+		{Dir: reflect.SelectRecv, Chan: reflect.ValueOf(ctx.Done())},
+		{Dir: reflect.SelectRecv, Chan: ch},
+	}
+
+	for {
+		chosen, item, ok := reflect.Select(cases)
+		if chosen == 0 {
+			return ctx.Err()
+		}
+
+		if !ok {
+			return nil
+		}
+
+		if err := fn(item.Interface()); err != nil {
+			return err
+		}
+	}
+}
+
+// flusher is implemented by the writers that can send the buffered data to the client, like an http.ResponseWriter.
+type flusher interface {
+	Flush()
+}
+
+func flush(w io.Writer) {
+	if f, ok := w.(flusher); ok {
+		f.Flush()
+	}
+}
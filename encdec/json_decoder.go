@@ -12,3 +12,10 @@ type JSONDecoder struct{}
 func (j JSONDecoder) Decode(r io.Reader, v interface{}) error {
 	return json.NewDecoder(r).Decode(v)
 }
+
+// DecodeStrict is like Decode, but returns an error if the JSON object has a key that doesn't match any field of v.
+func (j JSONDecoder) DecodeStrict(r io.Reader, v interface{}) error {
+	decoder := json.NewDecoder(r)
+	decoder.DisallowUnknownFields()
+	return decoder.Decode(v)
+}
@@ -23,3 +23,23 @@ func TestJSONDecoder(t *testing.T) {
 		t.Errorf("got:%v want:%v", gotCar, car)
 	}
 }
+
+func TestJSONDecoderStrict(t *testing.T) {
+	var decoder encdec.StrictDecoder = encdec.JSONDecoder{}
+	t.Run("known fields", func(t *testing.T) {
+		gotCar := Car{}
+		err := decoder.DecodeStrict(bytes.NewBufferString(`{"Brand":"Fiat"}`), &gotCar)
+		if err != nil {
+			t.Fatalf("not expecting error: %v", err)
+		}
+		if gotCar.Brand != "Fiat" {
+			t.Errorf("got:%v want:%v", gotCar.Brand, "Fiat")
+		}
+	})
+	t.Run("unknown field", func(t *testing.T) {
+		err := decoder.DecodeStrict(bytes.NewBufferString(`{"Brand":"Fiat","Wheels":4}`), &Car{})
+		if err == nil {
+			t.Errorf("expecting error")
+		}
+	})
+}
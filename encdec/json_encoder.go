@@ -3,12 +3,34 @@ package encdec
 import (
 	"encoding/json"
 	"io"
+	"strconv"
+	"strings"
 )
 
+// maxJSONIndent is the maximum number of spaces accepted by the indent option.
+const maxJSONIndent = 8
+
 // JSONEncoder implements Encoder interface to encode JSON format
-type JSONEncoder struct{}
+type JSONEncoder struct {
+	// Indent is the indentation of each level of the JSON output, no indentation if empty.
+	Indent string
+}
 
 // Encode writes to w the JSON encoding of v
 func (j JSONEncoder) Encode(w io.Writer, v interface{}) error {
-	return json.NewEncoder(w).Encode(v)
+	encoder := json.NewEncoder(w)
+	if j.Indent != "" {
+		encoder.SetIndent("", j.Indent)
+	}
+
+	return encoder.Encode(v)
+}
+
+// WithOptions returns a JSONEncoder configured with the indent option, the number of spaces (up to 8) of indentation.
+func (j JSONEncoder) WithOptions(options map[string]string) Encoder {
+	if indent, err := strconv.Atoi(options["indent"]); err == nil && indent >= 0 && indent <= maxJSONIndent {
+		j.Indent = strings.Repeat(" ", indent)
+	}
+
+	return j
 }
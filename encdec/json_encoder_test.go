@@ -21,3 +21,28 @@ func TestJSONEncoder(t *testing.T) {
 		t.Errorf("got:%v want:%v", gotCar, car)
 	}
 }
+
+func TestJSONEncoderWithOptions(t *testing.T) {
+	var optionsEncoder encdec.OptionsEncoder = encdec.JSONEncoderDecoder{}
+	tt := []struct {
+		options map[string]string
+		want    string
+	}{
+		{map[string]string{"indent": "2"}, "{\n  \"Brand\": \"Fiat\"\n}\n"},
+		{map[string]string{"indent": "0"}, "{\"Brand\":\"Fiat\"}\n"},
+		{map[string]string{"indent": "100"}, "{\"Brand\":\"Fiat\"}\n"},
+		{map[string]string{"indent": "foo", "version": "2"}, "{\"Brand\":\"Fiat\"}\n"},
+	}
+	for _, test := range tt {
+		t.Run(test.options["indent"], func(t *testing.T) {
+			buf := new(bytes.Buffer)
+			err := optionsEncoder.WithOptions(test.options).Encode(buf, struct{ Brand string }{"Fiat"})
+			if err != nil {
+				t.Fatalf("not expecting error: %v", err)
+			}
+			if buf.String() != test.want {
+				t.Errorf("got:%q want:%q", buf.String(), test.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,60 @@
+package encdec
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+)
+
+// JSONStreamEncoder implements Encoder and ContextEncoder interfaces to encode JSON format,
+// writing the items of an Iterator, a channel, a slice or an array as a JSON array, one item at a time.
+// The writer is flushed after each item, if it implements a Flush method like http.Flusher.
+// Any other value is encoded as with JSONEncoder.
+type JSONStreamEncoder struct{}
+
+// Encode writes to w the JSON encoding of v
+func (j JSONStreamEncoder) Encode(w io.Writer, v interface{}) error {
+	return j.EncodeContext(context.Background(), w, v)
+}
+
+// EncodeContext is like Encode, but stops writing the items when ctx is done, returning the context error.
+// The JSON array is not closed if the writing is stopped, so the client can tell that the response is incomplete.
+func (j JSONStreamEncoder) EncodeContext(ctx context.Context, w io.Writer, v interface{}) error {
+	if !IsStream(v) {
+		return json.NewEncoder(w).Encode(v)
+	}
+
+	if _, err := io.WriteString(w, "["); err != nil {
+		return err
+	}
+
+	separator := ""
+
+	_, err := EachItem(ctx, v, func(item interface{}) error {
+		b, err := json.Marshal(item)
+		if err != nil {
+			return err
+		}
+
+		if _, err := io.WriteString(w, separator); err != nil {
+			return err
+		}
+
+		if _, err := w.Write(b); err != nil {
+			return err
+		}
+
+		separator = ","
+
+		flush(w)
+
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	_, err = io.WriteString(w, "]\n")
+
+	return err
+}
@@ -0,0 +1,72 @@
+package encdec_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/ehsoc/rest/encdec"
+)
+
+func TestJSONStreamEncoder(t *testing.T) {
+	want := []Car{{"Fiat"}, {"Ford"}}
+	tt := []struct {
+		name string
+		v    interface{}
+	}{
+		{"iterator", &CarIterator{cars: want}},
+		{"channel", carChannel(want...)},
+		{"slice", want},
+	}
+	for _, test := range tt {
+		t.Run(test.name, func(t *testing.T) {
+			buf := &FlushRecorder{}
+			err := encdec.JSONStreamEncoder{}.Encode(buf, test.v)
+			if err != nil {
+				t.Fatalf("not expecting error: %v", err)
+			}
+			gotCars := []Car{}
+			json.NewDecoder(buf).Decode(&gotCars)
+			if !reflect.DeepEqual(gotCars, want) {
+				t.Errorf("got:%v want:%v", gotCars, want)
+			}
+		})
+	}
+	t.Run("flushes each item", func(t *testing.T) {
+		buf := &FlushRecorder{}
+		encdec.JSONStreamEncoder{}.Encode(buf, carChannel(want...))
+		wantFlushes := []string{`[{"Brand":"Fiat"}`, `[{"Brand":"Fiat"},{"Brand":"Ford"}`}
+		if !reflect.DeepEqual(buf.flushes, wantFlushes) {
+			t.Errorf("got:%q want:%q", buf.flushes, wantFlushes)
+		}
+	})
+	t.Run("empty stream", func(t *testing.T) {
+		buf := &FlushRecorder{}
+		encdec.JSONStreamEncoder{}.Encode(buf, carChannel())
+		if buf.String() != "[]\n" {
+			t.Errorf("got:%q want:%q", buf.String(), "[]\n")
+		}
+	})
+	t.Run("iterator error", func(t *testing.T) {
+		iteratorErr := errors.New("database error")
+		err := encdec.JSONStreamEncoder{}.Encode(&FlushRecorder{}, &CarIterator{cars: want, err: iteratorErr})
+		if err != iteratorErr {
+			t.Errorf("got:%v want:%v", err, iteratorErr)
+		}
+	})
+}
+
+func TestJSONStreamEncoderContext(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	buf := &FlushRecorder{}
+	err := encdec.JSONStreamEncoder{}.EncodeContext(ctx, buf, &CarIterator{cars: []Car{{"Fiat"}}})
+	if err != context.Canceled {
+		t.Errorf("got:%v want:%v", err, context.Canceled)
+	}
+	if buf.String() != "[" {
+		t.Errorf("got:%q want:%q", buf.String(), "[")
+	}
+}
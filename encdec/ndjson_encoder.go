@@ -0,0 +1,38 @@
+package encdec
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+)
+
+// NDJSONEncoder implements Encoder and ContextEncoder interfaces to encode the newline delimited JSON format (application/x-ndjson).
+// Each item of an Iterator, a channel, a slice or an array is written as a JSON value in its own line,
+// and the writer is flushed after each item, if it implements a Flush method like http.Flusher.
+// Any other value is written as a single line.
+type NDJSONEncoder struct{}
+
+// Encode writes to w the NDJSON encoding of v
+func (n NDJSONEncoder) Encode(w io.Writer, v interface{}) error {
+	return n.EncodeContext(context.Background(), w, v)
+}
+
+// EncodeContext is like Encode, but stops writing the items when ctx is done, returning the context error.
+func (n NDJSONEncoder) EncodeContext(ctx context.Context, w io.Writer, v interface{}) error {
+	encoder := json.NewEncoder(w)
+
+	ok, err := EachItem(ctx, v, func(item interface{}) error {
+		if err := encoder.Encode(item); err != nil {
+			return err
+		}
+
+		flush(w)
+
+		return nil
+	})
+	if ok {
+		return err
+	}
+
+	return encoder.Encode(v)
+}
@@ -0,0 +1,68 @@
+package encdec_test
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/ehsoc/rest/encdec"
+)
+
+func TestNDJSONEncoder(t *testing.T) {
+	want := "{\"Brand\":\"Fiat\"}\n{\"Brand\":\"Ford\"}\n"
+	tt := []struct {
+		name string
+		v    interface{}
+	}{
+		{"iterator", &CarIterator{cars: []Car{{"Fiat"}, {"Ford"}}}},
+		{"channel", carChannel(Car{"Fiat"}, Car{"Ford"})},
+		{"slice", []Car{{"Fiat"}, {"Ford"}}},
+	}
+	for _, test := range tt {
+		t.Run(test.name, func(t *testing.T) {
+			buf := &FlushRecorder{}
+			err := encdec.NDJSONEncoder{}.Encode(buf, test.v)
+			if err != nil {
+				t.Fatalf("not expecting error: %v", err)
+			}
+			if buf.String() != want {
+				t.Errorf("got:%q want:%q", buf.String(), want)
+			}
+			wantFlushes := []string{"{\"Brand\":\"Fiat\"}\n", want}
+			if !reflect.DeepEqual(buf.flushes, wantFlushes) {
+				t.Errorf("got:%q want:%q", buf.flushes, wantFlushes)
+			}
+		})
+	}
+	t.Run("single value", func(t *testing.T) {
+		buf := &FlushRecorder{}
+		encdec.NDJSONEncoder{}.Encode(buf, Car{"Fiat"})
+		if buf.String() != "{\"Brand\":\"Fiat\"}\n" {
+			t.Errorf("got:%q want:%q", buf.String(), "{\"Brand\":\"Fiat\"}\n")
+		}
+	})
+	t.Run("iterator is closed", func(t *testing.T) {
+		it := &CarIterator{cars: []Car{{"Fiat"}}}
+		encdec.NDJSONEncoder{}.Encode(&FlushRecorder{}, it)
+		if !it.closed {
+			t.Errorf("expecting closed iterator")
+		}
+	})
+}
+
+func TestNDJSONEncoderContext(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	ch := make(chan Car)
+	go func() {
+		ch <- Car{"Fiat"}
+		cancel()
+	}()
+	buf := &FlushRecorder{}
+	err := encdec.NDJSONEncoder{}.EncodeContext(ctx, buf, ch)
+	if err != context.Canceled {
+		t.Errorf("got:%v want:%v", err, context.Canceled)
+	}
+	if buf.String() != "{\"Brand\":\"Fiat\"}\n" {
+		t.Errorf("got:%q want:%q", buf.String(), "{\"Brand\":\"Fiat\"}\n")
+	}
+}
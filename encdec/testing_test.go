@@ -1,5 +1,55 @@
 package encdec_test
 
+import "bytes"
+
 type Car struct {
 	Brand string
 }
+
+// CarIterator is an encdec.Iterator of cars that counts the flushes of the writer between the items.
+type CarIterator struct {
+	cars    []Car
+	current int
+	closed  bool
+	err     error
+}
+
+func (c *CarIterator) Next() bool {
+	if c.err != nil || c.current >= len(c.cars) {
+		return false
+	}
+	c.current++
+	return true
+}
+
+func (c *CarIterator) Item() interface{} {
+	return c.cars[c.current-1]
+}
+
+func (c *CarIterator) Err() error {
+	return c.err
+}
+
+func (c *CarIterator) Close() error {
+	c.closed = true
+	return nil
+}
+
+// FlushRecorder is a bytes.Buffer that records the content of each flush.
+type FlushRecorder struct {
+	bytes.Buffer
+	flushes []string
+}
+
+func (f *FlushRecorder) Flush() {
+	f.flushes = append(f.flushes, f.String())
+}
+
+func carChannel(cars ...Car) <-chan Car {
+	ch := make(chan Car, len(cars))
+	for _, car := range cars {
+		ch <- car
+	}
+	close(ch)
+	return ch
+}
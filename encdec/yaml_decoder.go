@@ -0,0 +1,23 @@
+package encdec
+
+import (
+	"io"
+
+	"gopkg.in/yaml.v2"
+)
+
+// YAMLDecoder implements Decoder interface to decode YAML format
+type YAMLDecoder struct{}
+
+// Decode is a wrapper around gopkg.in/yaml.v2 YAML Decoder, that read from r and store it in v
+func (y YAMLDecoder) Decode(r io.Reader, v interface{}) error {
+	return yaml.NewDecoder(r).Decode(v)
+}
+
+// DecodeStrict is like Decode, but returns an error if the YAML mapping has a key that doesn't match any field of v,
+// or a duplicated key.
+func (y YAMLDecoder) DecodeStrict(r io.Reader, v interface{}) error {
+	decoder := yaml.NewDecoder(r)
+	decoder.SetStrict(true)
+	return decoder.Decode(v)
+}
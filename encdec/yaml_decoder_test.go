@@ -0,0 +1,45 @@
+package encdec_test
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+
+	"github.com/ehsoc/rest/encdec"
+	"gopkg.in/yaml.v2"
+)
+
+func TestYAMLDecoder(t *testing.T) {
+	car := Car{Brand: "Fiat"}
+	gotCar := Car{}
+	buf := bytes.NewBuffer([]byte(""))
+	yaml.NewEncoder(buf).Encode(car)
+
+	decoder := encdec.YAMLDecoder{}
+
+	decoder.Decode(buf, &gotCar)
+
+	if !reflect.DeepEqual(gotCar, car) {
+		t.Errorf("got:%v want:%v", gotCar, car)
+	}
+}
+
+func TestYAMLDecoderStrict(t *testing.T) {
+	var decoder encdec.StrictDecoder = encdec.YAMLEncoderDecoder{}
+	t.Run("known fields", func(t *testing.T) {
+		gotCar := Car{}
+		err := decoder.DecodeStrict(bytes.NewBufferString("brand: Fiat\n"), &gotCar)
+		if err != nil {
+			t.Fatalf("not expecting error: %v", err)
+		}
+		if gotCar.Brand != "Fiat" {
+			t.Errorf("got:%v want:%v", gotCar.Brand, "Fiat")
+		}
+	})
+	t.Run("unknown field", func(t *testing.T) {
+		err := decoder.DecodeStrict(bytes.NewBufferString("brand: Fiat\nwheels: 4\n"), &Car{})
+		if err == nil {
+			t.Errorf("expecting error")
+		}
+	})
+}
@@ -0,0 +1,20 @@
+package encdec
+
+import (
+	"io"
+
+	"gopkg.in/yaml.v2"
+)
+
+// YAMLEncoder implements Encoder interface to encode YAML format
+type YAMLEncoder struct{}
+
+// Encode writes to w the YAML encoding of v
+func (y YAMLEncoder) Encode(w io.Writer, v interface{}) error {
+	encoder := yaml.NewEncoder(w)
+	if err := encoder.Encode(v); err != nil {
+		return err
+	}
+
+	return encoder.Close()
+}
@@ -0,0 +1,8 @@
+package encdec
+
+// YAMLEncoderDecoder is a YAML EncoderDecoder implementation
+// composed by embedding YAMLEncoder and YAMLDecoder types
+type YAMLEncoderDecoder struct {
+	YAMLEncoder
+	YAMLDecoder
+}
@@ -0,0 +1,23 @@
+package encdec_test
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+
+	"github.com/ehsoc/rest/encdec"
+	"gopkg.in/yaml.v2"
+)
+
+func TestYAMLEncoder(t *testing.T) {
+	car := Car{Brand: "Fiat"}
+	gotCar := Car{}
+	encoder := encdec.YAMLEncoder{}
+	buf := bytes.NewBuffer([]byte(""))
+	encoder.Encode(buf, car)
+	yaml.NewDecoder(buf).Decode(&gotCar)
+
+	if !reflect.DeepEqual(gotCar, car) {
+		t.Errorf("got:%v want:%v", gotCar, car)
+	}
+}
@@ -0,0 +1,79 @@
+package rest
+
+import (
+	"errors"
+	"reflect"
+)
+
+// ErrorMatcher reports whether an error returned by an Operation is matched by an error mapping.
+type ErrorMatcher interface {
+	Match(err error) bool
+}
+
+// The ErrorMatcherFunc type is an adapter to allow the use of
+// ordinary functions as ErrorMatcher. If f is a function
+// with the appropriate signature, ErrorMatcherFunc(f) is a
+// ErrorMatcher that calls f.
+type ErrorMatcherFunc func(err error) bool
+
+// Match calls f(err)
+func (f ErrorMatcherFunc) Match(err error) bool {
+	return f(err)
+}
+
+// ErrorIs returns an ErrorMatcher that matches the errors that are equal to target, or wrap it, using errors.Is.
+func ErrorIs(target error) ErrorMatcher {
+	return ErrorMatcherFunc(func(err error) bool {
+		return errors.Is(err, target)
+	})
+}
+
+var errorType = reflect.TypeOf((*error)(nil)).Elem()
+
+// ErrorAs returns an ErrorMatcher that matches the errors that can be assigned to the type pointed by target, using errors.As.
+// target must be a non-nil pointer to a type that implements error, or to an interface type.
+// For an error type with pointer receiver methods, use a pointer to a pointer: ErrorAs(new(*MyError)).
+// A new target value is used for every match, so the matcher can be safely used by concurrent requests.
+func ErrorAs(target interface{}) ErrorMatcher {
+	t := reflect.TypeOf(target)
+	if t == nil || t.Kind() != reflect.Ptr {
+		panic("rest: ErrorAs target must be a non-nil pointer")
+	}
+
+	if t.Elem().Kind() != reflect.Interface && !t.Elem().Implements(errorType) {
+		panic("rest: ErrorAs target must be a pointer to an interface or to a type implementing error")
+	}
+
+	return ErrorMatcherFunc(func(err error) bool {
+		return errors.As(err, reflect.New(t.Elem()).Interface())
+	})
+}
+
+// errorMapping maps the errors matched by the matcher to a response.
+type errorMapping struct {
+	matcher  ErrorMatcher
+	response Response
+}
+
+// errorMappingCollection is a list of error mappings, where the last declared mapping takes precedence.
+type errorMappingCollection []errorMapping
+
+// response returns the response of the last declared mapping that matches the error.
+func (c errorMappingCollection) response(err error) (Response, bool) {
+	for i := len(c) - 1; i >= 0; i-- {
+		if c[i].matcher.Match(err) {
+			return c[i].response, true
+		}
+	}
+
+	return Response{}, false
+}
+
+// MapError maps the errors returned by the Operation that are matched by matcher to the given response,
+// instead of the default 500 (Internal Server Error) response.
+// The mappings are inherited by the child resources and methods declared after the call of this method.
+// The last declared mapping takes precedence, so the mappings of a method or a child resource
+// override the mappings of the parent resources.
+func (rs *ResourceCollection) MapError(matcher ErrorMatcher, response Response) {
+	rs.errorMappings = append(rs.errorMappings, errorMapping{matcher, response})
+}
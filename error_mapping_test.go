@@ -0,0 +1,125 @@
+package rest_test
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/ehsoc/rest"
+)
+
+var ErrNotFound = errors.New("not found")
+
+var ErrConflict = errors.New("conflict")
+
+type ErrorRateLimit struct {
+	RetryAfter int
+}
+
+func (e *ErrorRateLimit) Error() string {
+	return fmt.Sprintf("rate limit exceeded, retry after %d seconds", e.RetryAfter)
+}
+
+func errorOperation(err error) rest.MethodOperation {
+	return rest.NewMethodOperation(rest.OperationFunc(func(i rest.Input) (interface{}, bool, error) {
+		return nil, false, err
+	}), rest.NewResponse(200))
+}
+
+func TestErrorMatchers(t *testing.T) {
+	t.Run("is", func(t *testing.T) {
+		matcher := rest.ErrorIs(ErrNotFound)
+		assertTrue(t, matcher.Match(fmt.Errorf("car: %w", ErrNotFound)))
+		assertTrue(t, !matcher.Match(ErrConflict))
+	})
+	t.Run("as", func(t *testing.T) {
+		matcher := rest.ErrorAs(new(*ErrorRateLimit))
+		assertTrue(t, matcher.Match(fmt.Errorf("car: %w", &ErrorRateLimit{10})))
+		assertTrue(t, !matcher.Match(ErrConflict))
+	})
+	t.Run("as invalid target", func(t *testing.T) {
+		defer func() {
+			if r := recover(); r == nil {
+				t.Errorf("expecting panic")
+			}
+		}()
+		rest.ErrorAs(ErrorRateLimit{})
+	})
+}
+
+func TestMapError(t *testing.T) {
+	t.Run("method", func(t *testing.T) {
+		tt := []struct {
+			err      error
+			wantCode int
+		}{
+			{ErrNotFound, 404},
+			{fmt.Errorf("wrapped: %w", ErrConflict), 409},
+			{&ErrorRateLimit{10}, 429},
+			{errors.New("unknown"), 500},
+		}
+		for _, test := range tt {
+			t.Run(test.err.Error(), func(t *testing.T) {
+				m := rest.NewMethod("GET", errorOperation(test.err), mustGetJSONContentType()).
+					MapError(rest.ErrorIs(ErrNotFound), rest.NewResponse(404)).
+					MapError(rest.ErrorIs(ErrConflict), rest.NewResponse(409)).
+					MapError(rest.ErrorAs(new(*ErrorRateLimit)), rest.NewResponse(429))
+				req, _ := http.NewRequest("GET", "/", nil)
+				resp := httptest.NewRecorder()
+				m.ServeHTTP(resp, req)
+				assertResponseCode(t, resp, test.wantCode)
+			})
+		}
+	})
+	t.Run("inheritance", func(t *testing.T) {
+		methods := map[string]*rest.Method{}
+		api := rest.API{}
+		api.MapError(rest.ErrorIs(ErrNotFound), rest.NewResponse(404))
+		api.MapError(rest.ErrorIs(ErrConflict), rest.NewResponse(409))
+		api.Resource("car", func(r *rest.Resource) {
+			methods["car"] = r.Get(errorOperation(ErrNotFound), mustGetJSONContentType())
+			r.MapError(rest.ErrorIs(ErrNotFound), rest.NewResponse(410))
+			r.Resource("wheel", func(r *rest.Resource) {
+				methods["wheel"] = r.Get(errorOperation(ErrNotFound), mustGetJSONContentType())
+				methods["conflict"] = r.Post(errorOperation(ErrConflict), mustGetJSONContentType())
+				methods["override"] = r.Put(errorOperation(ErrNotFound), mustGetJSONContentType()).
+					MapError(rest.ErrorIs(ErrNotFound), rest.NewResponse(400))
+			})
+		})
+		tt := []struct {
+			name     string
+			wantCode int
+		}{
+			{"car", 404},
+			{"wheel", 410},
+			{"conflict", 409},
+			{"override", 400},
+		}
+		for _, test := range tt {
+			t.Run(test.name, func(t *testing.T) {
+				req, _ := http.NewRequest(methods[test.name].HTTPMethod, "/", nil)
+				resp := httptest.NewRecorder()
+				methods[test.name].ServeHTTP(resp, req)
+				assertResponseCode(t, resp, test.wantCode)
+			})
+		}
+	})
+	t.Run("responses", func(t *testing.T) {
+		m := rest.NewMethod("GET", errorOperation(nil), mustGetJSONContentType()).
+			MapError(rest.ErrorIs(ErrNotFound), rest.NewResponse(404).WithDescription("car not found")).
+			MapError(rest.ErrorIs(ErrConflict), rest.NewResponse(409)).
+			MapError(rest.ErrorAs(new(*ErrorRateLimit)), rest.NewResponse(404).WithDescription("override"))
+		descriptions := map[int]string{}
+		for _, resp := range m.Responses() {
+			descriptions[resp.Code()] = resp.Description()
+		}
+		if len(descriptions) != 3 {
+			t.Fatalf("expecting 3 responses, got: %v", descriptions)
+		}
+		assertStringEqual(t, descriptions[404], "override")
+		_, ok := descriptions[409]
+		assertTrue(t, ok)
+	})
+}
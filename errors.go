@@ -3,6 +3,8 @@ package rest
 import (
 	"errors"
 	"fmt"
+	"reflect"
+	"strings"
 )
 
 // ErrorNoDefaultContentTypeIsSet error when no default content-type is set
@@ -12,11 +14,32 @@ var ErrorNoDefaultContentTypeIsSet = errors.New("rest: no default content-type i
 // but it was not declared as parameter.
 var ErrorRequestBodyNotDefined = errors.New("rest: a request body was not defined")
 
+// ErrorPreconditionRequired error when the If-Match header is required, but the request doesn't have it.
+var ErrorPreconditionRequired = errors.New("rest: the If-Match header is required")
+
+// ErrorRequestBodyMissing error when the request body is required, but the request has no body.
+var ErrorRequestBodyMissing = errors.New("rest: the request body is required")
+
 var msgErrResourceCharNotAllowed = "rest: char not allowed on resource name '%s'"
 var msgErrParameterCharNotAllowed = "rest: char not allowed on parameter name '%s'"
 var msgErrParameterNotDefined = "rest: parameter '%s' not defined"
 var msgErrGetURIParamFunctionNotDefined = "rest: no get uri parameter function is defined in context value InputContextKey(\"uriparamfunc\") for '%v' parameter"
 var msgErrFailResponseNotDefined = "rest: resource '%s' failedResponse was not defined, but the operation was expecting one"
+var msgErrOutcomeNotDefined = "rest: resource '%s' has no response for the outcome key '%s' code %d, but the operation was expecting one"
+var msgErrParameterParse = "rest: parameter '%s' value '%s' is not a valid %s: %v"
+var msgErrParameterTypeMismatch = "rest: parameter '%s' is declared as %s and can not be converted to %s"
+var msgErrParameterRequired = "rest: required %s parameter '%s' is missing"
+var msgErrParameterEnum = "rest: parameter '%s' value '%s' is not one of the allowed values %v"
+var msgErrParameterConstraints = "rest: parameter constraints violated: %s"
+var msgErrRequestBodyDecode = "rest: request body can not be decoded: %v"
+var msgErrNotAcceptable = "rest: none of the available content types is acceptable for '%s'"
+var msgErrUnsupportedContentEncoding = "rest: the request content coding '%s' is not available"
+var msgErrDecodedBodyTooLarge = "rest: the decoded request body exceeds the maximum size of %d bytes"
+var msgErrPreconditionFailed = "rest: the If-Match header '%s' doesn't match the current entity tag '%s'"
+var msgErrRequestBodyRequiredFields = "rest: request body required fields are missing: %s"
+var msgErrInvalidJWT = "rest: invalid JWT: %s"
+var msgErrScopesNotGranted = "rest: the required scopes are not granted: %s"
+var msgErrInvalidJWK = "rest: the JWK '%s' of the key set is malformed"
 
 // ErrorResourceCharNotAllowed error when a forbidden character is included in the `name` parameter of a `Resource`.
 type ErrorResourceCharNotAllowed struct {
@@ -67,6 +90,176 @@ func (e *ErrorFailResponseNotDefined) Error() string {
 	return fmt.Sprintf(msgErrFailResponseNotDefined, e.Name)
 }
 
+// ErrorOutcomeNotDefined will be trigger when the Execute method returns an Outcome,
+// but there is no response declared for the Outcome key or code.
+type ErrorOutcomeNotDefined struct {
+	Name string
+	Key  string
+	Code int
+}
+
+func (e *ErrorOutcomeNotDefined) Error() string {
+	return fmt.Sprintf(msgErrOutcomeNotDefined, e.Name, e.Key, e.Code)
+}
+
+// ErrorParameterParse describes a parameter value that can not be converted to the parameter declared Type.
+// If an Operation returns this error, the handler will respond with a 400 (Bad Request) code.
+type ErrorParameterParse struct {
+	Name  string
+	Value string
+	Kind  reflect.Kind
+	Err   error
+}
+
+func (e *ErrorParameterParse) Error() string {
+	return fmt.Sprintf(msgErrParameterParse, e.Name, e.Value, e.Kind, e.Err)
+}
+
+// Unwrap returns the underlying conversion error.
+func (e *ErrorParameterParse) Unwrap() error {
+	return e.Err
+}
+
+// ErrorParameterTypeMismatch describes a specification/parameter check error trying to get a parameter
+// as a type that is not compatible with the declared Type of the parameter.
+type ErrorParameterTypeMismatch struct {
+	Name   string
+	Kind   reflect.Kind
+	Target string
+}
+
+func (e *ErrorParameterTypeMismatch) Error() string {
+	return fmt.Sprintf(msgErrParameterTypeMismatch, e.Name, e.Kind, e.Target)
+}
+
+// ErrorParameterRequired describes a required parameter that is missing in the request.
+type ErrorParameterRequired struct {
+	Name     string
+	HTTPType ParameterType
+}
+
+func (e *ErrorParameterRequired) Error() string {
+	return fmt.Sprintf(msgErrParameterRequired, e.HTTPType, e.Name)
+}
+
+// ErrorParameterEnum describes a parameter value that is not one of the declared EnumValues.
+type ErrorParameterEnum struct {
+	Name       string
+	Value      string
+	EnumValues []interface{}
+}
+
+func (e *ErrorParameterEnum) Error() string {
+	return fmt.Sprintf(msgErrParameterEnum, e.Name, e.Value, e.EnumValues)
+}
+
+// ErrorParameterConstraints describes all the declared parameter constraints that a request doesn't satisfy.
+type ErrorParameterConstraints struct {
+	Violations []ParameterViolation
+}
+
+func (e *ErrorParameterConstraints) Error() string {
+	messages := make([]string, 0, len(e.Violations))
+	for _, v := range e.Violations {
+		messages = append(messages, v.Message)
+	}
+
+	return fmt.Sprintf(msgErrParameterConstraints, strings.Join(messages, "; "))
+}
+
+// ErrorRequestBodyDecode describes a request body that can not be decoded into the declared RequestBody type.
+// If an Operation returns this error, the handler will respond with a 400 (Bad Request) code.
+type ErrorRequestBodyDecode struct {
+	Err error
+}
+
+func (e *ErrorRequestBodyDecode) Error() string {
+	return fmt.Sprintf(msgErrRequestBodyDecode, e.Err)
+}
+
+// Unwrap returns the underlying decoder error.
+func (e *ErrorRequestBodyDecode) Unwrap() error {
+	return e.Err
+}
+
+// ErrorRequestBodyRequiredFields describes the required fields missing in a request body.
+// If an Operation returns this error, the handler will respond with a 400 (Bad Request) code.
+type ErrorRequestBodyRequiredFields struct {
+	Fields []string
+}
+
+func (e *ErrorRequestBodyRequiredFields) Error() string {
+	return fmt.Sprintf(msgErrRequestBodyRequiredFields, strings.Join(e.Fields, ", "))
+}
+
+// ErrorNotAcceptable describes an Accept header that doesn't accept any of the available content types,
+// or that can not be parsed, in that case Err is the parse error.
+type ErrorNotAcceptable struct {
+	Accept string
+	Err    error
+}
+
+func (e *ErrorNotAcceptable) Error() string {
+	if e.Err != nil {
+		return fmt.Sprintf(msgErrNotAcceptable+": %v", e.Accept, e.Err)
+	}
+
+	return fmt.Sprintf(msgErrNotAcceptable, e.Accept)
+}
+
+// Unwrap returns the Accept header parse error.
+func (e *ErrorNotAcceptable) Unwrap() error {
+	return e.Err
+}
+
+// ErrorUnsupportedContentEncoding describes a request body with a Content-Encoding that is not available.
+type ErrorUnsupportedContentEncoding struct {
+	ContentEncoding string
+}
+
+func (e *ErrorUnsupportedContentEncoding) Error() string {
+	return fmt.Sprintf(msgErrUnsupportedContentEncoding, e.ContentEncoding)
+}
+
+// ErrorDecodedBodyTooLarge describes a request body with a Content-Encoding that exceeds the MaxDecodedSize of the ContentEncodings once decoded.
+// If an Operation returns this error, the handler will respond with the DecodedBodyTooLargeResponse, a 413 (Request Entity Too Large) code.
+type ErrorDecodedBodyTooLarge struct {
+	MaxSize int64
+}
+
+func (e *ErrorDecodedBodyTooLarge) Error() string {
+	return fmt.Sprintf(msgErrDecodedBodyTooLarge, e.MaxSize)
+}
+
+// ErrorPreconditionFailed describes an If-Match header that doesn't match the current entity tag of the resource.
+// If an Operation of a method with WithIfMatch returns this error, the handler will respond with a 412 (Precondition Failed) code.
+type ErrorPreconditionFailed struct {
+	IfMatch string
+	ETag    string
+}
+
+func (e *ErrorPreconditionFailed) Error() string {
+	return fmt.Sprintf(msgErrPreconditionFailed, e.IfMatch, e.ETag)
+}
+
+// ErrorInvalidJWT describes a bearer token that is missing, malformed, has an invalid signature, or invalid claims.
+type ErrorInvalidJWT struct {
+	Reason string
+}
+
+func (e *ErrorInvalidJWT) Error() string {
+	return fmt.Sprintf(msgErrInvalidJWT, e.Reason)
+}
+
+// ErrorInvalidJWK describes a malformed key of a JSON Web Key Set.
+type ErrorInvalidJWK struct {
+	KeyID string
+}
+
+func (e *ErrorInvalidJWK) Error() string {
+	return fmt.Sprintf(msgErrInvalidJWK, e.KeyID)
+}
+
 // AuthError describes an authentication/authorization error.
 // Use the following implementations:
 // For an authentication failure use the TypeErrorAuthentication error.
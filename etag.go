@@ -0,0 +1,214 @@
+package rest
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"strings"
+
+	"github.com/ehsoc/rest/encdec"
+)
+
+// ETagFunc returns the current entity tag of the resource, like EntityTag of the stored entity or a quoted version number,
+// or an empty string if the resource doesn't exist.
+type ETagFunc func(i Input) (string, error)
+
+// etagOptions are the options of the ETag generation.
+type etagOptions struct {
+	weak bool
+}
+
+// ifMatchOptions are the options of the If-Match precondition.
+type ifMatchOptions struct {
+	current                      ETagFunc
+	preconditionFailedResponse   Response
+	preconditionRequiredResponse Response
+}
+
+// EntityTag returns an entity tag with the hash of the JSON encoding of v, a weak one if weak is true.
+// It can be used by an ETagFunc, or by an Operation to set the ETag response header, to get the same entity tag
+// for the same value whatever the negotiated content type is.
+func EntityTag(v interface{}, weak bool) (string, error) {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return "", err
+	}
+
+	return newETag(b, weak), nil
+}
+
+func newETag(b []byte, weak bool) string {
+	sum := sha256.Sum256(b)
+	etag := `"` + hex.EncodeToString(sum[:16]) + `"`
+
+	if weak {
+		return "W/" + etag
+	}
+
+	return etag
+}
+
+// WithETag enables the ETag header of the successful GET and HEAD responses, with the hash of the encoded response body,
+// a weak entity tag if weak is true. If the Operation sets the ETag response header with Input.ResponseHeader, that one is used.
+// A request with an If-None-Match header matching the entity tag gets a 304 (Not Modified) response without a body.
+// The hashed entity tag is weakened if the response body is compressed, the entity tag of the Operation is not.
+func (m *Method) WithETag(weak bool) *Method {
+	m.etag = &etagOptions{weak}
+	return m
+}
+
+// WithIfMatch requires the If-Match header with the current entity tag of the resource, returned by current,
+// before running the Operation, for the optimistic concurrency control of PUT, PATCH and DELETE methods.
+// A request without the If-Match header gets a 428 (Precondition Required) response,
+// and a request with an If-Match header that doesn't match the current entity tag gets a 412 (Precondition Failed) response.
+// The check is advisory, as the resource can change between the check and the Operation: to be atomic,
+// the store must compare the If-Match header with ETagMatches when it updates the resource,
+// and the Operation can return an ErrorPreconditionFailed error to get the 412 (Precondition Failed) response.
+func (m *Method) WithIfMatch(current ETagFunc) *Method {
+	m.ifMatch = &ifMatchOptions{
+		current:                      current,
+		preconditionFailedResponse:   NewResponse(http.StatusPreconditionFailed).WithDescription("The entity tag of the If-Match header doesn't match"),
+		preconditionRequiredResponse: NewResponse(http.StatusPreconditionRequired).WithDescription("The If-Match header is required"),
+	}
+
+	return m
+}
+
+// checkIfMatch evaluates the If-Match precondition, and writes the response and returns false if it is not satisfied.
+// An error of the ETagFunc is written like an error of the Operation.
+func (m *Method) checkIfMatch(w http.ResponseWriter, r *http.Request, input Input) bool {
+	ifMatch := input.Request.Header.Get("If-Match")
+	if strings.TrimSpace(ifMatch) == "" {
+		writeResponse(w, r, m.problemResponse(m.ifMatch.preconditionRequiredResponse).render(nil, false, ErrorPreconditionRequired))
+		return false
+	}
+
+	current, err := m.ifMatch.current(input)
+	if err != nil {
+		m.writeOperationError(w, r, nil, false, err)
+		return false
+	}
+
+	if !matchesETag(ifMatch, current, false) {
+		err := &ErrorPreconditionFailed{ifMatch, current}
+		writeResponse(w, r, m.problemResponse(m.ifMatch.preconditionFailedResponse).render(nil, false, err))
+		return false
+	}
+
+	return true
+}
+
+// ETagMatches reports whether the etag matches any of the entity tags of the If-Match header value,
+// using the strong comparison function (RFC 7232 section 2.3.2).
+func ETagMatches(ifMatch, etag string) bool {
+	return matchesETag(ifMatch, etag, false)
+}
+
+// matchesETag reports whether the etag matches any of the entity tags of the If-Match or If-None-Match header value,
+// using the weak comparison function if weak is true (RFC 7232 section 2.3.2).
+func matchesETag(header, etag string, weak bool) bool {
+	if etag == "" {
+		return false
+	}
+
+	for _, tag := range strings.Split(header, ",") {
+		tag = strings.TrimSpace(tag)
+
+		if tag == "*" {
+			return true
+		}
+
+		if weak {
+			if strings.TrimPrefix(tag, "W/") == strings.TrimPrefix(etag, "W/") {
+				return true
+			}
+
+			continue
+		}
+
+		if tag == etag && !strings.HasPrefix(etag, "W/") {
+			return true
+		}
+	}
+
+	return false
+}
+
+// writeOperationResponse writes a successful response of the Operation with the Cache-Control and ETag headers if they are enabled,
+// or a 304 (Not Modified) response if the preconditions of a GET or HEAD request are not satisfied.
+func (m *Method) writeOperationResponse(w http.ResponseWriter, r *http.Request, resp Response) {
+	if resp.Code() < 200 || resp.Code() > 299 {
+		writeResponse(w, r, resp)
+		return
+	}
+
+	m.setCacheControl(w.Header())
+
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		writeResponse(w, r, resp)
+		return
+	}
+
+	var buf *bytes.Buffer
+
+	encoder, ok := r.Context().Value(EncoderDecoderContextKey("encoder")).(encdec.Encoder)
+
+	// the ETag of a stream can not be computed without buffering the whole stream
+	if m.etag != nil && w.Header().Get("ETag") == "" && ok && resp.Body() != nil && !encdec.IsStream(resp.Body()) {
+		buf = new(bytes.Buffer)
+		if err := encoder.Encode(buf, resp.Body()); err != nil {
+			writeResponse(w, r, m.problemResponse(NewResponse(http.StatusInternalServerError)).render(nil, false, err))
+			return
+		}
+
+		etag := newETag(buf.Bytes(), m.etag.weak)
+		w.Header().Set("ETag", etag)
+
+		if ew, ok := r.Context().Value(InputContextKey("encodingwriter")).(*encodingResponseWriter); ok {
+			ew.bodyETag = etag
+		}
+	}
+
+	if notModified(r, w.Header()) {
+		w.Header().Del("Content-Type")
+		w.WriteHeader(http.StatusNotModified)
+
+		return
+	}
+
+	if buf == nil {
+		writeResponse(w, r, resp)
+		return
+	}
+
+	w.WriteHeader(resp.Code())
+	w.Write(buf.Bytes())
+}
+
+// etagResponses returns the responses with the ETag header, and the 304, 412 and 428 responses of the enabled preconditions.
+func (m *Method) etagResponses(responses []Response) []Response {
+	if m.etag != nil {
+		for i := range responses {
+			if responses[i].code >= 200 && responses[i].code <= 299 {
+				responses[i] = responses[i].WithHeader("ETag", ResponseHeader{Description: "The entity tag of the response body", Type: reflect.String})
+			}
+		}
+
+		if !hasResponseCode(responses, http.StatusNotModified) {
+			responses = append(responses, NewResponse(http.StatusNotModified).WithDescription("The entity tag of the If-None-Match header matches"))
+		}
+	}
+
+	if m.ifMatch != nil {
+		for _, resp := range []Response{m.ifMatch.preconditionFailedResponse, m.ifMatch.preconditionRequiredResponse} {
+			if !hasResponseCode(responses, resp.code) {
+				responses = append(responses, resp)
+			}
+		}
+	}
+
+	return responses
+}
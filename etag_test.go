@@ -0,0 +1,293 @@
+package rest_test
+
+import (
+	"bytes"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/ehsoc/rest"
+)
+
+func etagMethod(httpMethod string, operationETag string) *rest.Method {
+	operation := rest.OperationFunc(func(i rest.Input) (interface{}, bool, error) {
+		if operationETag != "" {
+			i.ResponseHeader().Set("ETag", operationETag)
+		}
+		return Car{ID: 1, Brand: "Fiat"}, true, nil
+	})
+	mo := rest.NewMethodOperation(operation, rest.NewResponse(200).WithOperationResultBody(Car{}))
+	return rest.NewMethod(httpMethod, mo, mustGetJSONContentType())
+}
+
+func serveETag(m *rest.Method, httpMethod string, headers map[string]string) *httptest.ResponseRecorder {
+	req, _ := http.NewRequest(httpMethod, "/", nil)
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+	resp := httptest.NewRecorder()
+	m.ServeHTTP(resp, req)
+	return resp
+}
+
+func TestWithETag(t *testing.T) {
+	t.Run("strong", func(t *testing.T) {
+		m := etagMethod("GET", "").WithETag(false)
+		resp := serveETag(m, "GET", nil)
+		assertResponseCode(t, resp, 200)
+		etag := resp.Header().Get("ETag")
+		assertTrue(t, strings.HasPrefix(etag, `"`) && strings.HasSuffix(etag, `"`))
+		assertStringEqual(t, resp.Body.String(), "{\"id\":1,\"brand\":\"Fiat\",\"colors\":null}\n")
+
+		resp = serveETag(m, "GET", map[string]string{"If-None-Match": `"other", ` + etag})
+		assertResponseCode(t, resp, 304)
+		assertStringEqual(t, resp.Body.String(), "")
+		assertStringEqual(t, resp.Header().Get("ETag"), etag)
+
+		resp = serveETag(m, "GET", map[string]string{"If-None-Match": "W/" + etag})
+		assertResponseCode(t, resp, 304)
+
+		resp = serveETag(m, "GET", map[string]string{"If-None-Match": `"other"`})
+		assertResponseCode(t, resp, 200)
+		assertStringEqual(t, resp.Header().Get("ETag"), etag)
+	})
+	t.Run("weak", func(t *testing.T) {
+		m := etagMethod("GET", "").WithETag(true)
+		resp := serveETag(m, "GET", nil)
+		etag := resp.Header().Get("ETag")
+		assertTrue(t, strings.HasPrefix(etag, `W/"`))
+		resp = serveETag(m, "GET", map[string]string{"If-None-Match": strings.TrimPrefix(etag, "W/")})
+		assertResponseCode(t, resp, 304)
+	})
+	t.Run("operation entity tag", func(t *testing.T) {
+		m := etagMethod("GET", `"v2"`).WithETag(false)
+		resp := serveETag(m, "GET", nil)
+		assertStringEqual(t, resp.Header().Get("ETag"), `"v2"`)
+		resp = serveETag(m, "GET", map[string]string{"If-None-Match": `"v2"`})
+		assertResponseCode(t, resp, 304)
+	})
+	t.Run("head", func(t *testing.T) {
+		m := etagMethod("GET", "").WithETag(false)
+		etag := serveETag(m, "GET", nil).Header().Get("ETag")
+		resp := serveETag(m, "HEAD", map[string]string{"If-None-Match": etag})
+		assertResponseCode(t, resp, 304)
+	})
+	t.Run("disabled", func(t *testing.T) {
+		resp := serveETag(etagMethod("GET", ""), "GET", nil)
+		assertStringEqual(t, resp.Header().Get("ETag"), "")
+	})
+	t.Run("not a GET method", func(t *testing.T) {
+		resp := serveETag(etagMethod("POST", "").WithETag(false), "POST", nil)
+		assertStringEqual(t, resp.Header().Get("ETag"), "")
+	})
+	t.Run("compressed response", func(t *testing.T) {
+		ce := rest.NewContentEncodings()
+		ce.MinSize = 0
+		m := etagMethod("GET", "").WithETag(false).WithContentEncodings(ce)
+		etag := serveETag(m, "GET", nil).Header().Get("ETag")
+		resp := serveETag(m, "GET", map[string]string{"Accept-Encoding": "gzip"})
+		assertStringEqual(t, resp.Header().Get("Content-Encoding"), "gzip")
+		assertStringEqual(t, resp.Header().Get("ETag"), "W/"+etag)
+	})
+	t.Run("compressed response with operation entity tag", func(t *testing.T) {
+		ce := rest.NewContentEncodings()
+		ce.MinSize = 0
+		m := etagMethod("GET", `"v2"`).WithETag(false).WithContentEncodings(ce)
+		resp := serveETag(m, "GET", map[string]string{"Accept-Encoding": "gzip"})
+		assertStringEqual(t, resp.Header().Get("Content-Encoding"), "gzip")
+		assertStringEqual(t, resp.Header().Get("ETag"), `"v2"`)
+	})
+	t.Run("responses", func(t *testing.T) {
+		responses := etagMethod("GET", "").WithETag(false).Responses()
+		assertTrue(t, hasCode(responses, 304))
+		for _, resp := range responses {
+			if resp.Code() == 200 {
+				if _, ok := resp.Headers()["Etag"]; !ok {
+					t.Errorf("expecting ETag header in the 200 response")
+				}
+			}
+		}
+		assertFalse(t, hasCode(etagMethod("GET", "").Responses(), 304))
+	})
+}
+
+func TestWithIfMatch(t *testing.T) {
+	current := `"v1"`
+	var operationCalled bool
+	operation := rest.OperationFunc(func(i rest.Input) (interface{}, bool, error) {
+		operationCalled = true
+		return nil, true, nil
+	})
+	m := rest.NewMethod("PUT", rest.NewMethodOperation(operation, rest.NewResponse(200)), mustGetJSONContentType()).
+		WithIfMatch(func(i rest.Input) (string, error) {
+			return current, nil
+		})
+	tt := []struct {
+		name     string
+		current  string
+		ifMatch  string
+		wantCode int
+	}{
+		{"match", `"v1"`, `"v1"`, 200},
+		{"match in list", `"v1"`, `"v0", "v1"`, 200},
+		{"any", `"v1"`, "*", 200},
+		{"missing", `"v1"`, "", 428},
+		{"mismatch", `"v1"`, `"v0"`, 412},
+		{"weak entity tag", `"v1"`, `W/"v1"`, 412},
+		{"weak current entity tag", `W/"v1"`, `W/"v1"`, 412},
+		{"no current entity", "", "*", 412},
+	}
+	for _, test := range tt {
+		t.Run(test.name, func(t *testing.T) {
+			current = test.current
+			operationCalled = false
+			req, _ := http.NewRequest("PUT", "/", bytes.NewBufferString("{}"))
+			req.Header.Set("Content-Type", "application/json")
+			if test.ifMatch != "" {
+				req.Header.Set("If-Match", test.ifMatch)
+			}
+			resp := httptest.NewRecorder()
+			m.ServeHTTP(resp, req)
+			assertResponseCode(t, resp, test.wantCode)
+			assertTrue(t, operationCalled == (test.wantCode == 200))
+		})
+	}
+	t.Run("responses", func(t *testing.T) {
+		assertTrue(t, hasCode(m.Responses(), 412))
+		assertTrue(t, hasCode(m.Responses(), 428))
+	})
+}
+
+func TestEntityTag(t *testing.T) {
+	etag, err := rest.EntityTag(Car{ID: 1}, false)
+	assertNoErrorFatal(t, err)
+	other, _ := rest.EntityTag(Car{ID: 2}, false)
+	same, _ := rest.EntityTag(Car{ID: 1}, false)
+	weak, _ := rest.EntityTag(Car{ID: 1}, true)
+	assertStringEqual(t, same, etag)
+	assertTrue(t, other != etag)
+	assertStringEqual(t, weak, "W/"+etag)
+}
+
+func TestIfMatchCompressedRoundTrip(t *testing.T) {
+	car := Car{ID: 1, Brand: "Fiat"}
+	current := func(i rest.Input) (string, error) {
+		return rest.EntityTag(car, false)
+	}
+	get := rest.OperationFunc(func(i rest.Input) (interface{}, bool, error) {
+		etag, err := current(i)
+		i.ResponseHeader().Set("ETag", etag)
+		return car, true, err
+	})
+	put := rest.OperationFunc(func(i rest.Input) (interface{}, bool, error) {
+		car.Brand = "Ford"
+		return nil, true, nil
+	})
+	ce := rest.NewContentEncodings()
+	ce.MinSize = 0
+	getMethod := rest.NewMethod("GET", rest.NewMethodOperation(get, rest.NewResponse(200).WithOperationResultBody(Car{})), mustGetJSONContentType()).
+		WithETag(false).
+		WithContentEncodings(ce)
+	putMethod := rest.NewMethod("PUT", rest.NewMethodOperation(put, rest.NewResponse(200)), mustGetJSONContentType()).
+		WithIfMatch(current).
+		WithContentEncodings(ce)
+	resp := serveETag(getMethod, "GET", map[string]string{"Accept-Encoding": "gzip"})
+	assertStringEqual(t, resp.Header().Get("Content-Encoding"), "gzip")
+	etag := resp.Header().Get("ETag")
+	resp = serveETag(putMethod, "PUT", map[string]string{"Accept-Encoding": "gzip", "If-Match": etag})
+	assertResponseCode(t, resp, 200)
+	resp = serveETag(putMethod, "PUT", map[string]string{"Accept-Encoding": "gzip", "If-Match": etag})
+	assertResponseCode(t, resp, 412)
+}
+
+func TestIfMatchETagFuncError(t *testing.T) {
+	errNotFound := errors.New("not found")
+	current := func(i rest.Input) (string, error) {
+		if i.Request.URL.Query().Get("missing") == "true" {
+			return "", errNotFound
+		}
+		if i.Request.URL.Query().Get("version") == "x" {
+			return "", &rest.ErrorParameterParse{Name: "version", Value: "x", Kind: reflect.Int64, Err: errors.New("invalid syntax")}
+		}
+		if i.Request.URL.Query().Get("fail") == "true" {
+			return "", errors.New("store unavailable")
+		}
+		body, err := i.DecodeBody()
+		if err != nil {
+			return "", err
+		}
+		return rest.EntityTag(body, false)
+	}
+	operation := rest.OperationFunc(func(i rest.Input) (interface{}, bool, error) {
+		return nil, true, nil
+	})
+	m := rest.NewMethod("PUT", rest.NewMethodOperation(operation, rest.NewResponse(200)), mustGetJSONContentType()).
+		WithRequestBody("car", Car{}).
+		WithIfMatch(current).
+		MapError(rest.ErrorIs(errNotFound), rest.NewResponse(404))
+	tt := []struct {
+		name     string
+		query    string
+		body     string
+		wantCode int
+	}{
+		{"malformed body", "", "{", 400},
+		{"mapped error", "?missing=true", "{}", 404},
+		{"parameter parse error", "?version=x", "{}", 400},
+		{"unknown error", "?fail=true", "{}", 500},
+	}
+	for _, test := range tt {
+		t.Run(test.name, func(t *testing.T) {
+			req, _ := http.NewRequest("PUT", "/"+test.query, strings.NewReader(test.body))
+			req.Header.Set("Content-Type", "application/json")
+			req.Header.Set("If-Match", `"v1"`)
+			resp := httptest.NewRecorder()
+			m.ServeHTTP(resp, req)
+			assertResponseCode(t, resp, test.wantCode)
+		})
+	}
+}
+
+func TestIfMatchConcurrentUpdate(t *testing.T) {
+	var mutex sync.Mutex
+	version := `"v1"`
+	// both requests pass the advisory precondition before any of them updates the resource
+	var checked sync.WaitGroup
+	checked.Add(2)
+	current := func(i rest.Input) (string, error) {
+		mutex.Lock()
+		etag := version
+		mutex.Unlock()
+		checked.Done()
+		checked.Wait()
+		return etag, nil
+	}
+	operation := rest.OperationFunc(func(i rest.Input) (interface{}, bool, error) {
+		mutex.Lock()
+		defer mutex.Unlock()
+		ifMatch := i.Request.Header.Get("If-Match")
+		if !rest.ETagMatches(ifMatch, version) {
+			return nil, false, &rest.ErrorPreconditionFailed{IfMatch: ifMatch, ETag: version}
+		}
+		version = `"v2"`
+		return nil, true, nil
+	})
+	m := rest.NewMethod("PUT", rest.NewMethodOperation(operation, rest.NewResponse(200)), mustGetJSONContentType()).
+		WithIfMatch(current)
+	codes := make(chan int, 2)
+	for i := 0; i < 2; i++ {
+		go func() {
+			codes <- serveETag(m, "PUT", map[string]string{"If-Match": `"v1"`}).Code
+		}()
+	}
+	got := []int{<-codes, <-codes}
+	sort.Ints(got)
+	if got[0] != 200 || got[1] != 412 {
+		t.Errorf("got: %v want: [200 412]", got)
+	}
+}
@@ -13,14 +13,21 @@ type ChiGenerator struct {
 
 func (c ChiGenerator) GenerateServer(api rest.API) http.Handler {
 	router := chi.NewMux()
-	if strings.TrimSpace(api.BasePath) != "" {
-		router.Route(api.BasePath, func(r chi.Router) {
-			for _, resource := range api.Resources() {
-				processResource(r, resource)
-			}
-		})
+	basePath := strings.TrimSuffix(strings.TrimSpace(api.BasePath), "/")
+	if basePath == "" {
+		for _, resource := range api.Resources() {
+			processResource(router, resource)
+		}
+
+		return router
 	}
 
+	router.Route(basePath, func(r chi.Router) {
+		for _, resource := range api.Resources() {
+			processResource(r, resource)
+		}
+	})
+
 	return router
 }
 
@@ -33,6 +40,9 @@ func processResource(r chi.Router, res rest.Resource) {
 		for _, method := range res.Methods() {
 			r.Method(method.HTTPMethod, "/", method)
 		}
+		for _, httpMethod := range res.NotAllowedMethods() {
+			r.Method(httpMethod, "/", res.MethodNotAllowedHandler())
+		}
 		for _, subRes := range res.Resources() {
 			processResource(r, subRes)
 		}
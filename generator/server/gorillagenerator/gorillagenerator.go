@@ -0,0 +1,54 @@
+// Package gorillagenerator implements a rest.ServerGenerator using the gorilla/mux router.
+package gorillagenerator
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/ehsoc/rest"
+	"github.com/gorilla/mux"
+)
+
+// GorillaGenerator is a rest.ServerGenerator implementation that registers a route for every method of the resources,
+// with the full path as the route template, so the route patterns are available with mux.CurrentRoute.
+type GorillaGenerator struct {
+}
+
+// GenerateServer returns a *mux.Router with the routes of the api resources.
+func (g GorillaGenerator) GenerateServer(api rest.API) http.Handler {
+	router := mux.NewRouter()
+	basePath := strings.TrimSuffix(strings.TrimSpace(api.BasePath), "/")
+
+	for _, resource := range api.Resources() {
+		processResource(router, basePath, resource)
+	}
+
+	return router
+}
+
+// GetURIParam returns the function to get the URI parameter values from the mux route variables.
+func (g GorillaGenerator) GetURIParam() func(*http.Request, string) string {
+	return func(r *http.Request, key string) string {
+		return mux.Vars(r)[key]
+	}
+}
+
+func processResource(router *mux.Router, parentPath string, res rest.Resource) {
+	// URI parameter resources paths ({name}) are valid mux route variables
+	path := parentPath + "/" + res.Path()
+
+	for _, method := range res.Methods() {
+		router.Handle(path, method).Methods(method.HTTPMethod)
+		// the trailing slash path is served by the same method, instead of a redirection
+		router.Handle(path+"/", method).Methods(method.HTTPMethod)
+	}
+
+	for _, httpMethod := range res.NotAllowedMethods() {
+		router.Handle(path, res.MethodNotAllowedHandler()).Methods(httpMethod)
+		router.Handle(path+"/", res.MethodNotAllowedHandler()).Methods(httpMethod)
+	}
+
+	for _, subRes := range res.Resources() {
+		processResource(router, path, subRes)
+	}
+}
@@ -0,0 +1,42 @@
+package gorillagenerator_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/ehsoc/rest"
+	"github.com/ehsoc/rest/encdec"
+	"github.com/ehsoc/rest/generator/server/gorillagenerator"
+	"github.com/ehsoc/rest/generator/server/servertest"
+	"github.com/gorilla/mux"
+)
+
+func TestGorillaGenerator(t *testing.T) {
+	servertest.Run(t, gorillagenerator.GorillaGenerator{})
+}
+
+func TestRouteTemplate(t *testing.T) {
+	template := ""
+	operation := rest.OperationFunc(func(i rest.Input) (interface{}, bool, error) {
+		template, _ = mux.CurrentRoute(i.Request).GetPathTemplate()
+		return nil, true, nil
+	})
+	ct := rest.NewContentTypes()
+	ct.Add("application/json", encdec.JSONEncoderDecoder{}, true)
+	api := rest.API{}
+	api.BasePath = "/v2"
+	api.Resource("pet", func(r *rest.Resource) {
+		r.ResourceP(rest.NewURIParameter("petId", reflect.String), func(r *rest.Resource) {
+			r.Get(rest.NewMethodOperation(operation, rest.NewResponse(200)), ct)
+		})
+	})
+	server := api.GenerateServer(gorillagenerator.GorillaGenerator{})
+	request, _ := http.NewRequest(http.MethodGet, "/v2/pet/1", nil)
+	server.ServeHTTP(httptest.NewRecorder(), request)
+	want := "/v2/pet/{petId}"
+	if template != want {
+		t.Errorf("got: %v want: %v", template, want)
+	}
+}
@@ -0,0 +1,232 @@
+// Package httproutergenerator implements a rest.ServerGenerator using the julienschmidt/httprouter router.
+package httproutergenerator
+
+import (
+	"context"
+	"net/http"
+	"strings"
+
+	"github.com/ehsoc/rest"
+	"github.com/julienschmidt/httprouter"
+)
+
+// HTTPRouterGenerator is a rest.ServerGenerator implementation that registers a route for every method of the resources.
+// httprouter doesn't allow a URI parameter resource to have sibling resources, so the child resources
+// of a resource with both URI parameter and static child resources are served by a catch-all route,
+// matching the static resources first.
+type HTTPRouterGenerator struct {
+}
+
+// subtreeParam is the name of the catch-all parameter of the routes serving a subtree of resources.
+const subtreeParam = "subtree"
+
+// GenerateServer returns a *httprouter.Router with the routes of the api resources.
+func (g HTTPRouterGenerator) GenerateServer(api rest.API) http.Handler {
+	router := httprouter.New()
+	basePath := strings.TrimSuffix(strings.TrimSpace(api.BasePath), "/")
+	resources := api.Resources()
+
+	if hasSiblingsConflict(resources) {
+		handleSubtree(router, basePath, &route{children: newRoutes(resources)})
+		return router
+	}
+
+	for _, resource := range resources {
+		processResource(router, basePath, resource)
+	}
+
+	return router
+}
+
+// GetURIParam returns the function to get the URI parameter values from the httprouter params stored in the request context.
+func (g HTTPRouterGenerator) GetURIParam() func(*http.Request, string) string {
+	return func(r *http.Request, key string) string {
+		return httprouter.ParamsFromContext(r.Context()).ByName(key)
+	}
+}
+
+func processResource(router *httprouter.Router, parentPath string, res rest.Resource) {
+	path := parentPath + "/" + routeSegment(res.Path())
+	resources := res.Resources()
+	// the trailing slash path conflicts with the catch-all route, which serves it instead
+	subtree := hasSiblingsConflict(resources)
+
+	for _, method := range res.Methods() {
+		router.Handler(method.HTTPMethod, path, method)
+		// the trailing slash path is served by the same method, instead of a redirection
+		if !subtree {
+			router.Handler(method.HTTPMethod, path+"/", method)
+		}
+	}
+
+	for _, httpMethod := range res.NotAllowedMethods() {
+		router.Handler(httpMethod, path, res.MethodNotAllowedHandler())
+		if !subtree {
+			router.Handler(httpMethod, path+"/", res.MethodNotAllowedHandler())
+		}
+	}
+
+	if subtree {
+		handleSubtree(router, path, newRoute(res))
+		return
+	}
+
+	for _, subRes := range resources {
+		processResource(router, path, subRes)
+	}
+}
+
+// handleSubtree registers a catch-all route under the path, serving the child resources of the root route.
+func handleSubtree(router *httprouter.Router, path string, root *route) {
+	for _, httpMethod := range root.httpMethods(append([]string{}, rest.HTTPMethods...)) {
+		router.Handler(httpMethod, path+"/*"+subtreeParam, root)
+	}
+}
+
+// route is a resource of a subtree served by a catch-all route.
+type route struct {
+	segment    string
+	handlers   map[string]http.Handler
+	notAllowed http.Handler
+	children   []*route
+}
+
+func newRoute(res rest.Resource) *route {
+	rt := &route{segment: res.Path(), handlers: map[string]http.Handler{}, children: newRoutes(res.Resources())}
+
+	for _, method := range res.Methods() {
+		rt.handlers[method.HTTPMethod] = method
+	}
+
+	if len(res.NotAllowedMethods()) > 0 {
+		rt.notAllowed = res.MethodNotAllowedHandler()
+	}
+
+	return rt
+}
+
+func newRoutes(resources []rest.Resource) []*route {
+	routes := []*route{}
+	for _, res := range resources {
+		routes = append(routes, newRoute(res))
+	}
+
+	return routes
+}
+
+// httpMethods appends the declared methods of the subtree that are not in the httpMethods list.
+func (rt *route) httpMethods(httpMethods []string) []string {
+	for httpMethod := range rt.handlers {
+		if !contains(httpMethods, httpMethod) {
+			httpMethods = append(httpMethods, httpMethod)
+		}
+	}
+
+	for _, child := range rt.children {
+		httpMethods = child.httpMethods(httpMethods)
+	}
+
+	return httpMethods
+}
+
+// ServeHTTP matches the path of the catch-all parameter against the subtree,
+// and serves the request with the matched resource method.
+func (rt *route) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	params := httprouter.ParamsFromContext(r.Context())
+	path := strings.TrimSuffix(strings.TrimPrefix(params.ByName(subtreeParam), "/"), "/")
+	segments := []string{}
+
+	if path != "" {
+		segments = strings.Split(path, "/")
+	}
+
+	// the catch-all parameter is the last one, the previous ones are the URI parameters of the parent resources
+	matched, params := rt.match(segments, params[:len(params)-1])
+	if matched == nil {
+		http.NotFound(w, r)
+		return
+	}
+
+	r = r.WithContext(context.WithValue(r.Context(), httprouter.ParamsKey, params))
+
+	if handler, ok := matched.handlers[r.Method]; ok {
+		handler.ServeHTTP(w, r)
+		return
+	}
+
+	if matched.notAllowed != nil {
+		matched.notAllowed.ServeHTTP(w, r)
+		return
+	}
+
+	http.NotFound(w, r)
+}
+
+// match returns the route of the path segments, and the params with the URI parameters of the path.
+// The static resources are matched before the URI parameter resources.
+func (rt *route) match(segments []string, params httprouter.Params) (*route, httprouter.Params) {
+	if len(segments) == 0 {
+		return rt, params
+	}
+
+	for _, child := range rt.children {
+		if !isURIParameterPath(child.segment) && child.segment == segments[0] {
+			if matched, matchedParams := child.match(segments[1:], params); matched != nil {
+				return matched, matchedParams
+			}
+		}
+	}
+
+	if segments[0] == "" {
+		return nil, nil
+	}
+
+	for _, child := range rt.children {
+		if isURIParameterPath(child.segment) {
+			param := httprouter.Param{Key: strings.TrimSuffix(strings.TrimPrefix(child.segment, "{"), "}"), Value: segments[0]}
+			if matched, matchedParams := child.match(segments[1:], append(params[:len(params):len(params)], param)); matched != nil {
+				return matched, matchedParams
+			}
+		}
+	}
+
+	return nil, nil
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+
+	return false
+}
+
+// routeSegment translates a URI parameter resource path ({name}) to the httprouter named parameter syntax (:name).
+func routeSegment(path string) string {
+	if isURIParameterPath(path) {
+		return ":" + strings.TrimSuffix(strings.TrimPrefix(path, "{"), "}")
+	}
+
+	return path
+}
+
+func isURIParameterPath(path string) bool {
+	return strings.HasPrefix(path, "{") && strings.HasSuffix(path, "}")
+}
+
+// hasSiblingsConflict reports whether a URI parameter resource has sibling resources, a conflict for the httprouter router.
+func hasSiblingsConflict(resources []rest.Resource) bool {
+	if len(resources) < 2 {
+		return false
+	}
+
+	for _, res := range resources {
+		if isURIParameterPath(res.Path()) {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,96 @@
+package httproutergenerator_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/ehsoc/rest"
+	"github.com/ehsoc/rest/encdec"
+	"github.com/ehsoc/rest/generator/server/httproutergenerator"
+	"github.com/ehsoc/rest/generator/server/servertest"
+)
+
+func TestHTTPRouterGenerator(t *testing.T) {
+	servertest.Run(t, httproutergenerator.HTTPRouterGenerator{})
+}
+
+func TestURIParameterSiblings(t *testing.T) {
+	ct := rest.NewContentTypes()
+	ct.Add("application/json", encdec.JSONEncoderDecoder{}, true)
+	operation := func(name string) rest.MethodOperation {
+		return rest.NewMethodOperation(rest.OperationFunc(func(i rest.Input) (interface{}, bool, error) {
+			params := map[string]string{"operation": name}
+			for _, key := range []string{"storeId", "petId"} {
+				params[key], _ = i.GetURIParam(key)
+			}
+			return params, true, nil
+		}), rest.NewResponse(200).WithOperationResultBody(map[string]string{}))
+	}
+	storeID := rest.NewURIParameter("storeId", reflect.String)
+	petID := rest.NewURIParameter("petId", reflect.String)
+	api := rest.API{BasePath: "/v1"}
+	api.Resource("store", func(r *rest.Resource) {
+		r.Resource("inventory", func(r *rest.Resource) {
+			r.Get(operation("inventory"), ct)
+		})
+		r.ResourceP(storeID, func(r *rest.Resource) {
+			r.Get(operation("store"), ct).WithParameter(storeID)
+			r.Resource("pet", func(r *rest.Resource) {
+				r.Get(operation("pets"), ct).WithParameter(storeID)
+				r.Resource("findByStatus", func(r *rest.Resource) {
+					r.Get(operation("findByStatus"), ct).WithParameter(storeID)
+				})
+				r.ResourceP(petID, func(r *rest.Resource) {
+					r.Get(operation("pet"), ct).WithParameter(storeID).WithParameter(petID)
+					r.Delete(operation("deletePet"), ct).WithParameter(storeID).WithParameter(petID)
+				})
+			})
+		})
+	})
+	server := api.GenerateServer(httproutergenerator.HTTPRouterGenerator{})
+	tt := []struct {
+		method     string
+		path       string
+		wantCode   int
+		wantParams map[string]string
+	}{
+		{"GET", "/v1/store/inventory", 200, map[string]string{"operation": "inventory", "storeId": "", "petId": ""}},
+		{"GET", "/v1/store/inventory/", 200, map[string]string{"operation": "inventory", "storeId": "", "petId": ""}},
+		{"GET", "/v1/store/1", 200, map[string]string{"operation": "store", "storeId": "1", "petId": ""}},
+		{"GET", "/v1/store/1/pet", 200, map[string]string{"operation": "pets", "storeId": "1", "petId": ""}},
+		{"GET", "/v1/store/1/pet/", 200, map[string]string{"operation": "pets", "storeId": "1", "petId": ""}},
+		{"GET", "/v1/store/1/pet/findByStatus", 200, map[string]string{"operation": "findByStatus", "storeId": "1", "petId": ""}},
+		{"GET", "/v1/store/1/pet/2", 200, map[string]string{"operation": "pet", "storeId": "1", "petId": "2"}},
+		{"DELETE", "/v1/store/1/pet/2/", 200, map[string]string{"operation": "deletePet", "storeId": "1", "petId": "2"}},
+		{"DELETE", "/v1/store/1/pet/findByStatus", 405, nil},
+		{"GET", "/v1/store/1/pet/2/photo", 404, nil},
+		{"GET", "/v1/store/1/pet//", 404, nil},
+		{"GET", "/v1/store/", 404, nil},
+	}
+	for _, test := range tt {
+		t.Run(test.method+" "+test.path, func(t *testing.T) {
+			request, _ := http.NewRequest(test.method, test.path, nil)
+			response := httptest.NewRecorder()
+			server.ServeHTTP(response, request)
+			if response.Code != test.wantCode {
+				t.Fatalf("got: %v want: %v", response.Code, test.wantCode)
+			}
+			if test.wantCode == 405 && response.Header().Get("Allow") != "GET" {
+				t.Errorf("got Allow header: %q", response.Header().Get("Allow"))
+			}
+			if test.wantParams == nil {
+				return
+			}
+			params := map[string]string{}
+			if err := json.NewDecoder(response.Body).Decode(&params); err != nil {
+				t.Fatalf("not expecting error: %v", err)
+			}
+			if !reflect.DeepEqual(params, test.wantParams) {
+				t.Errorf("got: %v want: %v", params, test.wantParams)
+			}
+		})
+	}
+}
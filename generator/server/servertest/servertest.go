@@ -0,0 +1,555 @@
+// Package servertest provides a conformance test harness for rest.ServerGenerator implementations.
+// A generator passes the harness if it translates the Resource tree and URI parameters of an API
+// into a http.Handler with the same routing behaviour as the generators of this module:
+//
+//	func TestServerGenerator(t *testing.T) {
+//		servertest.Run(t, mygenerator.MyGenerator{})
+//	}
+package servertest
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/ehsoc/rest"
+	"github.com/ehsoc/rest/encdec"
+	"github.com/ehsoc/rest/test/petstore"
+)
+
+// OperationStub is an Operation that records the URI parameter petId and the decoded Pet body.
+type OperationStub struct {
+	wasCall bool
+	Pet     petstore.Pet
+	PetID   string
+}
+
+// Execute implements the Operation interface.
+func (o *OperationStub) Execute(i rest.Input) (interface{}, bool, error) {
+	o.wasCall = true
+	petID, _ := i.GetURIParam("petId")
+	o.PetID = petID
+	pet := petstore.Pet{}
+	body, _ := i.GetBody()
+	if body != nil && body != http.NoBody {
+		i.BodyDecoder.Decode(body, &pet)
+		o.Pet = pet
+	}
+	error, _ := i.GetQueryString("error")
+	if error != "" {
+		return nil, false, errors.New("Failed")
+	}
+	return o.Pet, true, nil
+}
+
+// Run runs the conformance tests against the ServerGenerator gen.
+func Run(t *testing.T, gen rest.ServerGenerator) {
+	t.Run("generate server", func(t *testing.T) {
+		testGenerateServer(t, gen)
+	})
+	t.Run("nested routes", func(t *testing.T) {
+		testNestedRoutes(t, gen)
+	})
+	t.Run("uri parameters", func(t *testing.T) {
+		testURIParameters(t, gen)
+	})
+	t.Run("base path", func(t *testing.T) {
+		testBasePath(t, gen)
+	})
+	t.Run("trailing slash", func(t *testing.T) {
+		testTrailingSlash(t, gen)
+	})
+	t.Run("not found and method not allowed", func(t *testing.T) {
+		testNotFoundAndMethodNotAllowed(t, gen)
+	})
+	t.Run("http methods", func(t *testing.T) {
+		testHTTPMethods(t, gen)
+	})
+	t.Run("middleware order", func(t *testing.T) {
+		testMiddlewareOrder(t, gen)
+	})
+	t.Run("automatic methods", func(t *testing.T) {
+		testAutoMethods(t, gen)
+	})
+	t.Run("cors", func(t *testing.T) {
+		testCORS(t, gen)
+	})
+}
+
+func jsonContentTypes() rest.ContentTypes {
+	ct := rest.NewContentTypes()
+	ct.Add("application/json", encdec.JSONEncoderDecoder{}, true)
+	return ct
+}
+
+func testGenerateServer(t *testing.T, gen rest.ServerGenerator) {
+	t.Run("get method", func(t *testing.T) {
+		api := rest.API{}
+		api.BasePath = "/v2"
+		api.Host = "localhost"
+		ct := jsonContentTypes()
+		operation := &OperationStub{}
+		getMethodOp := rest.NewMethodOperation(operation, rest.NewResponse(200)).WithFailResponse(rest.NewResponse(http.StatusNotFound))
+
+		myID := "101"
+		api.Resource("pet", func(r *rest.Resource) {
+			uriParam := rest.NewURIParameter("petId", reflect.String)
+			r.ResourceP(uriParam, func(r *rest.Resource) {
+				r.Get(getMethodOp, ct).WithParameter(uriParam)
+			})
+		})
+
+		server := gen.GenerateServer(api)
+		ctx := context.WithValue(context.Background(), rest.InputContextKey("uriparamfunc"), gen.GetURIParam())
+		request, _ := http.NewRequest(http.MethodGet, "/v2/pet/"+myID, nil)
+		request = request.WithContext(ctx)
+		response := httptest.NewRecorder()
+		server.ServeHTTP(response, request)
+		if response.Code != http.StatusOK {
+			t.Errorf("got: %v want: %v", response.Code, http.StatusOK)
+		}
+		if !operation.wasCall {
+			t.Errorf("operation was not called")
+		}
+		if operation.PetID != myID {
+			t.Errorf("got: %s want: %s", operation.PetID, myID)
+		}
+	})
+	t.Run("post method", func(t *testing.T) {
+		api := rest.API{}
+		api.BasePath = "/v2"
+		api.Host = "localhost"
+		ct := jsonContentTypes()
+		operation := &OperationStub{}
+		postMethodOp := rest.NewMethodOperation(operation, rest.NewResponse(http.StatusCreated).WithBody(petstore.Pet{})).WithFailResponse(rest.NewResponse(http.StatusBadRequest))
+		postMethod := rest.NewMethod(http.MethodPost, postMethodOp, ct)
+		postMethod.RequestBody = rest.RequestBody{Description: "", Body: petstore.Pet{}}
+
+		api.Resource("pet", func(r *rest.Resource) {
+			r.AddMethod(postMethod)
+		})
+		server := gen.GenerateServer(api)
+
+		pet := petstore.Pet{Name: "Cat"}
+		buf := new(bytes.Buffer)
+		encoder := encdec.JSONEncoderDecoder{}
+		encoder.Encode(buf, pet)
+
+		request, _ := http.NewRequest(http.MethodPost, "/v2/pet", buf)
+		request.Header.Set("Content-Type", "application/json")
+		response := httptest.NewRecorder()
+		server.ServeHTTP(response, request)
+		if response.Code != http.StatusCreated {
+			t.Errorf("got: %v want: %v", response.Code, http.StatusCreated)
+		}
+		if !operation.wasCall {
+			t.Errorf("operation was not called")
+		}
+		if !reflect.DeepEqual(pet, operation.Pet) {
+			t.Errorf("got: %v want: %v", pet, operation.Pet)
+		}
+	})
+}
+
+var testRoutes = []struct {
+	route    string
+	wantCode int
+}{
+	{"/v1/1", 404},
+	{"/v1/1/2", 404},
+	{"/v1/1/2/3", 200},
+	{"/v1/1/2/3/4/5/1", 200},
+}
+
+func testNestedRoutes(t *testing.T, gen rest.ServerGenerator) {
+	mo := rest.NewMethodOperation(&OperationStub{}, rest.NewResponse(http.StatusOK)).WithFailResponse(rest.NewResponse(500))
+	ct := jsonContentTypes()
+
+	api := rest.API{}
+	api.BasePath = "/v1"
+	api.Resource("1", func(r *rest.Resource) {
+		r.Resource("2", func(r *rest.Resource) {
+			r.Resource("3", func(r *rest.Resource) {
+				r.Get(mo, ct)
+				r.Resource("4", func(r *rest.Resource) {
+					r.Resource("5", func(r *rest.Resource) {
+						r.Resource("1", func(r *rest.Resource) {
+							r.Get(mo, ct)
+						})
+					})
+				})
+			})
+		})
+	})
+
+	server := api.GenerateServer(gen)
+	for _, test := range testRoutes {
+		t.Run(test.route, func(t *testing.T) {
+			request, _ := http.NewRequest(http.MethodGet, test.route, nil)
+			request.Header.Set("Content-Type", "application/json")
+			response := httptest.NewRecorder()
+			server.ServeHTTP(response, request)
+			if response.Code != test.wantCode {
+				t.Errorf("got: %v want: %v", response.Code, test.wantCode)
+			}
+		})
+	}
+}
+
+// paramsOperation returns the values of the URI parameters names in the success response body.
+func paramsOperation(names ...string) rest.MethodOperation {
+	return rest.NewMethodOperation(rest.OperationFunc(func(i rest.Input) (interface{}, bool, error) {
+		params := map[string]string{}
+		for _, name := range names {
+			params[name], _ = i.GetURIParam(name)
+		}
+		return params, true, nil
+	}), rest.NewResponse(http.StatusOK).WithOperationResultBody(map[string]string{}))
+}
+
+// serve sends a request to the server, returning the response and the decoded URI parameters of a paramsOperation.
+func serve(t *testing.T, server http.Handler, method, path string) (*httptest.ResponseRecorder, map[string]string) {
+	t.Helper()
+	request, _ := http.NewRequest(method, path, nil)
+	request.Header.Set("Content-Type", "application/json")
+	response := httptest.NewRecorder()
+	server.ServeHTTP(response, request)
+	params := map[string]string{}
+	if response.Code == http.StatusOK && response.Body.Len() > 0 {
+		err := json.NewDecoder(bytes.NewReader(response.Body.Bytes())).Decode(&params)
+		if err != nil {
+			t.Fatalf("not expecting error decoding the response body %q: %v", response.Body.String(), err)
+		}
+	}
+	return response, params
+}
+
+func assertCode(t *testing.T, response *httptest.ResponseRecorder, want int) {
+	t.Helper()
+	if response.Code != want {
+		t.Errorf("got: %v want: %v", response.Code, want)
+	}
+}
+
+func testURIParameters(t *testing.T, gen rest.ServerGenerator) {
+	ct := jsonContentTypes()
+	api := rest.API{}
+	api.BasePath = "/v1"
+	api.Resource("store", func(r *rest.Resource) {
+		storeID := rest.NewURIParameter("storeId", reflect.String)
+		r.ResourceP(storeID, func(r *rest.Resource) {
+			r.Get(paramsOperation("storeId"), ct).WithParameter(storeID)
+			r.Resource("pet", func(r *rest.Resource) {
+				petID := rest.NewURIParameter("petId", reflect.String)
+				r.ResourceP(petID, func(r *rest.Resource) {
+					r.Get(paramsOperation("storeId", "petId"), ct).WithParameter(storeID).WithParameter(petID)
+					r.Resource("photo", func(r *rest.Resource) {
+						photoID := rest.NewURIParameter("photoId", reflect.String)
+						r.ResourceP(photoID, func(r *rest.Resource) {
+							r.Get(paramsOperation("storeId", "petId", "photoId"), ct).
+								WithParameter(storeID).WithParameter(petID).WithParameter(photoID)
+						})
+					})
+				})
+			})
+		})
+	})
+	server := api.GenerateServer(gen)
+	tt := []struct {
+		path string
+		want map[string]string
+	}{
+		{"/v1/store/s1", map[string]string{"storeId": "s1"}},
+		{"/v1/store/s1/pet/p2", map[string]string{"storeId": "s1", "petId": "p2"}},
+		{"/v1/store/s1/pet/p2/photo/f3", map[string]string{"storeId": "s1", "petId": "p2", "photoId": "f3"}},
+		{"/v1/store/pet/pet/photo/photo/pet", map[string]string{"storeId": "pet", "petId": "photo", "photoId": "pet"}},
+	}
+	for _, test := range tt {
+		t.Run(test.path, func(t *testing.T) {
+			response, got := serve(t, server, http.MethodGet, test.path)
+			assertCode(t, response, http.StatusOK)
+			if !reflect.DeepEqual(got, test.want) {
+				t.Errorf("got: %v want: %v", got, test.want)
+			}
+		})
+	}
+}
+
+func testBasePath(t *testing.T, gen rest.ServerGenerator) {
+	tt := []struct {
+		basePath string
+		found    []string
+		notFound []string
+	}{
+		{"", []string{"/car"}, []string{"/v1/car"}},
+		{"/", []string{"/car"}, []string{"/v1/car"}},
+		{"/v1", []string{"/v1/car"}, []string{"/car", "/v2/car"}},
+		{"/v1/", []string{"/v1/car"}, []string{"/car"}},
+		{"/api/v1", []string{"/api/v1/car"}, []string{"/car", "/api/car", "/v1/car"}},
+	}
+	for _, test := range tt {
+		t.Run("base path "+test.basePath, func(t *testing.T) {
+			api := rest.API{}
+			api.BasePath = test.basePath
+			api.Resource("car", func(r *rest.Resource) {
+				r.Get(paramsOperation(), jsonContentTypes())
+			})
+			server := api.GenerateServer(gen)
+			for _, path := range test.found {
+				response, _ := serve(t, server, http.MethodGet, path)
+				assertCode(t, response, http.StatusOK)
+			}
+			for _, path := range test.notFound {
+				response, _ := serve(t, server, http.MethodGet, path)
+				assertCode(t, response, http.StatusNotFound)
+			}
+		})
+	}
+}
+
+func testTrailingSlash(t *testing.T, gen rest.ServerGenerator) {
+	ct := jsonContentTypes()
+	api := rest.API{}
+	api.BasePath = "/v1"
+	api.Resource("car", func(r *rest.Resource) {
+		r.Get(paramsOperation(), ct)
+		r.Post(paramsOperation(), ct)
+		carID := rest.NewURIParameter("carId", reflect.String)
+		r.ResourceP(carID, func(r *rest.Resource) {
+			r.Get(paramsOperation("carId"), ct).WithParameter(carID)
+		})
+	})
+	server := api.GenerateServer(gen)
+	tt := []struct {
+		method string
+		path   string
+		want   map[string]string
+	}{
+		{http.MethodGet, "/v1/car/", map[string]string{}},
+		{http.MethodPost, "/v1/car/", map[string]string{}},
+		{http.MethodGet, "/v1/car/1/", map[string]string{"carId": "1"}},
+	}
+	for _, test := range tt {
+		t.Run(test.method+" "+test.path, func(t *testing.T) {
+			response, got := serve(t, server, test.method, test.path)
+			assertCode(t, response, http.StatusOK)
+			if !reflect.DeepEqual(got, test.want) {
+				t.Errorf("got: %v want: %v", got, test.want)
+			}
+		})
+	}
+}
+
+func testNotFoundAndMethodNotAllowed(t *testing.T, gen rest.ServerGenerator) {
+	ct := jsonContentTypes()
+	api := rest.API{}
+	api.BasePath = "/v1"
+	api.Resource("car", func(r *rest.Resource) {
+		r.Get(paramsOperation(), ct)
+		r.Post(paramsOperation(), ct)
+		carID := rest.NewURIParameter("carId", reflect.String)
+		r.ResourceP(carID, func(r *rest.Resource) {
+			r.Resource("wheels", func(r *rest.Resource) {
+				r.Put(paramsOperation("carId"), ct).WithParameter(carID)
+			})
+		})
+	})
+	server := api.GenerateServer(gen)
+	tt := []struct {
+		method    string
+		path      string
+		wantCode  int
+		wantAllow string
+	}{
+		{http.MethodGet, "/v1/car", http.StatusOK, ""},
+		{http.MethodDelete, "/v1/car", http.StatusMethodNotAllowed, "GET, POST"},
+		{http.MethodOptions, "/v1/car", http.StatusMethodNotAllowed, "GET, POST"},
+		{http.MethodHead, "/v1/car/", http.StatusMethodNotAllowed, "GET, POST"},
+		{http.MethodPut, "/v1/car/1/wheels", http.StatusOK, ""},
+		{http.MethodGet, "/v1/car/1/wheels", http.StatusMethodNotAllowed, "PUT"},
+		{http.MethodGet, "/v1/car/1", http.StatusNotFound, ""},
+		{http.MethodGet, "/v1/truck", http.StatusNotFound, ""},
+		{http.MethodGet, "/v1/car/1/wheels/2", http.StatusNotFound, ""},
+		{http.MethodGet, "/v1", http.StatusNotFound, ""},
+	}
+	for _, test := range tt {
+		t.Run(test.method+" "+test.path, func(t *testing.T) {
+			response, _ := serve(t, server, test.method, test.path)
+			assertCode(t, response, test.wantCode)
+			if got := response.Header().Get("Allow"); got != test.wantAllow {
+				t.Errorf("got Allow: %q want: %q", got, test.wantAllow)
+			}
+		})
+	}
+}
+
+var httpMethods = []string{
+	http.MethodGet,
+	http.MethodHead,
+	http.MethodPost,
+	http.MethodPut,
+	http.MethodPatch,
+	http.MethodDelete,
+	http.MethodConnect,
+	http.MethodOptions,
+	http.MethodTrace,
+}
+
+func testHTTPMethods(t *testing.T, gen rest.ServerGenerator) {
+	methodOperation := rest.NewMethodOperation(rest.OperationFunc(func(i rest.Input) (interface{}, bool, error) {
+		i.ResponseHeader().Set("X-Method", i.Request.Method)
+		return nil, true, nil
+	}), rest.NewResponse(http.StatusNoContent))
+	api := rest.API{}
+	api.BasePath = "/v1"
+	api.Resource("car", func(r *rest.Resource) {
+		for _, httpMethod := range httpMethods {
+			r.AddMethod(rest.NewMethod(httpMethod, methodOperation, jsonContentTypes()))
+		}
+	})
+	server := api.GenerateServer(gen)
+	for _, httpMethod := range httpMethods {
+		t.Run(httpMethod, func(t *testing.T) {
+			response, _ := serve(t, server, httpMethod, "/v1/car")
+			assertCode(t, response, http.StatusNoContent)
+			if got := response.Header().Get("X-Method"); got != httpMethod {
+				t.Errorf("got: %v want: %v", got, httpMethod)
+			}
+		})
+	}
+}
+
+func testMiddlewareOrder(t *testing.T, gen rest.ServerGenerator) {
+	calls := []string{}
+	middleware := func(name string) rest.Middleware {
+		return func(next http.Handler) http.Handler {
+			return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				calls = append(calls, name)
+				next.ServeHTTP(w, r)
+			})
+		}
+	}
+	methodOperation := rest.NewMethodOperation(rest.OperationFunc(func(i rest.Input) (interface{}, bool, error) {
+		calls = append(calls, "operation")
+		return nil, true, nil
+	}), rest.NewResponse(http.StatusOK))
+	ct := jsonContentTypes()
+	api := rest.API{}
+	api.BasePath = "/v1"
+	api.Resource("car", func(r *rest.Resource) {
+		r.Use(middleware("car 1"), middleware("car 2"))
+		r.Get(methodOperation, ct)
+		carID := rest.NewURIParameter("carId", reflect.String)
+		r.ResourceP(carID, func(r *rest.Resource) {
+			r.Use(middleware("carId"))
+			r.Get(methodOperation, ct).WithParameter(carID)
+		})
+	})
+	server := api.GenerateServer(gen)
+	tt := []struct {
+		path string
+		want []string
+	}{
+		{"/v1/car", []string{"car 1", "car 2", "operation"}},
+		{"/v1/car/1", []string{"car 1", "car 2", "carId", "operation"}},
+	}
+	for _, test := range tt {
+		t.Run(test.path, func(t *testing.T) {
+			calls = []string{}
+			response, _ := serve(t, server, http.MethodGet, test.path)
+			assertCode(t, response, http.StatusOK)
+			if strings.Join(calls, ",") != strings.Join(test.want, ",") {
+				t.Errorf("got: %v want: %v", calls, test.want)
+			}
+		})
+	}
+}
+
+func testAutoMethods(t *testing.T, gen rest.ServerGenerator) {
+	ct := jsonContentTypes()
+	api := rest.API{}
+	api.BasePath = "/v1"
+	api.EnableAutoHead()
+	api.EnableAutoOptions()
+	api.Resource("car", func(r *rest.Resource) {
+		r.Get(paramsOperation(), ct)
+		r.Post(paramsOperation(), ct)
+		carID := rest.NewURIParameter("carId", reflect.String)
+		r.ResourceP(carID, func(r *rest.Resource) {
+			r.Get(paramsOperation("carId"), ct).WithParameter(carID)
+		})
+	})
+	server := api.GenerateServer(gen)
+	tt := []struct {
+		method    string
+		path      string
+		wantCode  int
+		wantAllow string
+	}{
+		{http.MethodDelete, "/v1/car", http.StatusMethodNotAllowed, "GET, POST, HEAD, OPTIONS"},
+		{http.MethodOptions, "/v1/car", http.StatusNoContent, "GET, POST, HEAD, OPTIONS"},
+		{http.MethodOptions, "/v1/car/1", http.StatusNoContent, "GET, HEAD, OPTIONS"},
+		{http.MethodHead, "/v1/car", http.StatusOK, ""},
+		{http.MethodHead, "/v1/car/1", http.StatusOK, ""},
+	}
+	for _, test := range tt {
+		t.Run(test.method+" "+test.path, func(t *testing.T) {
+			response, _ := serve(t, server, test.method, test.path)
+			assertCode(t, response, test.wantCode)
+			if got := response.Header().Get("Allow"); got != test.wantAllow {
+				t.Errorf("got Allow: %q want: %q", got, test.wantAllow)
+			}
+			if response.Body.Len() != 0 {
+				t.Errorf("was not expecting a body, got: %q", response.Body.String())
+			}
+		})
+	}
+}
+
+func testCORS(t *testing.T, gen rest.ServerGenerator) {
+	ct := jsonContentTypes()
+	api := rest.API{}
+	api.BasePath = "/v1"
+	api.UseCORS(rest.CORSPolicy{AllowedOrigins: []string{"https://example.com"}})
+	api.Resource("car", func(r *rest.Resource) {
+		r.Get(paramsOperation(), ct)
+	})
+	api.Resource("truck", func(r *rest.Resource) {
+		r.EnableAutoOptions()
+		r.Get(paramsOperation(), ct)
+	})
+	server := api.GenerateServer(gen)
+	tt := []struct {
+		method     string
+		path       string
+		preflight  bool
+		wantCode   int
+		wantOrigin string
+	}{
+		{http.MethodOptions, "/v1/car", true, http.StatusNoContent, "https://example.com"},
+		{http.MethodOptions, "/v1/truck", true, http.StatusNoContent, "https://example.com"},
+		{http.MethodOptions, "/v1/truck", false, http.StatusNoContent, "https://example.com"},
+		{http.MethodGet, "/v1/car", false, http.StatusOK, "https://example.com"},
+	}
+	for _, test := range tt {
+		t.Run(test.method+" "+test.path, func(t *testing.T) {
+			request, _ := http.NewRequest(test.method, test.path, nil)
+			request.Header.Set("Origin", "https://example.com")
+			if test.preflight {
+				request.Header.Set("Access-Control-Request-Method", http.MethodGet)
+			}
+			response := httptest.NewRecorder()
+			server.ServeHTTP(response, request)
+			assertCode(t, response, test.wantCode)
+			if got := response.Header().Get("Access-Control-Allow-Origin"); got != test.wantOrigin {
+				t.Errorf("got: %q want: %q", got, test.wantOrigin)
+			}
+		})
+	}
+}
@@ -0,0 +1,201 @@
+// Package stdgenerator implements a rest.ServerGenerator using only the net/http package.
+// The routes are matched against a tree of the resources path segments, where the URI parameter
+// resources (created by ResourceP) are matched by any segment value.
+package stdgenerator
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/ehsoc/rest"
+)
+
+type paramsContextKey struct{}
+
+// StdGenerator is a rest.ServerGenerator implementation without third party routers.
+type StdGenerator struct {
+}
+
+// GenerateServer returns a http.Handler routing the requests to the methods of the api resources.
+// A request to a resource path without a handler for the request method will get a 405 (Method Not Allowed) response,
+// and a request to an unknown path will get a 404 (Not Found) response.
+func (s StdGenerator) GenerateServer(api rest.API) http.Handler {
+	root := &node{}
+	base := root
+	for _, segment := range splitPath(api.BasePath) {
+		base = base.staticChild(segment)
+	}
+
+	for _, resource := range api.Resources() {
+		processResource(base, resource)
+	}
+
+	return &router{root}
+}
+
+// GetURIParam returns the function to get the URI parameter values matched by the generated server.
+func (s StdGenerator) GetURIParam() func(*http.Request, string) string {
+	return URLParam
+}
+
+// URLParam returns the value of the URI parameter key matched by the generated server,
+// or an empty string if the parameter was not matched.
+func URLParam(r *http.Request, key string) string {
+	params, _ := r.Context().Value(paramsContextKey{}).(map[string]string)
+	return params[key]
+}
+
+func processResource(parent *node, res rest.Resource) {
+	var n *node
+
+	path := res.Path()
+	if strings.HasPrefix(path, "{") && strings.HasSuffix(path, "}") {
+		n = parent.paramChild(strings.TrimSuffix(strings.TrimPrefix(path, "{"), "}"))
+	} else {
+		n = parent.staticChild(path)
+	}
+
+	for _, method := range res.Methods() {
+		n.handle(method.HTTPMethod, method)
+	}
+
+	for _, httpMethod := range res.NotAllowedMethods() {
+		n.handle(httpMethod, res.MethodNotAllowedHandler())
+	}
+
+	for _, subRes := range res.Resources() {
+		processResource(n, subRes)
+	}
+}
+
+// node is a path segment of the routing tree.
+// A static child has precedence over the param child when both match a segment.
+type node struct {
+	static    map[string]*node
+	param     *node
+	paramName string
+	handlers  map[string]http.Handler
+}
+
+func (n *node) staticChild(segment string) *node {
+	if n.static == nil {
+		n.static = make(map[string]*node)
+	}
+
+	child, ok := n.static[segment]
+	if !ok {
+		child = &node{}
+		n.static[segment] = child
+	}
+
+	return child
+}
+
+func (n *node) paramChild(name string) *node {
+	if n.param == nil {
+		n.param = &node{paramName: name}
+	}
+
+	if n.param.paramName != name {
+		panic(fmt.Sprintf("stdgenerator: URI parameter '{%s}' conflicts with '{%s}' in the same path position", name, n.param.paramName))
+	}
+
+	return n.param
+}
+
+func (n *node) handle(httpMethod string, h http.Handler) {
+	if n.handlers == nil {
+		n.handlers = make(map[string]http.Handler)
+	}
+
+	n.handlers[httpMethod] = h
+}
+
+// match returns the node matching the segments, adding the matched URI parameters to params.
+func (n *node) match(segments []string, params map[string]string) *node {
+	if len(segments) == 0 {
+		if n.handlers == nil {
+			return nil
+		}
+		return n
+	}
+
+	if child, ok := n.static[segments[0]]; ok {
+		if found := child.match(segments[1:], params); found != nil {
+			return found
+		}
+	}
+
+	if n.param != nil {
+		if found := n.param.match(segments[1:], params); found != nil {
+			params[n.param.paramName] = segments[0]
+			return found
+		}
+	}
+
+	return nil
+}
+
+type router struct {
+	root *node
+}
+
+func (rt *router) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	segments, err := splitEscapedPath(r.URL.EscapedPath())
+	if err != nil {
+		http.NotFound(w, r)
+		return
+	}
+
+	params := make(map[string]string)
+	n := rt.root.match(segments, params)
+
+	if n == nil {
+		http.NotFound(w, r)
+		return
+	}
+
+	h, ok := n.handlers[r.Method]
+	if !ok {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+
+	if len(params) > 0 {
+		r = r.WithContext(context.WithValue(r.Context(), paramsContextKey{}, params))
+	}
+
+	h.ServeHTTP(w, r)
+}
+
+// splitPath returns the non empty segments of the path.
+func splitPath(path string) []string {
+	segments := []string{}
+
+	for _, s := range strings.Split(path, "/") {
+		if s != "" {
+			segments = append(segments, s)
+		}
+	}
+
+	return segments
+}
+
+// splitEscapedPath returns the non empty unescaped segments of an escaped path,
+// so an escaped slash is part of the segment value.
+func splitEscapedPath(path string) ([]string, error) {
+	segments := splitPath(path)
+
+	for i, s := range segments {
+		unescaped, err := url.PathUnescape(s)
+		if err != nil {
+			return nil, err
+		}
+		segments[i] = unescaped
+	}
+
+	return segments, nil
+}
@@ -0,0 +1,97 @@
+package stdgenerator_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/ehsoc/rest"
+	"github.com/ehsoc/rest/encdec"
+	"github.com/ehsoc/rest/generator/server/servertest"
+	"github.com/ehsoc/rest/generator/server/stdgenerator"
+)
+
+func TestStdGenerator(t *testing.T) {
+	servertest.Run(t, stdgenerator.StdGenerator{})
+}
+
+func TestStaticAndParamSiblings(t *testing.T) {
+	ct := rest.NewContentTypes()
+	ct.Add("application/json", encdec.JSONEncoderDecoder{}, true)
+	routeOperation := func(route string) rest.MethodOperation {
+		return rest.NewMethodOperation(rest.OperationFunc(func(i rest.Input) (interface{}, bool, error) {
+			petID, _ := i.GetURIParam("petId")
+			return route + ":" + petID, true, nil
+		}), rest.NewResponse(200).WithOperationResultBody(""))
+	}
+	api := rest.API{}
+	api.BasePath = "/v1"
+	api.Resource("pet", func(r *rest.Resource) {
+		r.Resource("findByStatus", func(r *rest.Resource) {
+			r.Get(routeOperation("findByStatus"), ct)
+		})
+		uriParam := rest.NewURIParameter("petId", reflect.String)
+		r.ResourceP(uriParam, func(r *rest.Resource) {
+			r.Get(routeOperation("pet"), ct).WithParameter(uriParam)
+			r.Resource("uploadImage", func(r *rest.Resource) {
+				r.Post(routeOperation("uploadImage"), ct).WithParameter(uriParam)
+			})
+		})
+	})
+	server := api.GenerateServer(stdgenerator.StdGenerator{})
+	tt := []struct {
+		method   string
+		route    string
+		wantCode int
+		wantBody string
+	}{
+		{http.MethodGet, "/v1/pet/findByStatus", 200, "findByStatus:"},
+		{http.MethodGet, "/v1/pet/findByStatus/", 200, "findByStatus:"},
+		{http.MethodGet, "/v1/pet/1", 200, "pet:1"},
+		{http.MethodGet, "/v1/pet/a%2Fb", 200, "pet:a/b"},
+		{http.MethodPost, "/v1/pet/findByStatus/uploadImage", 200, "uploadImage:findByStatus"},
+		{http.MethodDelete, "/v1/pet/1", 405, ""},
+		{http.MethodGet, "/v1/pet", 404, ""},
+		{http.MethodGet, "/pet/1", 404, ""},
+	}
+	for _, test := range tt {
+		t.Run(test.method+test.route, func(t *testing.T) {
+			request, _ := http.NewRequest(test.method, test.route, nil)
+			request.Header.Set("Content-Type", "application/json")
+			response := httptest.NewRecorder()
+			server.ServeHTTP(response, request)
+			if response.Code != test.wantCode {
+				t.Fatalf("got: %v want: %v", response.Code, test.wantCode)
+			}
+			if test.wantBody != "" {
+				got := ""
+				encdec.JSONEncoderDecoder{}.Decode(response.Body, &got)
+				if got != test.wantBody {
+					t.Errorf("got: %v want: %v", got, test.wantBody)
+				}
+			}
+		})
+	}
+}
+
+func TestConflictingURIParameters(t *testing.T) {
+	defer func() {
+		if r := recover(); r == nil {
+			t.Errorf("was expecting a panic")
+		}
+	}()
+	ct := rest.NewContentTypes()
+	ct.Add("application/json", encdec.JSONEncoderDecoder{}, true)
+	mo := rest.NewMethodOperation(&servertest.OperationStub{}, rest.NewResponse(200))
+	api := rest.API{}
+	api.Resource("pet", func(r *rest.Resource) {
+		r.ResourceP(rest.NewURIParameter("petId", reflect.String), func(r *rest.Resource) {
+			r.Get(mo, ct)
+		})
+		r.ResourceP(rest.NewURIParameter("id", reflect.String), func(r *rest.Resource) {
+			r.Put(mo, ct)
+		})
+	})
+	stdgenerator.StdGenerator{}.GenerateServer(api)
+}
@@ -17,12 +17,45 @@ import (
 	"github.com/go-openapi/spec"
 )
 
+var msgErrUnsupportedMethod = "oaiv2: method %s of resource %s is not supported by the OpenAPI v2 specification"
+
+// ErrorUnsupportedMethod is reported when a resource method can not be represented in the specification.
+type ErrorUnsupportedMethod struct {
+	Method string
+	Path   string
+}
+
+func (e *ErrorUnsupportedMethod) Error() string {
+	return fmt.Sprintf(msgErrUnsupportedMethod, e.Method, e.Path)
+}
+
+// traceExtension is the path item vendor extension used for the TRACE operations,
+// as the OpenAPI v2 specification doesn't define a trace field.
+const traceExtension = "x-trace"
+
+// contentEncodingsExtension is the operation vendor extension listing the content codings of the responses and request bodies.
+const contentEncodingsExtension = "x-content-encodings"
+
 type OpenAPIV2SpecGenerator struct {
 	swagger spec.Swagger
+	errs    []error
+}
+
+// Errors returns the errors found during the generation of the specification.
+// A method that can not be represented in the specification will be skipped and reported here.
+func (o *OpenAPIV2SpecGenerator) Errors() []error {
+	return o.errs
+}
+
+func (o *OpenAPIV2SpecGenerator) reportError(err error) {
+	log.Println("Warning on generating specification:", err)
+	o.errs = append(o.errs, err)
 }
 
 func (o *OpenAPIV2SpecGenerator) resolveResource(basePath string, apiResource rest.Resource) {
 	pathItem := spec.PathItem{}
+	newBasePath := path.Join(basePath, apiResource.Path())
+
 	for _, method := range apiResource.Methods() {
 		specMethod := spec.NewOperation("")
 		specMethod.Description = method.Description
@@ -119,6 +152,10 @@ func (o *OpenAPIV2SpecGenerator) resolveResource(basePath string, apiResource re
 		}
 		specMethod.Consumes = method.GetDecoderMediaTypes()
 		specMethod.Produces = method.GetEncoderMediaTypes()
+
+		if encodings := method.GetContentEncodings(); len(encodings) > 0 {
+			specMethod.AddExtension(contentEncodingsExtension, encodings)
+		}
 		// Security
 		for _, security := range method.SecurityCollection {
 			secSchemes := map[string][]string{}
@@ -135,8 +172,21 @@ func (o *OpenAPIV2SpecGenerator) resolveResource(basePath string, apiResource re
 					// Add to secSchemes map
 					secSchemes[securityScheme.Name] = []string{}
 					o.addSecurityDefinition(secScheme.Name, secScheme)
+				case rest.BearerSecurityType:
+					// OpenAPI v2 has no bearer security scheme, so it is described as an API key in the Authorization header,
+					// whatever the scheme parameter is, as the bearer token is always sent in that header
+					secScheme := spec.APIKeyAuth("Authorization", "header")
+					secScheme.Description = securityScheme.Description
+					if secScheme.Description == "" {
+						secScheme.Description = "JWT bearer token, with the `Bearer <token>` value"
+					}
+					// Add to secSchemes map
+					secSchemes[securityScheme.Name] = []string{}
+					o.addSecurityDefinition(securityScheme.Name, secScheme)
 				case rest.OAuth2SecurityType:
 					if securityScheme.OAuth2Flows != nil {
+						// the required scopes of the method, or every scope of the flow
+						requiredScopes, hasRequiredScopes := security.Scopes[securityScheme.Name]
 						// OpenAPI v2 doesn't support multiple flows, so will create a oauth scheme per flow
 						for k, flow := range securityScheme.OAuth2Flows {
 							secScheme := getOAuth2SecScheme(flow)
@@ -147,6 +197,9 @@ func (o *OpenAPIV2SpecGenerator) resolveResource(basePath string, apiResource re
 							for scp := range secScheme.Scopes {
 								scopes = append(scopes, scp)
 							}
+							if hasRequiredScopes {
+								scopes = append([]string{}, requiredScopes...)
+							}
 							sort.Strings(scopes)
 							// Add to secSchemes map
 							secSchemes[securityScheme.Name] = scopes
@@ -170,8 +223,15 @@ func (o *OpenAPIV2SpecGenerator) resolveResource(basePath string, apiResource re
 			} else {
 				res.Description = http.StatusText(response.Code())
 			}
+			for name, header := range response.Headers() {
+				res.AddHeader(name, toHeader(header))
+			}
 			specMethod.RespondsWith(response.Code(), res)
 			specMethod.Responses.Default = nil
+			// Problem details bodies are always encoded as application/problem+json
+			if _, ok := response.Body().(*rest.ProblemDetails); ok && !containsString(specMethod.Produces, rest.ProblemDetailsMediaType) {
+				specMethod.Produces = append(specMethod.Produces, rest.ProblemDetailsMediaType)
+			}
 		}
 
 		switch method.HTTPMethod {
@@ -183,6 +243,16 @@ func (o *OpenAPIV2SpecGenerator) resolveResource(basePath string, apiResource re
 			pathItem.Get = specMethod
 		case http.MethodDelete:
 			pathItem.Delete = specMethod
+		case http.MethodPatch:
+			pathItem.Patch = specMethod
+		case http.MethodHead:
+			pathItem.Head = specMethod
+		case http.MethodOptions:
+			pathItem.Options = specMethod
+		case http.MethodTrace:
+			pathItem.AddExtension(traceExtension, specMethod)
+		default:
+			o.reportError(&ErrorUnsupportedMethod{method.HTTPMethod, newBasePath})
 		}
 	}
 	if o.swagger.Paths == nil {
@@ -190,7 +260,6 @@ func (o *OpenAPIV2SpecGenerator) resolveResource(basePath string, apiResource re
 		o.swagger.Paths.Paths = make(map[string]spec.PathItem)
 	}
 
-	newBasePath := path.Join(basePath, apiResource.Path())
 	// Only Paths with methods should be in the Paths map
 	if len(apiResource.Methods()) > 0 {
 		o.swagger.Paths.Paths[newBasePath] = pathItem
@@ -353,6 +422,18 @@ func (o *OpenAPIV2SpecGenerator) toSchema(v interface{}) *spec.Schema {
 	}
 }
 
+// toHeader converts a response header to a spec header. Non simple types are declared as string.
+func toHeader(header rest.ResponseHeader) *spec.Header {
+	specHeader := spec.ResponseHeader().WithDescription(header.Description)
+	schema, err := simpleTypesToSchema(header.Type)
+
+	if err != nil || len(schema.Type) == 0 {
+		return specHeader.Typed("string", "")
+	}
+
+	return specHeader.Typed(schema.Type[0], schema.Format)
+}
+
 func simpleTypesToSchema(kind reflect.Kind) (*spec.Schema, error) {
 	schema := &spec.Schema{}
 
@@ -399,3 +480,13 @@ func getValue(x interface{}) reflect.Value {
 
 	return val
 }
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+
+	return false
+}
@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"io/ioutil"
 	"log"
+	"net/http"
 	"os"
 	"reflect"
 	"testing"
@@ -259,3 +260,330 @@ func TestSecurityTwoSchemes(t *testing.T) {
 		t.Errorf("expecting id-key map key")
 	}
 }
+
+func TestAllHTTPMethods(t *testing.T) {
+	mo := rest.NewMethodOperation(rest.OperationFunc(func(i rest.Input) (interface{}, bool, error) {
+		return nil, true, nil
+	}), rest.NewResponse(200))
+	ct := rest.NewContentTypes()
+	ct.Add("application/json", encdec.JSONEncoderDecoder{}, true)
+	api := rest.API{}
+	api.Resource("car", func(r *rest.Resource) {
+		r.Get(mo, ct).WithSummary(http.MethodGet)
+		r.Post(mo, ct).WithSummary(http.MethodPost)
+		r.Put(mo, ct).WithSummary(http.MethodPut)
+		r.Delete(mo, ct).WithSummary(http.MethodDelete)
+		r.Patch(mo, ct).WithSummary(http.MethodPatch)
+		r.Head(mo, ct).WithSummary(http.MethodHead)
+		r.Options(mo, ct).WithSummary(http.MethodOptions)
+		r.Trace(mo, ct).WithSummary(http.MethodTrace)
+	})
+	gen := oaiv2.OpenAPIV2SpecGenerator{}
+	generatedSpec := new(bytes.Buffer)
+	gen.GenerateAPISpec(generatedSpec, api)
+	gotSwagger := spec.Swagger{}
+	json.NewDecoder(generatedSpec).Decode(&gotSwagger)
+	pathItem := gotSwagger.Paths.Paths["/car"]
+	traceOperation := &spec.Operation{}
+	if trace, ok := pathItem.Extensions["x-trace"]; ok {
+		b, _ := json.Marshal(trace)
+		json.Unmarshal(b, traceOperation)
+	}
+
+	testCases := []struct {
+		method    string
+		operation *spec.Operation
+	}{
+		{http.MethodGet, pathItem.Get},
+		{http.MethodPost, pathItem.Post},
+		{http.MethodPut, pathItem.Put},
+		{http.MethodDelete, pathItem.Delete},
+		{http.MethodPatch, pathItem.Patch},
+		{http.MethodHead, pathItem.Head},
+		{http.MethodOptions, pathItem.Options},
+		{http.MethodTrace, traceOperation},
+	}
+	for _, tt := range testCases {
+		t.Run(tt.method, func(t *testing.T) {
+			if tt.operation == nil {
+				t.Fatalf("expecting %s operation", tt.method)
+			}
+			if tt.operation.Summary != tt.method {
+				t.Errorf("got: %v want: %v", tt.operation.Summary, tt.method)
+			}
+		})
+	}
+	if len(gen.Errors()) != 0 {
+		t.Errorf("not expecting errors, got: %v", gen.Errors())
+	}
+}
+
+func TestUnsupportedMethod(t *testing.T) {
+	api := rest.API{}
+	api.Resource("tunnel", func(r *rest.Resource) {
+		mo := rest.NewMethodOperation(rest.OperationFunc(func(i rest.Input) (interface{}, bool, error) {
+			return nil, true, nil
+		}), rest.NewResponse(200))
+		r.Connect(mo, rest.NewContentTypes())
+	})
+	gen := oaiv2.OpenAPIV2SpecGenerator{}
+	gen.GenerateAPISpec(new(bytes.Buffer), api)
+	if len(gen.Errors()) != 1 {
+		t.Fatalf("expecting one error, got: %v", gen.Errors())
+	}
+	if _, ok := gen.Errors()[0].(*oaiv2.ErrorUnsupportedMethod); !ok {
+		t.Errorf("got: %T want: %T", gen.Errors()[0], &oaiv2.ErrorUnsupportedMethod{})
+	}
+}
+
+func TestProblemDetails(t *testing.T) {
+	mo := rest.NewMethodOperation(rest.OperationFunc(func(i rest.Input) (interface{}, bool, error) {
+		return nil, true, nil
+	}), rest.NewResponse(200)).WithFailResponse(rest.NewResponse(404))
+	ct := rest.NewContentTypes()
+	ct.Add("application/json", encdec.JSONEncoderDecoder{}, true)
+	api := rest.API{ProblemDetails: true}
+	api.Resource("car", func(r *rest.Resource) {
+		r.Get(mo, ct)
+	})
+	generatedSpec := new(bytes.Buffer)
+	api.GenerateSpec(generatedSpec, &oaiv2.OpenAPIV2SpecGenerator{})
+	gotSwagger := spec.Swagger{}
+	err := json.NewDecoder(generatedSpec).Decode(&gotSwagger)
+	assertNoErrorFatal(t, err)
+	operation := gotSwagger.Paths.Paths["/car"].Get
+	assertJSONStructEqual(t, operation.Produces, []string{"application/json", rest.ProblemDetailsMediaType})
+	for _, code := range []int{404, 415, 500} {
+		response, ok := operation.Responses.StatusCodeResponses[code]
+		if !ok {
+			t.Fatalf("expecting %d response", code)
+		}
+		if response.Schema == nil || response.Schema.Ref.String() != "#/definitions/ProblemDetails" {
+			t.Errorf("expecting ProblemDetails schema for %d response, got: %v", code, response.Schema)
+		}
+	}
+}
+
+func TestOutcomeResponses(t *testing.T) {
+	mo := rest.NewMethodOperation(rest.OperationFunc(func(i rest.Input) (interface{}, bool, error) {
+		return rest.NewOutcome("accepted", nil), true, nil
+	}), rest.NewResponse(201)).
+		WithFailResponse(rest.NewResponse(404)).
+		WithResponse("accepted", rest.NewResponse(202)).
+		WithResponse("conflict", rest.NewResponse(409).WithDescription("car already exists"))
+	ct := rest.NewContentTypes()
+	ct.Add("application/json", encdec.JSONEncoderDecoder{}, true)
+	api := rest.API{}
+	api.Resource("car", func(r *rest.Resource) {
+		r.Post(mo, ct)
+	})
+	gen := oaiv2.OpenAPIV2SpecGenerator{}
+	generatedSpec := new(bytes.Buffer)
+	gen.GenerateAPISpec(generatedSpec, api)
+	gotSwagger := spec.Swagger{}
+	json.NewDecoder(generatedSpec).Decode(&gotSwagger)
+	responses := gotSwagger.Paths.Paths["/car"].Post.Responses.StatusCodeResponses
+	for _, code := range []int{201, 202, 404, 409} {
+		if _, ok := responses[code]; !ok {
+			t.Errorf("expecting %d response", code)
+		}
+	}
+	if responses[409].Description != "car already exists" {
+		t.Errorf("got: %v want: %v", responses[409].Description, "car already exists")
+	}
+}
+
+func TestResponseHeaders(t *testing.T) {
+	successResponse := rest.NewResponse(201).
+		WithHeader("Location", rest.ResponseHeader{Description: "URL of the new car", Type: reflect.String}).
+		WithHeader("Retry-After", rest.ResponseHeader{Type: reflect.Int64})
+	ct := rest.NewContentTypes()
+	ct.Add("application/json", encdec.JSONEncoderDecoder{}, true)
+	api := rest.API{}
+	api.Resource("car", func(r *rest.Resource) {
+		r.Post(rest.NewMethodOperation(rest.OperationFunc(func(i rest.Input) (interface{}, bool, error) {
+			return nil, true, nil
+		}), successResponse), ct)
+	})
+	gen := oaiv2.OpenAPIV2SpecGenerator{}
+	generatedSpec := new(bytes.Buffer)
+	gen.GenerateAPISpec(generatedSpec, api)
+	gotSwagger := spec.Swagger{}
+	json.NewDecoder(generatedSpec).Decode(&gotSwagger)
+	headers := gotSwagger.Paths.Paths["/car"].Post.Responses.StatusCodeResponses[201].Headers
+	want := map[string]spec.Header{
+		"Location":    *spec.ResponseHeader().WithDescription("URL of the new car").Typed("string", ""),
+		"Retry-After": *spec.ResponseHeader().Typed("integer", "int64"),
+	}
+	if !reflect.DeepEqual(headers, want) {
+		t.Errorf("got: %#v want: %#v", headers, want)
+	}
+}
+
+func TestAutoMethods(t *testing.T) {
+	ct := rest.NewContentTypes()
+	ct.Add("application/json", encdec.JSONEncoderDecoder{}, true)
+	mo := rest.NewMethodOperation(rest.OperationFunc(func(i rest.Input) (interface{}, bool, error) {
+		return nil, true, nil
+	}), rest.NewResponse(200))
+	generate := func(enabled bool) spec.PathItem {
+		api := rest.API{}
+		if enabled {
+			api.EnableAutoHead()
+			api.EnableAutoOptions()
+		}
+		api.Resource("car", func(r *rest.Resource) {
+			uriParam := rest.NewURIParameter("carId", reflect.String)
+			r.ResourceP(uriParam, func(r *rest.Resource) {
+				r.Get(mo, ct).WithParameter(uriParam)
+			})
+		})
+		generatedSpec := new(bytes.Buffer)
+		gen := oaiv2.OpenAPIV2SpecGenerator{}
+		gen.GenerateAPISpec(generatedSpec, api)
+		gotSwagger := spec.Swagger{}
+		json.NewDecoder(generatedSpec).Decode(&gotSwagger)
+		return gotSwagger.Paths.Paths["/car/{carId}"]
+	}
+	t.Run("disabled", func(t *testing.T) {
+		pathItem := generate(false)
+		if pathItem.Head != nil || pathItem.Options != nil {
+			t.Errorf("was not expecting HEAD nor OPTIONS operations")
+		}
+	})
+	t.Run("enabled", func(t *testing.T) {
+		pathItem := generate(true)
+		if pathItem.Head == nil || pathItem.Options == nil {
+			t.Fatalf("expecting HEAD and OPTIONS operations")
+		}
+		response, ok := pathItem.Options.Responses.StatusCodeResponses[http.StatusNoContent]
+		if !ok {
+			t.Fatalf("expecting OPTIONS 204 response")
+		}
+		if _, ok := response.Headers["Allow"]; !ok {
+			t.Errorf("expecting Allow header in the OPTIONS response")
+		}
+		if len(pathItem.Options.Parameters) != 1 || pathItem.Options.Parameters[0].Name != "carId" {
+			t.Errorf("expecting carId parameter, got: %v", pathItem.Options.Parameters)
+		}
+	})
+}
+
+func TestContentEncodings(t *testing.T) {
+	ct := rest.NewContentTypes()
+	ct.Add("application/json", encdec.JSONEncoderDecoder{}, true)
+	mo := rest.NewMethodOperation(rest.OperationFunc(func(i rest.Input) (interface{}, bool, error) {
+		return nil, true, nil
+	}), rest.NewResponse(200))
+	api := rest.API{}
+	api.Resource("car", func(r *rest.Resource) {
+		r.Get(mo, ct)
+		r.UseContentEncodings(rest.NewContentEncodings())
+		r.Post(mo, ct)
+	})
+	generatedSpec := new(bytes.Buffer)
+	gen := oaiv2.OpenAPIV2SpecGenerator{}
+	gen.GenerateAPISpec(generatedSpec, api)
+	gotSwagger := spec.Swagger{}
+	json.NewDecoder(generatedSpec).Decode(&gotSwagger)
+	pathItem := gotSwagger.Paths.Paths["/car"]
+	if _, ok := pathItem.Get.Extensions["x-content-encodings"]; ok {
+		t.Errorf("was not expecting x-content-encodings in the GET operation")
+	}
+	got, _ := pathItem.Post.Extensions["x-content-encodings"].([]interface{})
+	if !reflect.DeepEqual(got, []interface{}{"gzip", "deflate"}) {
+		t.Errorf("got: %v want: %v", got, []interface{}{"gzip", "deflate"})
+	}
+}
+
+func TestCachePolicy(t *testing.T) {
+	ct := rest.NewContentTypes()
+	ct.Add("application/json", encdec.JSONEncoderDecoder{}, true)
+	mo := rest.NewMethodOperation(rest.OperationFunc(func(i rest.Input) (interface{}, bool, error) {
+		return nil, true, nil
+	}), rest.NewResponse(200)).WithFailResponse(rest.NewResponse(404))
+	api := rest.API{}
+	api.Resource("car", func(r *rest.Resource) {
+		r.Get(mo, ct).WithCachePolicy(rest.CachePolicy{Public: true, MaxAge: 60})
+	})
+	generatedSpec := new(bytes.Buffer)
+	gen := oaiv2.OpenAPIV2SpecGenerator{}
+	gen.GenerateAPISpec(generatedSpec, api)
+	gotSwagger := spec.Swagger{}
+	json.NewDecoder(generatedSpec).Decode(&gotSwagger)
+	responses := gotSwagger.Paths.Paths["/car"].Get.Responses.StatusCodeResponses
+	header, ok := responses[http.StatusOK].Headers["Cache-Control"]
+	if !ok {
+		t.Fatalf("expecting Cache-Control header in the 200 response")
+	}
+	if header.Description != "public, max-age=60" {
+		t.Errorf("got: %v want: %v", header.Description, "public, max-age=60")
+	}
+	if _, ok := responses[http.StatusNotFound].Headers["Cache-Control"]; ok {
+		t.Errorf("was not expecting Cache-Control header in the 404 response")
+	}
+}
+
+func TestBearerSecurityScheme(t *testing.T) {
+	ct := rest.NewContentTypes()
+	ct.Add("application/json", encdec.JSONEncoderDecoder{}, true)
+	mo := rest.NewMethodOperation(rest.OperationFunc(func(i rest.Input) (interface{}, bool, error) {
+		return nil, true, nil
+	}), rest.NewResponse(200))
+	api := rest.API{}
+	api.Resource("car", func(r *rest.Resource) {
+		r.Get(mo, ct).WithSecurity(rest.NewBearerSecurityScheme("jwt", rest.NewJWTVerifier()))
+		// a bearer scheme that is not built with NewBearerSecurityScheme has no parameter
+		r.Post(mo, ct).WithSecurity(rest.NewSecurityScheme("token", rest.BearerSecurityType, rest.SecurityOperation{
+			Authenticator:                rest.NewJWTVerifier(),
+			FailedAuthenticationResponse: rest.NewResponse(401),
+		}))
+	})
+	generatedSpec := new(bytes.Buffer)
+	gen := oaiv2.OpenAPIV2SpecGenerator{}
+	gen.GenerateAPISpec(generatedSpec, api)
+	gotSwagger := spec.Swagger{}
+	json.NewDecoder(generatedSpec).Decode(&gotSwagger)
+	for _, name := range []string{"jwt", "token"} {
+		secScheme, ok := gotSwagger.SecurityDefinitions[name]
+		if !ok {
+			t.Fatalf("expecting %s security definition", name)
+		}
+		if secScheme.Type != "apiKey" || secScheme.In != "header" || secScheme.Name != "Authorization" {
+			t.Errorf("got: %s in %s named %s want: apiKey in header named Authorization", secScheme.Type, secScheme.In, secScheme.Name)
+		}
+	}
+	want := []map[string][]string{{"jwt": {}}}
+	if got := gotSwagger.Paths.Paths["/car"].Get.Security; !reflect.DeepEqual(got, want) {
+		t.Errorf("got: %v want: %v", got, want)
+	}
+}
+
+func TestSecurityScopes(t *testing.T) {
+	ct := rest.NewContentTypes()
+	ct.Add("application/json", encdec.JSONEncoderDecoder{}, true)
+	mo := rest.NewMethodOperation(rest.OperationFunc(func(i rest.Input) (interface{}, bool, error) {
+		return nil, true, nil
+	}), rest.NewResponse(200))
+	scheme := rest.NewOAuth2SecurityScheme("oauth", rest.SecurityOperation{}).
+		WithImplicitOAuth2Flow("http://localhost/auth", map[string]string{"read:cars": "read", "write:cars": "write"})
+	api := rest.API{}
+	api.Resource("car", func(r *rest.Resource) {
+		r.Get(mo, ct).WithSecurity(scheme)
+		r.Post(mo, ct).WithSecurityScopes(scheme, "write:cars")
+	})
+	generatedSpec := new(bytes.Buffer)
+	gen := oaiv2.OpenAPIV2SpecGenerator{}
+	gen.GenerateAPISpec(generatedSpec, api)
+	gotSwagger := spec.Swagger{}
+	json.NewDecoder(generatedSpec).Decode(&gotSwagger)
+	pathItem := gotSwagger.Paths.Paths["/car"]
+	want := []map[string][]string{{"oauth": {"read:cars", "write:cars"}}}
+	if !reflect.DeepEqual(pathItem.Get.Security, want) {
+		t.Errorf("got: %v want: %v", pathItem.Get.Security, want)
+	}
+	want = []map[string][]string{{"oauth": {"write:cars"}}}
+	if !reflect.DeepEqual(pathItem.Post.Security, want) {
+		t.Errorf("got: %v want: %v", pathItem.Post.Security, want)
+	}
+}
@@ -0,0 +1,667 @@
+package oaiv3
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"path"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ehsoc/rest"
+	"github.com/go-openapi/spec"
+)
+
+const (
+	// Version30 is the OpenAPI 3.0 version written by default in the `openapi` field of the document.
+	Version30 = "3.0.3"
+	// Version31 is the OpenAPI 3.1 version, with the JSON Schema 2020-12 schemas.
+	Version31 = "3.1.0"
+)
+
+const (
+	multipartFormMediaType  = "multipart/form-data"
+	urlEncodedFormMediaType = "application/x-www-form-urlencoded"
+	anyMediaType            = "*/*"
+)
+
+var msgErrUnsupportedMethod = "oaiv3: method %s of resource %s is not supported by the OpenAPI v3 specification"
+
+// ErrorUnsupportedMethod is reported when a resource method can not be represented in the specification.
+type ErrorUnsupportedMethod struct {
+	Method string
+	Path   string
+}
+
+func (e *ErrorUnsupportedMethod) Error() string {
+	return fmt.Sprintf(msgErrUnsupportedMethod, e.Method, e.Path)
+}
+
+// OpenAPIV3SpecGenerator is an APISpecGenerator implementation that writes an OpenAPI v3 document.
+// Version is the value of the `openapi` field, Version30 will be used if is empty.
+// With Version31, the pointer fields are described with the "null" type in a type array, instead of
+// the `nullable` property, and the uploaded files with the `contentMediaType` keyword instead of the binary format.
+// Schemes are used to build the server URLs with the API Host and BasePath, `http` will be used if is empty.
+type OpenAPIV3SpecGenerator struct {
+	Version string
+	Schemes []string
+	doc     Document
+	errs    []error
+	// resolving are the struct types whose schemas are being resolved, to refer to them in recursive types
+	resolving map[reflect.Type]bool
+}
+
+// GenerateAPISpec writes the OpenAPI v3 document of the api into w.
+func (o *OpenAPIV3SpecGenerator) GenerateAPISpec(w io.Writer, api rest.API) {
+	o.doc.OpenAPI = o.Version
+	if o.doc.OpenAPI == "" {
+		o.doc.OpenAPI = Version30
+	}
+
+	o.doc.Info = Info{Title: api.Title, Description: api.Description, Version: api.Version}
+	o.doc.Servers = o.servers(api)
+	o.doc.Paths = make(map[string]*PathItem)
+
+	for _, apiResource := range api.Resources() {
+		o.resolveResource("/", apiResource)
+	}
+
+	e := json.NewEncoder(w)
+
+	e.SetIndent(" ", "  ")
+	e.Encode(o.doc)
+}
+
+// Errors returns the errors found during the generation of the document.
+// A method that can not be represented in the document will be skipped and reported here.
+func (o *OpenAPIV3SpecGenerator) Errors() []error {
+	return o.errs
+}
+
+func (o *OpenAPIV3SpecGenerator) reportError(err error) {
+	log.Println("Warning on generating specification:", err)
+	o.errs = append(o.errs, err)
+}
+
+func (o *OpenAPIV3SpecGenerator) servers(api rest.API) []Server {
+	basePath := strings.TrimSpace(api.BasePath)
+	if strings.TrimSpace(api.Host) == "" {
+		if basePath == "" {
+			return nil
+		}
+
+		return []Server{{URL: basePath}}
+	}
+
+	schemes := o.Schemes
+	if len(schemes) == 0 {
+		schemes = []string{"http"}
+	}
+
+	servers := []Server{}
+	for _, scheme := range schemes {
+		servers = append(servers, Server{URL: scheme + "://" + strings.TrimSpace(api.Host) + basePath})
+	}
+
+	return servers
+}
+
+func (o *OpenAPIV3SpecGenerator) resolveResource(basePath string, apiResource rest.Resource) {
+	pathItem := &PathItem{}
+	newBasePath := path.Join(basePath, apiResource.Path())
+
+	for _, method := range apiResource.Methods() {
+		operation := o.resolveMethod(method)
+
+		switch method.HTTPMethod {
+		case http.MethodGet:
+			pathItem.Get = operation
+		case http.MethodPut:
+			pathItem.Put = operation
+		case http.MethodPost:
+			pathItem.Post = operation
+		case http.MethodDelete:
+			pathItem.Delete = operation
+		case http.MethodOptions:
+			pathItem.Options = operation
+		case http.MethodHead:
+			pathItem.Head = operation
+		case http.MethodPatch:
+			pathItem.Patch = operation
+		case http.MethodTrace:
+			pathItem.Trace = operation
+		default:
+			o.reportError(&ErrorUnsupportedMethod{method.HTTPMethod, newBasePath})
+		}
+	}
+	// Only Paths with methods should be in the Paths map
+	if len(apiResource.Methods()) > 0 {
+		o.doc.Paths[newBasePath] = pathItem
+	}
+
+	for _, apiResource := range apiResource.Resources() {
+		o.resolveResource(newBasePath, apiResource)
+	}
+}
+
+func (o *OpenAPIV3SpecGenerator) resolveMethod(method rest.Method) *Operation {
+	operation := &Operation{Summary: method.Summary, Description: method.Description, ContentEncodings: method.GetContentEncodings()}
+	operation.Responses = make(map[string]*Response)
+	formParameters := []rest.Parameter{}
+
+	for _, parameter := range sortedParameters(method.Parameters()) {
+		if parameter.HTTPType == rest.FormDataParameter || parameter.HTTPType == rest.FileParameter {
+			formParameters = append(formParameters, parameter)
+			continue
+		}
+
+		operation.Parameters = append(operation.Parameters, o.convertParameter(parameter))
+	}
+
+	operation.RequestBody = o.requestBody(method, formParameters)
+	// Security
+	for _, security := range method.SecurityCollection {
+		requirement := SecurityRequirement{}
+		for _, securityScheme := range security.SecuritySchemes {
+			scopes := o.addSecurityScheme(securityScheme)
+			// the scopes of a requirement other than oauth2 must be empty
+			if requiredScopes, ok := security.Scopes[securityScheme.Name]; ok && securityScheme.Type == rest.OAuth2SecurityType {
+				scopes = append([]string{}, requiredScopes...)
+				sort.Strings(scopes)
+			}
+			requirement[securityScheme.Name] = scopes
+		}
+
+		operation.Security = append(operation.Security, requirement)
+	}
+	// Responses
+	encoderMediaTypes := method.GetEncoderMediaTypes()
+
+	for _, response := range method.Responses() {
+		res := &Response{}
+		// Body() returns an interfaces so can be nil
+		if response.Body() != nil {
+			mediaTypes := encoderMediaTypes
+			// Problem details bodies are always encoded as application/problem+json
+			if _, ok := response.Body().(*rest.ProblemDetails); ok {
+				mediaTypes = []string{rest.ProblemDetailsMediaType}
+			}
+			res.Content = o.content(mediaTypes, o.toSchema(response.Body()))
+		}
+		// If response.Description is empty we will set a default response base on the status code
+		if response.Description() != "" {
+			res.Description = response.Description()
+		} else {
+			res.Description = http.StatusText(response.Code())
+		}
+
+		for name, header := range response.Headers() {
+			if res.Headers == nil {
+				res.Headers = make(map[string]*Header)
+			}
+			res.Headers[name] = toHeader(header)
+		}
+
+		operation.Responses[strconv.Itoa(response.Code())] = res
+	}
+
+	return operation
+}
+
+// sortedParameters sorts the parameters for a consistent order in Marshaling.
+// Header parameters will go first, then URI parameters, and then the rest, each group sorted by name.
+func sortedParameters(parameters []rest.Parameter) []rest.Parameter {
+	pKeys := make([]rest.Parameter, 0)
+	pURIKeys := make([]rest.Parameter, 0)
+	pHeaderKeys := make([]rest.Parameter, 0)
+
+	for _, p := range parameters {
+		switch p.HTTPType {
+		case rest.URIParameter:
+			pURIKeys = append(pURIKeys, p)
+		case rest.HeaderParameter:
+			pHeaderKeys = append(pHeaderKeys, p)
+		default:
+			pKeys = append(pKeys, p)
+		}
+	}
+
+	for _, ps := range [][]rest.Parameter{pHeaderKeys, pURIKeys, pKeys} {
+		ps := ps
+		sort.Slice(ps, func(i, j int) bool {
+			return ps[i].Name < ps[j].Name
+		})
+	}
+
+	pHeaderKeys = append(pHeaderKeys, pURIKeys...)
+
+	return append(pHeaderKeys, pKeys...)
+}
+
+func (o *OpenAPIV3SpecGenerator) convertParameter(parameter rest.Parameter) *Parameter {
+	specParam := &Parameter{
+		Name:        parameter.Name,
+		Description: parameter.Description,
+		Required:    parameter.Required,
+		Example:     parameter.Example,
+	}
+
+	switch parameter.HTTPType {
+	case rest.QueryParameter:
+		specParam.In = "query"
+	case rest.URIParameter:
+		specParam.In = "path"
+	case rest.HeaderParameter:
+		specParam.In = "header"
+	}
+
+	if parameter.Type == reflect.Array {
+		specParam.Schema = o.arraySchema(parameter)
+		specParam.Style, specParam.Explode = collectionStyle(parameter.CollectionFormat)
+
+		return specParam
+	}
+
+	specParam.Schema = typedSchema(parameter.Name, parameter.Type)
+
+	return specParam
+}
+
+func (o *OpenAPIV3SpecGenerator) arraySchema(parameter rest.Parameter) *spec.Schema {
+	items := &spec.Schema{}
+
+	if len(parameter.EnumValues) > 0 {
+		items = o.toSchema(parameter.EnumValues[0])
+		if items == nil {
+			items = &spec.Schema{}
+		}
+
+		items.WithEnum(parameter.EnumValues...).WithDefault(parameter.EnumValues[0])
+	}
+
+	return spec.ArrayProperty(items)
+}
+
+// collectionStyle translates an OpenAPI v2 collection format into the v3 style and explode properties.
+func collectionStyle(collectionFormat string) (string, *bool) {
+	explode := false
+
+	switch collectionFormat {
+	case "multi":
+		explode = true
+		return "form", &explode
+	case "ssv":
+		return "spaceDelimited", &explode
+	case "pipes":
+		return "pipeDelimited", &explode
+	default:
+		return "form", &explode
+	}
+}
+
+func (o *OpenAPIV3SpecGenerator) requestBody(method rest.Method, formParameters []rest.Parameter) *RequestBody {
+	if method.RequestBody.Body == nil && len(formParameters) == 0 {
+		return nil
+	}
+
+	requestBody := &RequestBody{Description: method.RequestBody.Description}
+	requestBody.Content = make(map[string]*MediaType)
+	decoderMediaTypes := method.GetDecoderMediaTypes()
+
+	if len(formParameters) > 0 {
+		formSchema := o.formSchema(formParameters)
+		formMediaTypes := []string{}
+		for _, mediaType := range decoderMediaTypes {
+			if isFormMediaType(mediaType) {
+				formMediaTypes = append(formMediaTypes, mediaType)
+			}
+		}
+
+		if len(formMediaTypes) == 0 {
+			formMediaTypes = append(formMediaTypes, defaultFormMediaType(formParameters))
+		}
+
+		for _, mediaType := range formMediaTypes {
+			requestBody.Content[mediaType] = &MediaType{formSchema}
+		}
+	}
+
+	if method.RequestBody.Body != nil {
+		requestBody.Required = method.RequestBody.Required
+		bodyMediaTypes := []string{}
+
+		for _, mediaType := range decoderMediaTypes {
+			if _, ok := requestBody.Content[mediaType]; !ok {
+				bodyMediaTypes = append(bodyMediaTypes, mediaType)
+			}
+		}
+
+		for mediaType, mt := range o.content(bodyMediaTypes, o.toSchema(method.RequestBody.Body)) {
+			requestBody.Content[mediaType] = mt
+		}
+	}
+
+	return requestBody
+}
+
+func (o *OpenAPIV3SpecGenerator) formSchema(formParameters []rest.Parameter) *spec.Schema {
+	schema := &spec.Schema{}
+	schema.Typed("object", "")
+
+	for _, parameter := range formParameters {
+		var property *spec.Schema
+
+		switch {
+		case parameter.HTTPType == rest.FileParameter:
+			property = o.fileSchema()
+		case parameter.Body != nil:
+			property = o.toSchema(parameter.Body)
+		default:
+			property = typedSchema(parameter.Name, parameter.Type)
+		}
+
+		if property == nil {
+			property = &spec.Schema{}
+		}
+		// Sibling properties of a $ref are ignored, so the reference is wrapped to keep the description.
+		if property.Ref.String() != "" && parameter.Description != "" {
+			property = &spec.Schema{SchemaProps: spec.SchemaProps{AllOf: []spec.Schema{*property}}}
+		}
+
+		property.Description = parameter.Description
+		schema.SetProperty(parameter.Name, *property)
+
+		if parameter.Required {
+			schema.AddRequired(parameter.Name)
+		}
+	}
+
+	return schema
+}
+
+func isFormMediaType(mediaType string) bool {
+	return mediaType == multipartFormMediaType || mediaType == urlEncodedFormMediaType
+}
+
+func defaultFormMediaType(formParameters []rest.Parameter) string {
+	for _, parameter := range formParameters {
+		if parameter.HTTPType == rest.FileParameter || parameter.Body != nil {
+			return multipartFormMediaType
+		}
+	}
+
+	return urlEncodedFormMediaType
+}
+
+// content returns a content map with the same schema for every media type.
+func (o *OpenAPIV3SpecGenerator) content(mediaTypes []string, schema *spec.Schema) map[string]*MediaType {
+	if len(mediaTypes) == 0 {
+		mediaTypes = []string{anyMediaType}
+	}
+
+	content := make(map[string]*MediaType)
+	for _, mediaType := range mediaTypes {
+		content[mediaType] = &MediaType{schema}
+	}
+
+	return content
+}
+
+// addSecurityScheme adds the security scheme to the components and returns the scopes of the security requirement.
+func (o *OpenAPIV3SpecGenerator) addSecurityScheme(securityScheme *rest.SecurityScheme) []string {
+	scopes := []string{}
+	secScheme := &SecurityScheme{Description: securityScheme.Description}
+
+	switch securityScheme.Type {
+	case rest.BasicSecurityType:
+		secScheme.Type = "http"
+		secScheme.Scheme = "basic"
+	case rest.APIKeySecurityType:
+		secScheme.Type = "apiKey"
+		secScheme.Name = securityScheme.Parameter.Name
+		secScheme.In = "header"
+
+		if securityScheme.Parameter.HTTPType == rest.QueryParameter {
+			secScheme.In = "query"
+		}
+	case rest.BearerSecurityType:
+		secScheme.Type = "http"
+		secScheme.Scheme = "bearer"
+		secScheme.BearerFormat = "JWT"
+	case rest.OAuth2SecurityType:
+		secScheme.Type = "oauth2"
+		secScheme.Flows = &OAuthFlows{}
+
+		for _, flow := range securityScheme.OAuth2Flows {
+			oauthFlow := &OAuthFlow{flow.AuthorizationURL, flow.TokenURL, flow.RefreshURL, flow.Scopes}
+			if oauthFlow.Scopes == nil {
+				oauthFlow.Scopes = make(map[string]string)
+			}
+
+			switch flow.Name {
+			case rest.FlowImplicitType:
+				secScheme.Flows.Implicit = oauthFlow
+			case rest.FlowPasswordType:
+				secScheme.Flows.Password = oauthFlow
+			case rest.FlowClientCredentialType:
+				secScheme.Flows.ClientCredentials = oauthFlow
+			case rest.FlowAuthCodeType:
+				secScheme.Flows.AuthorizationCode = oauthFlow
+			}
+
+			for scp := range flow.Scopes {
+				scopes = appendUnique(scopes, scp)
+			}
+		}
+
+		sort.Strings(scopes)
+	default:
+		secScheme.Type = securityScheme.Type
+	}
+
+	o.addSecuritySchemeComponent(securityScheme.Name, secScheme)
+
+	return scopes
+}
+
+func appendUnique(values []string, value string) []string {
+	for _, v := range values {
+		if v == value {
+			return values
+		}
+	}
+
+	return append(values, value)
+}
+
+func (o *OpenAPIV3SpecGenerator) checkNilComponents() {
+	if o.doc.Components == nil {
+		o.doc.Components = &Components{}
+	}
+}
+
+func (o *OpenAPIV3SpecGenerator) addSecuritySchemeComponent(name string, securityScheme *SecurityScheme) {
+	o.checkNilComponents()
+
+	if o.doc.Components.SecuritySchemes == nil {
+		o.doc.Components.SecuritySchemes = make(map[string]*SecurityScheme)
+	}
+
+	o.doc.Components.SecuritySchemes[name] = securityScheme
+}
+
+func (o *OpenAPIV3SpecGenerator) addSchema(name string, schema *spec.Schema) {
+	o.checkNilComponents()
+
+	if o.doc.Components.Schemas == nil {
+		o.doc.Components.Schemas = make(map[string]*spec.Schema)
+	}
+
+	o.doc.Components.Schemas[name] = schema
+}
+
+func (o *OpenAPIV3SpecGenerator) toSchema(v interface{}) *spec.Schema {
+	val := getValue(v)
+	switch val.Kind() {
+	case reflect.Array, reflect.Slice:
+		return spec.ArrayProperty(o.toSchema(reflect.New(val.Type().Elem()).Interface()))
+	case reflect.Struct:
+		if _, ok := val.Interface().(time.Time); ok {
+			return spec.DateTimeProperty()
+		}
+		structName := val.Type().Name()
+		// a recursive type refers to its own schema, that is added to the components once its fields are resolved
+		if o.resolving[val.Type()] {
+			return spec.RefSchema("#/components/schemas/" + structName)
+		}
+
+		if o.resolving == nil {
+			o.resolving = make(map[reflect.Type]bool)
+		}
+
+		o.resolving[val.Type()] = true
+		defer delete(o.resolving, val.Type())
+
+		refSchema := &spec.Schema{}
+		refSchema = refSchema.Typed("object", "")
+		refSchema.Description = fmt.Sprintf("A %s object.", structName)
+
+		for i := 0; i < val.NumField(); i++ {
+			// Avoiding panic on unexported fields
+			if val.Field(i).CanInterface() {
+				field := val.Type().Field(i)
+				fieldValue := val.Field(i).Interface()
+				isPtr := field.Type.Kind() == reflect.Ptr
+				// a nil pointer field is described by the type it points to
+				if isPtr && val.Field(i).IsNil() {
+					fieldValue = reflect.New(field.Type.Elem()).Interface()
+				}
+
+				if fieldSchema := o.toSchema(fieldValue); fieldSchema != nil {
+					if isPtr {
+						fieldSchema = o.nullable(fieldSchema)
+					}
+
+					refSchema.SetProperty(getFieldName(field), *fieldSchema)
+				}
+			}
+		}
+		o.addSchema(structName, refSchema)
+
+		return spec.RefSchema("#/components/schemas/" + structName)
+	default:
+		schema, _ := simpleTypesToSchema(val.Kind())
+		return schema
+	}
+}
+
+// isVersion31 reports whether the document is an OpenAPI 3.1 document.
+func (o *OpenAPIV3SpecGenerator) isVersion31() bool {
+	return strings.HasPrefix(o.doc.OpenAPI, "3.1")
+}
+
+// nullable returns the schema of a value that can be null. OpenAPI 3.1 adds the "null" type,
+// and OpenAPI 3.0 sets the nullable property, wrapping a reference as it can't have sibling properties.
+func (o *OpenAPIV3SpecGenerator) nullable(schema *spec.Schema) *spec.Schema {
+	isRef := schema.Ref.String() != ""
+
+	switch {
+	case isRef && o.isVersion31():
+		return &spec.Schema{SchemaProps: spec.SchemaProps{AnyOf: []spec.Schema{*schema, *new(spec.Schema).Typed("null", "")}}}
+	case isRef:
+		return &spec.Schema{SchemaProps: spec.SchemaProps{AllOf: []spec.Schema{*schema}, Nullable: true}}
+	case len(schema.Type) == 0:
+		// a schema without type already allows null
+	case o.isVersion31():
+		schema.Type = append(schema.Type, "null")
+	default:
+		schema.Nullable = true
+	}
+
+	return schema
+}
+
+// fileSchema returns the schema of an uploaded file.
+func (o *OpenAPIV3SpecGenerator) fileSchema() *spec.Schema {
+	schema := spec.StringProperty()
+	if o.isVersion31() {
+		schema.ExtraProps = map[string]interface{}{"contentMediaType": "application/octet-stream"}
+		return schema
+	}
+
+	schema.Format = "binary"
+
+	return schema
+}
+
+func typedSchema(name string, tpe reflect.Kind) *spec.Schema {
+	schema, err := simpleTypesToSchema(tpe)
+	if err != nil {
+		log.Println("Warning on processing parameter", name, ":", err)
+	}
+
+	return schema
+}
+
+// toHeader converts a response header to a header object. Non simple types are declared as string.
+func toHeader(header rest.ResponseHeader) *Header {
+	schema, err := simpleTypesToSchema(header.Type)
+	if err != nil || len(schema.Type) == 0 {
+		schema = spec.StringProperty()
+	}
+
+	return &Header{header.Description, schema}
+}
+
+func simpleTypesToSchema(kind reflect.Kind) (*spec.Schema, error) {
+	schema := &spec.Schema{}
+
+	switch kind {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32:
+		schema = spec.Int32Property()
+	case reflect.Int64:
+		schema = spec.Int64Property()
+	case reflect.String:
+		schema = spec.StringProperty()
+	case reflect.Bool:
+		schema = spec.BoolProperty()
+	case reflect.Float32:
+		schema = spec.Float32Property()
+	case reflect.Float64:
+		schema = spec.Float64Property()
+	case reflect.Array, reflect.Slice, reflect.Struct:
+		return nil, errors.New("kind is a complex type, use toSchema function instead")
+	}
+
+	return schema, nil
+}
+
+func getFieldName(field reflect.StructField) string {
+	fieldName := field.Name
+
+	if jsonTag := field.Tag.Get("json"); jsonTag != "" && jsonTag != "-" {
+		if commaIdx := strings.Index(jsonTag, ","); commaIdx > 0 {
+			return jsonTag[:commaIdx]
+		}
+
+		return jsonTag
+	}
+
+	return fieldName
+}
+
+func getValue(x interface{}) reflect.Value {
+	val := reflect.ValueOf(x)
+
+	if val.Kind() == reflect.Ptr {
+		val = val.Elem()
+	}
+
+	return val
+}
@@ -0,0 +1,529 @@
+package oaiv3_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"log"
+	"os"
+	"reflect"
+	"testing"
+
+	"github.com/ehsoc/rest"
+	"github.com/ehsoc/rest/encdec"
+	"github.com/ehsoc/rest/generator/spec/oaiv3"
+	"github.com/ehsoc/rest/test/petstore"
+	"github.com/nsf/jsondiff"
+)
+
+func generateDocument(t *testing.T, gen *oaiv3.OpenAPIV3SpecGenerator, api rest.API) oaiv3.Document {
+	t.Helper()
+	generatedSpec := new(bytes.Buffer)
+	gen.GenerateAPISpec(generatedSpec, api)
+	doc := oaiv3.Document{}
+	err := json.NewDecoder(generatedSpec).Decode(&doc)
+	assertNoErrorFatal(t, err)
+	return doc
+}
+
+func TestGenerateAPISpec(t *testing.T) {
+	api := petstore.GeneratePetStore()
+	gotDoc := generateDocument(t, &oaiv3.OpenAPIV3SpecGenerator{}, api)
+	wantDoc := oaiv3.Document{}
+	err := json.Unmarshal(getPetJSON(), &wantDoc)
+	assertNoErrorFatal(t, err)
+
+	if gotDoc.OpenAPI != oaiv3.Version30 {
+		t.Errorf("got: %v want: %v", gotDoc.OpenAPI, oaiv3.Version30)
+	}
+	assertJSONStructEqual(t, gotDoc.Servers, wantDoc.Servers)
+
+	for _, p := range []string{"/pet", "/pet/{petId}", "/pet/{petId}/uploadImage", "/pet/findByStatus"} {
+		t.Run(p, func(t *testing.T) {
+			gotPath, ok := gotDoc.Paths[p]
+			if !ok {
+				t.Fatalf("Path not found")
+			}
+			wantPath, ok := wantDoc.Paths[p]
+			if !ok {
+				t.Fatalf("Path not found in test fixture")
+			}
+			assertJSONStructEqual(t, gotPath, wantPath)
+		})
+	}
+	t.Run("components", func(t *testing.T) {
+		assertJSONStructEqual(t, gotDoc.Components, wantDoc.Components)
+	})
+}
+
+func TestNoEmptyResources(t *testing.T) {
+	api := rest.API{}
+	api.BasePath = "/v1"
+	api.Resource("car", func(r *rest.Resource) {
+		carIDParam := rest.NewURIParameter("carId", reflect.String)
+		r.ResourceP(carIDParam, func(r *rest.Resource) {
+			r.Get(rest.MethodOperation{}, rest.ContentTypes{}).WithParameter(carIDParam)
+		})
+	})
+	doc := generateDocument(t, &oaiv3.OpenAPIV3SpecGenerator{}, api)
+
+	if len(doc.Paths) != 1 {
+		t.Errorf("expecting just one resource, got: %v", len(doc.Paths))
+	}
+	wantPath := "/car/{carId}"
+	if _, ok := doc.Paths[wantPath]; !ok {
+		t.Errorf("want: %v", wantPath)
+	}
+}
+
+func TestServers(t *testing.T) {
+	t.Run("host and schemes", func(t *testing.T) {
+		api := rest.NewAPI("/v1", "localhost:8080", "title", "v1")
+		gen := &oaiv3.OpenAPIV3SpecGenerator{Schemes: []string{"https", "http"}}
+		doc := generateDocument(t, gen, api)
+		want := []oaiv3.Server{{URL: "https://localhost:8080/v1"}, {URL: "http://localhost:8080/v1"}}
+		assertJSONStructEqual(t, doc.Servers, want)
+	})
+	t.Run("no host", func(t *testing.T) {
+		api := rest.NewAPI("/v1", "", "title", "v1")
+		doc := generateDocument(t, &oaiv3.OpenAPIV3SpecGenerator{}, api)
+		want := []oaiv3.Server{{URL: "/v1"}}
+		assertJSONStructEqual(t, doc.Servers, want)
+	})
+}
+
+var SecOpStub = rest.SecurityOperation{
+	Authenticator: rest.AuthenticatorFunc(func(i rest.Input) rest.AuthError {
+		return nil
+	}),
+	FailedAuthenticationResponse: rest.NewResponse(401),
+	FailedAuthorizationResponse:  rest.NewResponse(403),
+}
+
+func TestOAuth2MultipleFlows(t *testing.T) {
+	api := rest.API{}
+	api.Resource("one", func(r *rest.Resource) {
+		mo := rest.NewMethodOperation(rest.OperationFunc(func(i rest.Input) (interface{}, bool, error) {
+			return nil, true, nil
+		}), rest.NewResponse(200))
+		scheme := rest.NewOAuth2SecurityScheme("oauth", SecOpStub).
+			WithImplicitOAuth2Flow("http://localhost/auth", map[string]string{"read": "read access"}).
+			WithClientCredentialOAuth2Flow("http://localhost/token", map[string]string{"write": "write access"})
+		r.Get(mo, mustGetJSONContentType()).WithSecurity(scheme)
+	})
+	doc := generateDocument(t, &oaiv3.OpenAPIV3SpecGenerator{}, api)
+	secScheme, ok := doc.Components.SecuritySchemes["oauth"]
+	if !ok {
+		t.Fatal("expecting oauth security scheme")
+	}
+	if secScheme.Flows.Implicit == nil || secScheme.Flows.Implicit.AuthorizationURL != "http://localhost/auth" {
+		t.Errorf("expecting implicit flow, got: %#v", secScheme.Flows.Implicit)
+	}
+	if secScheme.Flows.ClientCredentials == nil || secScheme.Flows.ClientCredentials.TokenURL != "http://localhost/token" {
+		t.Errorf("expecting client credentials flow, got: %#v", secScheme.Flows.ClientCredentials)
+	}
+	security := doc.Paths["/one"].Get.Security
+	if len(security) != 1 {
+		t.Fatalf("got: %d, expecting 1 in security slice", len(security))
+	}
+	assertJSONStructEqual(t, security[0]["oauth"], []string{"read", "write"})
+}
+
+func TestRequestBodyContentTypes(t *testing.T) {
+	api := rest.API{}
+	api.Resource("car", func(r *rest.Resource) {
+		mo := rest.NewMethodOperation(rest.OperationFunc(func(i rest.Input) (interface{}, bool, error) {
+			return nil, true, nil
+		}), rest.NewResponse(201))
+		ct := rest.NewContentTypes()
+		ct.Add("application/json", encdec.JSONEncoderDecoder{}, true)
+		ct.Add("application/xml", encdec.XMLEncoderDecoder{}, false)
+		r.Post(mo, ct).WithRequestBody("car", Car{})
+	})
+	doc := generateDocument(t, &oaiv3.OpenAPIV3SpecGenerator{}, api)
+	requestBody := doc.Paths["/car"].Post.RequestBody
+	if requestBody == nil {
+		t.Fatal("expecting request body")
+	}
+	if !requestBody.Required {
+		t.Errorf("expecting required request body")
+	}
+	for _, mediaType := range []string{"application/json", "application/xml"} {
+		content, ok := requestBody.Content[mediaType]
+		if !ok {
+			t.Fatalf("expecting %s content", mediaType)
+		}
+		if content.Schema.Ref.String() != "#/components/schemas/Car" {
+			t.Errorf("got: %v want: %v", content.Schema.Ref.String(), "#/components/schemas/Car")
+		}
+	}
+}
+
+func TestUnsupportedMethod(t *testing.T) {
+	api := rest.API{}
+	api.Resource("tunnel", func(r *rest.Resource) {
+		mo := rest.NewMethodOperation(rest.OperationFunc(func(i rest.Input) (interface{}, bool, error) {
+			return nil, true, nil
+		}), rest.NewResponse(200))
+		r.Connect(mo, mustGetJSONContentType())
+	})
+	gen := &oaiv3.OpenAPIV3SpecGenerator{}
+	generateDocument(t, gen, api)
+	if len(gen.Errors()) != 1 {
+		t.Fatalf("expecting one error, got: %v", gen.Errors())
+	}
+	if _, ok := gen.Errors()[0].(*oaiv3.ErrorUnsupportedMethod); !ok {
+		t.Errorf("got: %T want: %T", gen.Errors()[0], &oaiv3.ErrorUnsupportedMethod{})
+	}
+}
+
+type Car struct {
+	ID    int    `json:"id"`
+	Brand string `json:"brand"`
+}
+
+func mustGetJSONContentType() rest.ContentTypes {
+	ct := rest.NewContentTypes()
+	ct.Add("application/json", encdec.JSONEncoderDecoder{}, true)
+	return ct
+}
+
+func assertJSONStructEqual(t *testing.T, got, want interface{}) {
+	t.Helper()
+	gotJSON, err := json.MarshalIndent(got, " ", "  ")
+	if err != nil {
+		t.Fatalf("Not expecting error: %v", err)
+	}
+	wantJSON, err := json.MarshalIndent(want, " ", "  ")
+	if err != nil {
+		t.Fatalf("Not expecting error: %v", err)
+	}
+
+	if !reflect.DeepEqual(gotJSON, wantJSON) {
+		opts := jsondiff.DefaultConsoleOptions()
+		opts.PrintTypes = false
+		_, result := jsondiff.Compare(gotJSON, wantJSON, &opts)
+		t.Errorf("Expecting equal, diff: %s", result)
+	}
+}
+
+func getPetJSON() []byte {
+	jsonFile, err := os.Open("../../../test/fixtures/petstore_oav3.json")
+	if err != nil {
+		log.Println(err)
+	}
+	defer jsonFile.Close()
+	byteValue, _ := ioutil.ReadAll(jsonFile)
+	return byteValue
+}
+
+func assertNoErrorFatal(t *testing.T, err error) {
+	t.Helper()
+	if err != nil {
+		t.Fatalf("Was not expecting error: %v", err)
+	}
+}
+
+func TestProblemDetails(t *testing.T) {
+	api := rest.API{ProblemDetails: true}
+	api.Resource("car", func(r *rest.Resource) {
+		mo := rest.NewMethodOperation(rest.OperationFunc(func(i rest.Input) (interface{}, bool, error) {
+			return nil, true, nil
+		}), rest.NewResponse(200).WithBody(Car{})).WithFailResponse(rest.NewResponse(404))
+		r.Get(mo, mustGetJSONContentType())
+	})
+	generatedSpec := new(bytes.Buffer)
+	api.GenerateSpec(generatedSpec, &oaiv3.OpenAPIV3SpecGenerator{})
+	doc := oaiv3.Document{}
+	err := json.NewDecoder(generatedSpec).Decode(&doc)
+	assertNoErrorFatal(t, err)
+	responses := doc.Paths["/car"].Get.Responses
+	for _, code := range []string{"404", "415", "500"} {
+		response, ok := responses[code]
+		if !ok {
+			t.Fatalf("expecting %s response", code)
+		}
+		content, ok := response.Content[rest.ProblemDetailsMediaType]
+		if !ok || len(response.Content) != 1 {
+			t.Fatalf("expecting just %s content, got: %v", rest.ProblemDetailsMediaType, response.Content)
+		}
+		if content.Schema.Ref.String() != "#/components/schemas/ProblemDetails" {
+			t.Errorf("got: %v want: %v", content.Schema.Ref.String(), "#/components/schemas/ProblemDetails")
+		}
+	}
+	if _, ok := responses["200"].Content["application/json"]; !ok {
+		t.Errorf("expecting application/json content for the success response")
+	}
+}
+
+func TestOutcomeResponses(t *testing.T) {
+	mo := rest.NewMethodOperation(rest.OperationFunc(func(i rest.Input) (interface{}, bool, error) {
+		return rest.NewOutcome("accepted", nil), true, nil
+	}), rest.NewResponse(201)).
+		WithFailResponse(rest.NewResponse(404)).
+		WithResponse("accepted", rest.NewResponse(202)).
+		WithResponse("conflict", rest.NewResponse(409))
+	api := rest.API{}
+	api.Resource("car", func(r *rest.Resource) {
+		r.Post(mo, mustGetJSONContentType())
+	})
+	doc := generateDocument(t, &oaiv3.OpenAPIV3SpecGenerator{}, api)
+	responses := doc.Paths["/car"].Post.Responses
+	for _, code := range []string{"201", "202", "404", "409"} {
+		if _, ok := responses[code]; !ok {
+			t.Errorf("expecting %s response", code)
+		}
+	}
+}
+
+func TestResponseHeaders(t *testing.T) {
+	successResponse := rest.NewResponse(201).
+		WithHeader("Location", rest.ResponseHeader{Description: "URL of the new car", Type: reflect.String}).
+		WithHeader("Retry-After", rest.ResponseHeader{Type: reflect.Int64})
+	api := rest.API{}
+	api.Resource("car", func(r *rest.Resource) {
+		r.Post(rest.NewMethodOperation(rest.OperationFunc(func(i rest.Input) (interface{}, bool, error) {
+			return nil, true, nil
+		}), successResponse), mustGetJSONContentType())
+	})
+	doc := generateDocument(t, &oaiv3.OpenAPIV3SpecGenerator{}, api)
+	headers := doc.Paths["/car"].Post.Responses["201"].Headers
+	location, ok := headers["Location"]
+	if !ok {
+		t.Fatalf("expecting Location header")
+	}
+	if location.Description != "URL of the new car" || !location.Schema.Type.Contains("string") {
+		t.Errorf("got: %#v", location)
+	}
+	retryAfter, ok := headers["Retry-After"]
+	if !ok {
+		t.Fatalf("expecting Retry-After header")
+	}
+	if !retryAfter.Schema.Type.Contains("integer") || retryAfter.Schema.Format != "int64" {
+		t.Errorf("got: %#v", retryAfter.Schema)
+	}
+}
+
+func TestAutoMethods(t *testing.T) {
+	mo := rest.NewMethodOperation(rest.OperationFunc(func(i rest.Input) (interface{}, bool, error) {
+		return nil, true, nil
+	}), rest.NewResponse(200))
+	generate := func(enabled bool) *oaiv3.PathItem {
+		api := rest.API{}
+		if enabled {
+			api.EnableAutoHead()
+			api.EnableAutoOptions()
+		}
+		api.Resource("car", func(r *rest.Resource) {
+			r.Get(mo, mustGetJSONContentType())
+		})
+		doc := generateDocument(t, &oaiv3.OpenAPIV3SpecGenerator{}, api)
+		return doc.Paths["/car"]
+	}
+	if pathItem := generate(false); pathItem.Head != nil || pathItem.Options != nil {
+		t.Errorf("was not expecting HEAD nor OPTIONS operations")
+	}
+	pathItem := generate(true)
+	if pathItem.Head == nil || pathItem.Options == nil {
+		t.Fatalf("expecting HEAD and OPTIONS operations")
+	}
+	if _, ok := pathItem.Options.Responses["204"].Headers["Allow"]; !ok {
+		t.Errorf("expecting Allow header in the OPTIONS response")
+	}
+}
+
+func TestContentEncodings(t *testing.T) {
+	mo := rest.NewMethodOperation(rest.OperationFunc(func(i rest.Input) (interface{}, bool, error) {
+		return nil, true, nil
+	}), rest.NewResponse(200))
+	api := rest.API{}
+	api.Resource("car", func(r *rest.Resource) {
+		r.Get(mo, mustGetJSONContentType())
+		r.Post(mo, mustGetJSONContentType()).WithContentEncodings(rest.NewContentEncodings())
+	})
+	doc := generateDocument(t, &oaiv3.OpenAPIV3SpecGenerator{}, api)
+	pathItem := doc.Paths["/car"]
+	if pathItem.Get.ContentEncodings != nil {
+		t.Errorf("was not expecting x-content-encodings in the GET operation")
+	}
+	if !reflect.DeepEqual(pathItem.Post.ContentEncodings, []string{"gzip", "deflate"}) {
+		t.Errorf("got: %v want: %v", pathItem.Post.ContentEncodings, []string{"gzip", "deflate"})
+	}
+}
+
+func TestCachePolicy(t *testing.T) {
+	mo := rest.NewMethodOperation(rest.OperationFunc(func(i rest.Input) (interface{}, bool, error) {
+		return nil, true, nil
+	}), rest.NewResponse(200)).WithFailResponse(rest.NewResponse(404))
+	api := rest.API{}
+	api.UseCachePolicy(rest.CachePolicy{Public: true, MaxAge: 60})
+	api.Resource("car", func(r *rest.Resource) {
+		r.Get(mo, mustGetJSONContentType())
+	})
+	doc := generateDocument(t, &oaiv3.OpenAPIV3SpecGenerator{}, api)
+	responses := doc.Paths["/car"].Get.Responses
+	header, ok := responses["200"].Headers["Cache-Control"]
+	if !ok {
+		t.Fatalf("expecting Cache-Control header in the 200 response")
+	}
+	if header.Description != "public, max-age=60" {
+		t.Errorf("got: %v want: %v", header.Description, "public, max-age=60")
+	}
+	if _, ok := responses["404"].Headers["Cache-Control"]; ok {
+		t.Errorf("was not expecting Cache-Control header in the 404 response")
+	}
+}
+
+func TestBearerSecurityScheme(t *testing.T) {
+	mo := rest.NewMethodOperation(rest.OperationFunc(func(i rest.Input) (interface{}, bool, error) {
+		return nil, true, nil
+	}), rest.NewResponse(200))
+	api := rest.API{}
+	api.Resource("car", func(r *rest.Resource) {
+		r.Get(mo, mustGetJSONContentType()).WithSecurity(rest.NewBearerSecurityScheme("jwt", rest.NewJWTVerifier()))
+	})
+	doc := generateDocument(t, &oaiv3.OpenAPIV3SpecGenerator{}, api)
+	want := &oaiv3.SecurityScheme{Type: "http", Scheme: "bearer", BearerFormat: "JWT"}
+	if got := doc.Components.SecuritySchemes["jwt"]; !reflect.DeepEqual(got, want) {
+		t.Errorf("got: %#v want: %#v", got, want)
+	}
+}
+
+func TestSecurityScopes(t *testing.T) {
+	mo := rest.NewMethodOperation(rest.OperationFunc(func(i rest.Input) (interface{}, bool, error) {
+		return nil, true, nil
+	}), rest.NewResponse(200))
+	scheme := rest.NewOAuth2SecurityScheme("oauth", rest.SecurityOperation{}).
+		WithImplicitOAuth2Flow("http://localhost/auth", map[string]string{"read:cars": "read", "write:cars": "write"})
+	bearer := rest.NewBearerSecurityScheme("jwt", rest.NewJWTVerifier())
+	api := rest.API{}
+	api.Resource("car", func(r *rest.Resource) {
+		r.Get(mo, mustGetJSONContentType()).WithSecurity(scheme)
+		r.Post(mo, mustGetJSONContentType()).WithSecurityScopes(scheme, "write:cars").WithSecurityScopes(bearer, "write:cars")
+	})
+	doc := generateDocument(t, &oaiv3.OpenAPIV3SpecGenerator{}, api)
+	pathItem := doc.Paths["/car"]
+	want := []oaiv3.SecurityRequirement{{"oauth": {"read:cars", "write:cars"}}}
+	if !reflect.DeepEqual(pathItem.Get.Security, want) {
+		t.Errorf("got: %v want: %v", pathItem.Get.Security, want)
+	}
+	want = []oaiv3.SecurityRequirement{{"oauth": {"write:cars"}}, {"jwt": {}}}
+	if !reflect.DeepEqual(pathItem.Post.Security, want) {
+		t.Errorf("got: %v want: %v", pathItem.Post.Security, want)
+	}
+}
+
+type Category struct {
+	Name          string     `json:"name"`
+	Subcategories []Category `json:"subcategories"`
+	Parent        *Category  `json:"parent"`
+}
+
+func TestRecursiveSchema(t *testing.T) {
+	parent := &Category{Name: "vehicles"}
+	parent.Parent = parent
+	mo := rest.NewMethodOperation(rest.OperationFunc(func(i rest.Input) (interface{}, bool, error) {
+		return nil, true, nil
+	}), rest.NewResponse(200).WithBody(Category{Parent: parent}))
+	api := rest.API{}
+	api.Resource("category", func(r *rest.Resource) {
+		r.Get(mo, mustGetJSONContentType())
+	})
+	doc := generateDocument(t, &oaiv3.OpenAPIV3SpecGenerator{}, api)
+	schema := doc.Components.Schemas["Category"]
+	if schema == nil {
+		t.Fatalf("expecting the Category schema")
+	}
+	subcategories := schema.Properties["subcategories"]
+	parentRef := schema.Properties["parent"]
+	if len(parentRef.AllOf) != 1 || !parentRef.Nullable {
+		t.Fatalf("expecting the parent to be a nullable reference, got: %#v", parentRef)
+	}
+	want := "#/components/schemas/Category"
+	for _, got := range []string{
+		subcategories.Items.Schema.Ref.String(),
+		parentRef.AllOf[0].Ref.String(),
+		doc.Paths["/category"].Get.Responses["200"].Content["application/json"].Schema.Ref.String(),
+	} {
+		if got != want {
+			t.Errorf("got: %v want: %v", got, want)
+		}
+	}
+}
+
+type Vehicle struct {
+	Wheels   *int64    `json:"wheels"`
+	Category *Category `json:"category"`
+}
+
+func TestNullableFields(t *testing.T) {
+	api := rest.API{}
+	api.Resource("vehicle", func(r *rest.Resource) {
+		r.Get(rest.NewMethodOperation(rest.OperationFunc(func(i rest.Input) (interface{}, bool, error) {
+			return nil, true, nil
+		}), rest.NewResponse(200).WithBody(Vehicle{})), mustGetJSONContentType())
+	})
+	categoryRef := "#/components/schemas/Category"
+
+	t.Run("OpenAPI 3.0", func(t *testing.T) {
+		doc := generateDocument(t, &oaiv3.OpenAPIV3SpecGenerator{}, api)
+		if doc.OpenAPI != oaiv3.Version30 {
+			t.Errorf("got: %v want: %v", doc.OpenAPI, oaiv3.Version30)
+		}
+		schema := doc.Components.Schemas["Vehicle"]
+		if schema == nil {
+			t.Fatalf("expecting the Vehicle schema")
+		}
+		wheels := schema.Properties["wheels"]
+		if !wheels.Type.Contains("integer") || len(wheels.Type) != 1 || !wheels.Nullable {
+			t.Errorf("expecting a nullable integer, got: %#v", wheels)
+		}
+		category := schema.Properties["category"]
+		if len(category.AllOf) != 1 || category.AllOf[0].Ref.String() != categoryRef || !category.Nullable {
+			t.Errorf("expecting a nullable reference, got: %#v", category)
+		}
+	})
+	t.Run("OpenAPI 3.1", func(t *testing.T) {
+		doc := generateDocument(t, &oaiv3.OpenAPIV3SpecGenerator{Version: oaiv3.Version31}, api)
+		if doc.OpenAPI != oaiv3.Version31 {
+			t.Errorf("got: %v want: %v", doc.OpenAPI, oaiv3.Version31)
+		}
+		schema := doc.Components.Schemas["Vehicle"]
+		if schema == nil {
+			t.Fatalf("expecting the Vehicle schema")
+		}
+		wheels := schema.Properties["wheels"]
+		if !reflect.DeepEqual([]string(wheels.Type), []string{"integer", "null"}) || wheels.Nullable {
+			t.Errorf("expecting the integer and null types, got: %#v", wheels)
+		}
+		category := schema.Properties["category"]
+		if len(category.AnyOf) != 2 || category.AnyOf[0].Ref.String() != categoryRef ||
+			!category.AnyOf[1].Type.Contains("null") || category.Nullable {
+			t.Errorf("expecting a reference or null, got: %#v", category)
+		}
+	})
+}
+
+func TestFileSchema(t *testing.T) {
+	for _, tt := range []struct {
+		version          string
+		format           string
+		contentMediaType interface{}
+	}{
+		{"", "binary", nil},
+		{oaiv3.Version31, "", "application/octet-stream"},
+	} {
+		t.Run(tt.version, func(t *testing.T) {
+			gen := &oaiv3.OpenAPIV3SpecGenerator{Version: tt.version}
+			doc := generateDocument(t, gen, petstore.GeneratePetStore())
+			content := doc.Paths["/pet/{petId}/uploadImage"].Post.RequestBody.Content["multipart/form-data"]
+			file := content.Schema.Properties["file"]
+			if file.Format != tt.format {
+				t.Errorf("got: %v want: %v", file.Format, tt.format)
+			}
+			if got := file.ExtraProps["contentMediaType"]; got != tt.contentMediaType {
+				t.Errorf("got: %v want: %v", got, tt.contentMediaType)
+			}
+		})
+	}
+}
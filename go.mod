@@ -5,6 +5,9 @@ go 1.15
 require (
 	github.com/go-chi/chi v1.5.1
 	github.com/go-openapi/spec v0.20.0
+	github.com/gorilla/mux v1.8.0
+	github.com/julienschmidt/httprouter v1.3.0
 	github.com/nsf/jsondiff v0.0.0-20200515183724-f29ed568f4ce
 	github.com/spf13/afero v1.5.1
+	gopkg.in/yaml.v2 v2.4.0
 )
@@ -1,5 +1,10 @@
 package httputil
 
+import (
+	"strconv"
+	"strings"
+)
+
 // MediaType represents a media type from an Accept header.
 // Name property is the MIME type name (like "application/json").
 // Params property contains the media type option parameters.
@@ -7,3 +12,54 @@ type MediaType struct {
 	Name   string
 	Params map[string]string
 }
+
+// Quality returns the value of the q parameter (quality value) of the media type.
+// It returns 1 if the parameter is not present, and 0 if it is not a valid quality value.
+func (m MediaType) Quality() float64 {
+	q, ok := m.Params["q"]
+	if !ok {
+		return 1
+	}
+
+	v, err := strconv.ParseFloat(q, 64)
+	if err != nil || v < 0 || v > 1 {
+		return 0
+	}
+
+	return v
+}
+
+// Matches reports whether the media type, that can be a media range with wildcards like "*/*" or "application/*",
+// matches the mimeType. Parameters are not compared.
+func (m MediaType) Matches(mimeType string) bool {
+	if m.Name == "*/*" {
+		return true
+	}
+
+	mimeType = strings.ToLower(mimeType)
+	if strings.HasSuffix(m.Name, "/*") {
+		return strings.HasPrefix(mimeType, strings.TrimSuffix(m.Name, "*"))
+	}
+
+	return m.Name == mimeType
+}
+
+// Specificity returns how specific is the media type: 0 for "*/*", 1 for a subtype wildcard like "application/*",
+// and 2 for a full media type, plus the number of parameters other than q.
+func (m MediaType) Specificity() int {
+	switch {
+	case m.Name == "*/*":
+		return 0
+	case strings.HasSuffix(m.Name, "/*"):
+		return 1
+	}
+
+	specificity := 2
+	for k := range m.Params {
+		if k != "q" {
+			specificity++
+		}
+	}
+
+	return specificity
+}
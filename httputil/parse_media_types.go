@@ -7,6 +7,7 @@ import (
 
 // ParseMediaTypes will parse one or multiple media type directives (i.e Accept or Content-Type headers)
 // and return an array of MediaType representing the MIME type name and optional parameters.
+// A media type that can not be parsed is returned as an empty MediaType, use ParseMediaTypesStrict to get the error.
 func ParseMediaTypes(accept string) []MediaType {
 	mediatypes := []MediaType{}
 	types := strings.Split(accept, ",")
@@ -18,3 +19,25 @@ func ParseMediaTypes(accept string) []MediaType {
 
 	return mediatypes
 }
+
+// ParseMediaTypesStrict is like ParseMediaTypes, but returns an error if any of the media types can not be parsed.
+// Empty elements of the list are ignored.
+func ParseMediaTypesStrict(accept string) ([]MediaType, error) {
+	mediatypes := []MediaType{}
+	types := strings.Split(accept, ",")
+
+	for _, ct := range types {
+		if strings.TrimSpace(ct) == "" {
+			continue
+		}
+
+		name, params, err := mime.ParseMediaType(ct)
+		if err != nil {
+			return nil, err
+		}
+
+		mediatypes = append(mediatypes, MediaType{name, params})
+	}
+
+	return mediatypes, nil
+}
@@ -35,3 +35,62 @@ func TestParseAccept(t *testing.T) {
 		})
 	}
 }
+
+func TestParseMediaTypesStrict(t *testing.T) {
+	t.Run("valid", func(t *testing.T) {
+		got, err := httputil.ParseMediaTypesStrict("application/json;q=0.5, , */*")
+		if err != nil {
+			t.Fatalf("not expecting error: %v", err)
+		}
+		want := []httputil.MediaType{
+			{Name: "application/json", Params: map[string]string{"q": "0.5"}},
+			{Name: "*/*", Params: map[string]string{}},
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("got:%v want:%v", got, want)
+		}
+	})
+	t.Run("invalid", func(t *testing.T) {
+		_, err := httputil.ParseMediaTypesStrict("application/json, application/")
+		if err == nil {
+			t.Errorf("expecting error")
+		}
+	})
+}
+
+func TestMediaType(t *testing.T) {
+	tt := []struct {
+		mediaType       string
+		mimeType        string
+		wantMatch       bool
+		wantQuality     float64
+		wantSpecificity int
+	}{
+		{"*/*", "application/json", true, 1, 0},
+		{"application/*;q=0.5", "application/json", true, 0.5, 1},
+		{"application/*", "text/html", false, 1, 1},
+		{"application/json;q=0", "application/json", true, 0, 2},
+		{"application/json;indent=4", "application/json", true, 1, 3},
+		{"Application/JSON", "application/json", true, 1, 2},
+		{"text/html;q=2", "application/json", false, 0, 2},
+		{"text/html;q=foo", "text/html", true, 0, 2},
+	}
+	for _, test := range tt {
+		t.Run(test.mediaType, func(t *testing.T) {
+			mts, err := httputil.ParseMediaTypesStrict(test.mediaType)
+			if err != nil {
+				t.Fatalf("not expecting error: %v", err)
+			}
+			mt := mts[0]
+			if got := mt.Matches(test.mimeType); got != test.wantMatch {
+				t.Errorf("got:%v want:%v", got, test.wantMatch)
+			}
+			if got := mt.Quality(); got != test.wantQuality {
+				t.Errorf("got:%v want:%v", got, test.wantQuality)
+			}
+			if got := mt.Specificity(); got != test.wantSpecificity {
+				t.Errorf("got:%v want:%v", got, test.wantSpecificity)
+			}
+		})
+	}
+}
@@ -1,9 +1,12 @@
 package rest
 
 import (
+	"fmt"
 	"io"
 	"mime/multipart"
 	"net/http"
+	"reflect"
+	"time"
 
 	"github.com/ehsoc/rest/encdec"
 	"github.com/ehsoc/rest/httputil"
@@ -75,6 +78,17 @@ func (i Input) GetQueryString(key string) (string, error) {
 	return i.Request.URL.Query().Get(key), nil
 }
 
+// GetQueryArray gets the elements of an array query parameter, split according to the parameter CollectionFormat.
+// With the multi format, every value associated with the key is an element of the array.
+// If the parameter is not defined, will return error.
+func (i Input) GetQueryArray(key string) ([]string, error) {
+	p, err := i.Parameters.GetParameter(QueryParameter, key)
+	if err != nil {
+		return nil, err
+	}
+	return splitCollectionValues(p.CollectionFormat, i.Request.URL.Query()[key]), nil
+}
+
 // GetFormValue gets the first value for the named component of the query.
 // FormValue calls FormValue from the standard library.
 // If the parameter is not defined, will return error.
@@ -130,3 +144,237 @@ func (i Input) GetBody() (io.ReadCloser, error) {
 	}
 	return i.Request.Body, nil
 }
+
+// ResponseHeader returns the header map of the response, to set header values like Location, ETag or Link.
+// The headers set before the Execute method of the Operation returns are written by the handler.
+// If the InputContextKey("responseheader") context value is not set, a new empty header map is returned.
+func (i Input) ResponseHeader() http.Header {
+	if header, ok := i.Request.Context().Value(InputContextKey("responseheader")).(http.Header); ok {
+		return header
+	}
+	return http.Header{}
+}
+
+// SetLastModified sets the Last-Modified response header with the modification time of the resource,
+// so a GET or HEAD request with an If-Modified-Since header not older than t gets a 304 (Not Modified) response.
+func (i Input) SetLastModified(t time.Time) {
+	i.ResponseHeader().Set("Last-Modified", t.UTC().Format(http.TimeFormat))
+}
+
+// DecodeBody decodes the request body with the negotiated decoder into a new value of the declared RequestBody type.
+// The body is decoded once per request, and the result is cached in the request context,
+// so it can be called by validators and the operation.
+// If the declared type is a pointer, a pointer to the new value will be returned.
+// If the request body is not defined, will return an ErrorRequestBodyNotDefined error,
+// and if the body can not be decoded, will return an ErrorRequestBodyDecode error.
+func (i Input) DecodeBody() (interface{}, error) {
+	if i.RequestBodyParameter.Body == nil {
+		return nil, ErrorRequestBodyNotDefined
+	}
+	cache, ok := i.Request.Context().Value(InputContextKey("body")).(*requestBodyCache)
+	if !ok {
+		cache = &requestBodyCache{}
+	}
+	if !cache.decoded {
+		decoder := i.BodyDecoder
+		if decoder == nil {
+			decoder = encdec.TextDecoder{}
+		}
+		cache.value, cache.err = decodeRequestBody(i.Request.Body, decoder, i.RequestBodyParameter.Body, cache.strict)
+		cache.decoded = true
+	}
+	return cache.value, cache.err
+}
+
+// parameterValue gets the parameter definition and the raw string value of the parameter.
+func (i Input) parameterValue(paramType ParameterType, key string) (Parameter, string, error) {
+	p, err := i.Parameters.GetParameter(paramType, key)
+	if err != nil {
+		return Parameter{}, "", err
+	}
+
+	var value string
+
+	switch paramType {
+	case URIParameter:
+		value, err = i.GetURIParam(key)
+	case HeaderParameter:
+		value, err = i.GetHeader(key)
+	case QueryParameter:
+		value, err = i.GetQueryString(key)
+	case FormDataParameter:
+		value, err = i.GetFormValue(key)
+	}
+
+	return p, value, err
+}
+
+// GetURIParamInt64 gets the URI parameter converted to int64.
+// The parameter must be declared with an integer Type, otherwise will return an ErrorParameterTypeMismatch error.
+// If the value can not be converted, will return an ErrorParameterParse error.
+func (i Input) GetURIParamInt64(key string) (int64, error) {
+	p, value, err := i.parameterValue(URIParameter, key)
+	if err != nil {
+		return 0, err
+	}
+	return parseInt64Value(p, value)
+}
+
+// GetURIParamFloat gets the URI parameter converted to float64.
+// The parameter must be declared with a float Type, otherwise will return an ErrorParameterTypeMismatch error.
+// If the value can not be converted, will return an ErrorParameterParse error.
+func (i Input) GetURIParamFloat(key string) (float64, error) {
+	p, value, err := i.parameterValue(URIParameter, key)
+	if err != nil {
+		return 0, err
+	}
+	return parseFloatValue(p, value)
+}
+
+// GetURIParamBool gets the URI parameter converted to bool.
+// The parameter must be declared with the Bool Type, otherwise will return an ErrorParameterTypeMismatch error.
+// If the value can not be converted, will return an ErrorParameterParse error.
+func (i Input) GetURIParamBool(key string) (bool, error) {
+	p, value, err := i.parameterValue(URIParameter, key)
+	if err != nil {
+		return false, err
+	}
+	return parseBoolValue(p, value)
+}
+
+// GetQueryInt64 gets the first value of the query parameter converted to int64.
+// If there are no values associated with the key, will return 0.
+// The parameter must be declared with an integer Type, otherwise will return an ErrorParameterTypeMismatch error.
+// If the value can not be converted, will return an ErrorParameterParse error.
+func (i Input) GetQueryInt64(key string) (int64, error) {
+	p, value, err := i.parameterValue(QueryParameter, key)
+	if err != nil {
+		return 0, err
+	}
+	return parseInt64Value(p, value)
+}
+
+// GetQueryFloat gets the first value of the query parameter converted to float64.
+// If there are no values associated with the key, will return 0.
+// The parameter must be declared with a float Type, otherwise will return an ErrorParameterTypeMismatch error.
+// If the value can not be converted, will return an ErrorParameterParse error.
+func (i Input) GetQueryFloat(key string) (float64, error) {
+	p, value, err := i.parameterValue(QueryParameter, key)
+	if err != nil {
+		return 0, err
+	}
+	return parseFloatValue(p, value)
+}
+
+// GetQueryBool gets the first value of the query parameter converted to bool.
+// If there are no values associated with the key, will return false.
+// The parameter must be declared with the Bool Type, otherwise will return an ErrorParameterTypeMismatch error.
+// If the value can not be converted, will return an ErrorParameterParse error.
+func (i Input) GetQueryBool(key string) (bool, error) {
+	p, value, err := i.parameterValue(QueryParameter, key)
+	if err != nil {
+		return false, err
+	}
+	return parseBoolValue(p, value)
+}
+
+// GetHeaderInt64 gets the header parameter converted to int64.
+// If the header is not present, will return 0.
+// The parameter must be declared with an integer Type, otherwise will return an ErrorParameterTypeMismatch error.
+// If the value can not be converted, will return an ErrorParameterParse error.
+func (i Input) GetHeaderInt64(key string) (int64, error) {
+	p, value, err := i.parameterValue(HeaderParameter, key)
+	if err != nil {
+		return 0, err
+	}
+	return parseInt64Value(p, value)
+}
+
+// GetHeaderFloat gets the header parameter converted to float64.
+// If the header is not present, will return 0.
+// The parameter must be declared with a float Type, otherwise will return an ErrorParameterTypeMismatch error.
+// If the value can not be converted, will return an ErrorParameterParse error.
+func (i Input) GetHeaderFloat(key string) (float64, error) {
+	p, value, err := i.parameterValue(HeaderParameter, key)
+	if err != nil {
+		return 0, err
+	}
+	return parseFloatValue(p, value)
+}
+
+// GetHeaderBool gets the header parameter converted to bool.
+// If the header is not present, will return false.
+// The parameter must be declared with the Bool Type, otherwise will return an ErrorParameterTypeMismatch error.
+// If the value can not be converted, will return an ErrorParameterParse error.
+func (i Input) GetHeaderBool(key string) (bool, error) {
+	p, value, err := i.parameterValue(HeaderParameter, key)
+	if err != nil {
+		return false, err
+	}
+	return parseBoolValue(p, value)
+}
+
+// Bind sets the value of the parameter with the given key into the value pointed by dst,
+// converting it according to the declared Type of the parameter.
+// The parameter is searched in the URI, query, header and form data parameters, in that order.
+// dst must be a non-nil pointer to a string, a numeric type or a bool, or a pointer to a
+// string slice for array parameters.
+// If the parameter is not defined will return an ErrorParameterNotDefined error,
+// and if the value can not be converted, will return an ErrorParameterParse error.
+func (i Input) Bind(key string, dst interface{}) error {
+	rv := reflect.ValueOf(dst)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() {
+		return &ErrorParameterTypeMismatch{key, reflect.Invalid, fmt.Sprintf("%T", dst)}
+	}
+
+	for _, paramType := range []ParameterType{URIParameter, QueryParameter, HeaderParameter, FormDataParameter} {
+		p, err := i.Parameters.GetParameter(paramType, key)
+		if err != nil {
+			continue
+		}
+
+		if p.Type == reflect.Array || p.Type == reflect.Slice {
+			values, err := i.parameterValues(p)
+			if err != nil {
+				return err
+			}
+
+			if _, ok := dst.(*[]string); !ok {
+				return &ErrorParameterTypeMismatch{p.Name, p.Type, rv.Elem().Type().String()}
+			}
+
+			*dst.(*[]string) = values
+
+			return nil
+		}
+
+		_, value, err := i.parameterValue(paramType, key)
+		if err != nil {
+			return err
+		}
+
+		return setParameterValue(p, value, rv.Elem())
+	}
+
+	return &ErrorParameterNotDefined{key}
+}
+
+// parameterValues gets all the values of an array parameter.
+func (i Input) parameterValues(p Parameter) ([]string, error) {
+	switch p.HTTPType {
+	case QueryParameter:
+		return i.GetQueryArray(p.Name)
+	case FormDataParameter:
+		values, err := i.GetFormValues(p.Name)
+		return splitCollectionValues(p.CollectionFormat, values), err
+	case HeaderParameter:
+		return splitCollectionValues(p.CollectionFormat, i.Request.Header[http.CanonicalHeaderKey(p.Name)]), nil
+	}
+
+	value, err := i.GetURIParam(p.Name)
+	if err != nil {
+		return nil, err
+	}
+
+	return []string{value}, nil
+}
@@ -257,3 +257,222 @@ func TestGetFormValues(t *testing.T) {
 		}
 	})
 }
+
+func TestTypedAccessors(t *testing.T) {
+	newInput := func(target string, params ...rest.Parameter) rest.Input {
+		ctx := context.WithValue(context.Background(), rest.InputContextKey("uriparamfunc"), func(r *http.Request, key string) string {
+			return "42"
+		})
+		r, _ := http.NewRequest("GET", target, nil)
+		r = r.WithContext(ctx)
+		r.Header.Set("X-Rate", "0.5")
+		parameters := rest.ParameterCollection{}
+		for _, p := range params {
+			parameters.AddParameter(p)
+		}
+		return rest.Input{r, parameters, rest.RequestBody{}, nil}
+	}
+	t.Run("uri int64", func(t *testing.T) {
+		input := newInput("/", rest.NewURIParameter("id", reflect.Int64))
+		got, err := input.GetURIParamInt64("id")
+		assertNoErrorFatal(t, err)
+		if got != 42 {
+			t.Errorf("got: %v want: %v", got, 42)
+		}
+	})
+	t.Run("query bool", func(t *testing.T) {
+		input := newInput("/?active=true", rest.NewQueryParameter("active", reflect.Bool))
+		got, err := input.GetQueryBool("active")
+		assertNoErrorFatal(t, err)
+		if !got {
+			t.Errorf("got: %v want: %v", got, true)
+		}
+	})
+	t.Run("query float", func(t *testing.T) {
+		input := newInput("/?price=9.99", rest.NewQueryParameter("price", reflect.Float64))
+		got, err := input.GetQueryFloat("price")
+		assertNoErrorFatal(t, err)
+		if got != 9.99 {
+			t.Errorf("got: %v want: %v", got, 9.99)
+		}
+	})
+	t.Run("header float", func(t *testing.T) {
+		input := newInput("/", rest.NewHeaderParameter("X-Rate", reflect.Float32))
+		got, err := input.GetHeaderFloat("X-Rate")
+		assertNoErrorFatal(t, err)
+		if got != 0.5 {
+			t.Errorf("got: %v want: %v", got, 0.5)
+		}
+	})
+	t.Run("empty value", func(t *testing.T) {
+		input := newInput("/", rest.NewQueryParameter("limit", reflect.Int))
+		got, err := input.GetQueryInt64("limit")
+		assertNoErrorFatal(t, err)
+		if got != 0 {
+			t.Errorf("got: %v want: %v", got, 0)
+		}
+	})
+	t.Run("parse error", func(t *testing.T) {
+		input := newInput("/?limit=ten", rest.NewQueryParameter("limit", reflect.Int32))
+		_, err := input.GetQueryInt64("limit")
+		parseErr, ok := err.(*rest.ErrorParameterParse)
+		if !ok {
+			t.Fatalf("got: %T want: %T", err, &rest.ErrorParameterParse{})
+		}
+		if parseErr.Name != "limit" || parseErr.Value != "ten" || parseErr.Kind != reflect.Int32 {
+			t.Errorf("unexpected error values: %#v", parseErr)
+		}
+	})
+	t.Run("out of range", func(t *testing.T) {
+		input := newInput("/?limit=300", rest.NewQueryParameter("limit", reflect.Int8))
+		_, err := input.GetQueryInt64("limit")
+		if _, ok := err.(*rest.ErrorParameterParse); !ok {
+			t.Errorf("got: %T want: %T", err, &rest.ErrorParameterParse{})
+		}
+	})
+	t.Run("type mismatch", func(t *testing.T) {
+		input := newInput("/", rest.NewURIParameter("id", reflect.String))
+		_, err := input.GetURIParamInt64("id")
+		if _, ok := err.(*rest.ErrorParameterTypeMismatch); !ok {
+			t.Errorf("got: %T want: %T", err, &rest.ErrorParameterTypeMismatch{})
+		}
+	})
+	t.Run("parameter not found", func(t *testing.T) {
+		input := newInput("/")
+		_, err := input.GetQueryBool("active")
+		if _, ok := err.(*rest.ErrorParameterNotDefined); !ok {
+			t.Errorf("got: %T want: %T", err, &rest.ErrorParameterNotDefined{})
+		}
+	})
+}
+
+func TestBind(t *testing.T) {
+	ctx := context.WithValue(context.Background(), rest.InputContextKey("uriparamfunc"), func(r *http.Request, key string) string {
+		return "7"
+	})
+	r, _ := http.NewRequest("GET", "/?limit=20&tag=a&tag=b&name=rex", nil)
+	r = r.WithContext(ctx)
+	r.Header.Set("X-Debug", "1")
+	parameters := rest.ParameterCollection{}
+	parameters.AddParameter(rest.NewURIParameter("id", reflect.Int64))
+	parameters.AddParameter(rest.NewQueryParameter("limit", reflect.Int))
+	parameters.AddParameter(rest.NewQueryParameter("name", reflect.String))
+	parameters.AddParameter(rest.NewQueryArrayParameter("tag", nil))
+	parameters.AddParameter(rest.NewHeaderParameter("X-Debug", reflect.Bool))
+	input := rest.Input{r, parameters, rest.RequestBody{}, nil}
+
+	t.Run("bind values", func(t *testing.T) {
+		var (
+			id    int64
+			limit uint16
+			name  string
+			tags  []string
+			debug bool
+		)
+		assertNoErrorFatal(t, input.Bind("id", &id))
+		assertNoErrorFatal(t, input.Bind("name", &name))
+		assertNoErrorFatal(t, input.Bind("tag", &tags))
+		assertNoErrorFatal(t, input.Bind("X-Debug", &debug))
+		if id != 7 || name != "rex" || !debug {
+			t.Errorf("unexpected values id: %v name: %v debug: %v", id, name, debug)
+		}
+		if !reflect.DeepEqual(tags, []string{"a", "b"}) {
+			t.Errorf("got: %v want: %v", tags, []string{"a", "b"})
+		}
+		err := input.Bind("limit", &limit)
+		if _, ok := err.(*rest.ErrorParameterTypeMismatch); !ok {
+			t.Errorf("got: %T want: %T", err, &rest.ErrorParameterTypeMismatch{})
+		}
+	})
+	t.Run("overflow", func(t *testing.T) {
+		var id int8
+		parameters := rest.ParameterCollection{}
+		parameters.AddParameter(rest.NewQueryParameter("limit", reflect.Int64))
+		r, _ := http.NewRequest("GET", "/?limit=1000", nil)
+		input := rest.Input{r, parameters, rest.RequestBody{}, nil}
+		err := input.Bind("limit", &id)
+		if _, ok := err.(*rest.ErrorParameterParse); !ok {
+			t.Errorf("got: %T want: %T", err, &rest.ErrorParameterParse{})
+		}
+	})
+	t.Run("not a pointer", func(t *testing.T) {
+		var id int64
+		err := input.Bind("id", id)
+		if _, ok := err.(*rest.ErrorParameterTypeMismatch); !ok {
+			t.Errorf("got: %T want: %T", err, &rest.ErrorParameterTypeMismatch{})
+		}
+	})
+	t.Run("parameter not found", func(t *testing.T) {
+		var v string
+		err := input.Bind("foo", &v)
+		if _, ok := err.(*rest.ErrorParameterNotDefined); !ok {
+			t.Errorf("got: %T want: %T", err, &rest.ErrorParameterNotDefined{})
+		}
+	})
+}
+
+func TestGetQueryArray(t *testing.T) {
+	tt := []struct {
+		name   string
+		format string
+		target string
+		want   []string
+	}{
+		{"default", "", "/?foo=a,b", []string{"a", "b"}},
+		{"csv", "csv", "/?foo=a,b&foo=c", []string{"a", "b", "c"}},
+		{"ssv", "ssv", "/?foo=a%20b", []string{"a", "b"}},
+		{"tsv", "tsv", "/?foo=a%09b", []string{"a", "b"}},
+		{"pipes", "pipes", "/?foo=a|b", []string{"a", "b"}},
+		{"multi", "multi", "/?foo=a&foo=b,c", []string{"a", "b,c"}},
+	}
+	for _, test := range tt {
+		t.Run(test.name, func(t *testing.T) {
+			r, _ := http.NewRequest("GET", test.target, nil)
+			p := rest.NewQueryArrayParameter("foo", nil)
+			p.CollectionFormat = test.format
+			parameters := rest.ParameterCollection{}
+			parameters.AddParameter(p)
+			input := rest.Input{r, parameters, rest.RequestBody{}, nil}
+			got, err := input.GetQueryArray("foo")
+			assertNoErrorFatal(t, err)
+			if !reflect.DeepEqual(got, test.want) {
+				t.Errorf("got: %v want: %v", got, test.want)
+			}
+		})
+	}
+}
+
+func TestDecodeBody(t *testing.T) {
+	t.Run("decode body", func(t *testing.T) {
+		r, _ := http.NewRequest("POST", "/", bytes.NewBufferString(`{"brand":"Ford"}`))
+		input := rest.Input{r, rest.ParameterCollection{}, rest.RequestBody{"", Car{}, true}, encdec.JSONDecoder{}}
+		got, err := input.DecodeBody()
+		assertNoErrorFatal(t, err)
+		if !reflect.DeepEqual(got, Car{Brand: "Ford"}) {
+			t.Errorf("got: %#v want: %#v", got, Car{Brand: "Ford"})
+		}
+	})
+	t.Run("pointer type", func(t *testing.T) {
+		r, _ := http.NewRequest("POST", "/", bytes.NewBufferString(`{"brand":"Ford"}`))
+		input := rest.Input{r, rest.ParameterCollection{}, rest.RequestBody{"", &Car{}, true}, encdec.JSONDecoder{}}
+		got, err := input.DecodeBody()
+		assertNoErrorFatal(t, err)
+		if !reflect.DeepEqual(got, &Car{Brand: "Ford"}) {
+			t.Errorf("got: %#v want: %#v", got, &Car{Brand: "Ford"})
+		}
+	})
+	t.Run("decode error", func(t *testing.T) {
+		r, _ := http.NewRequest("POST", "/", bytes.NewBufferString(`{"brand":`))
+		input := rest.Input{r, rest.ParameterCollection{}, rest.RequestBody{"", Car{}, true}, encdec.JSONDecoder{}}
+		_, err := input.DecodeBody()
+		if _, ok := err.(*rest.ErrorRequestBodyDecode); !ok {
+			t.Errorf("got: %T want: %T", err, &rest.ErrorRequestBodyDecode{})
+		}
+	})
+	t.Run("request body not defined", func(t *testing.T) {
+		r, _ := http.NewRequest("POST", "/", bytes.NewBufferString(`{"brand":"Ford"}`))
+		input := rest.Input{r, rest.ParameterCollection{}, rest.RequestBody{}, encdec.JSONDecoder{}}
+		_, err := input.DecodeBody()
+		assertEqualError(t, err, rest.ErrorRequestBodyNotDefined)
+	})
+}
@@ -0,0 +1,350 @@
+package rest
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/hmac"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"io/ioutil"
+	"math/big"
+	"net/http"
+	"reflect"
+	"strings"
+	"time"
+)
+
+// BearerSecurityType is the bearer token authentication security scheme, with a JSON Web Token (RFC 6750).
+const BearerSecurityType = "bearer"
+
+const (
+	// HS256 is the HMAC using SHA-256 JWT signing algorithm, with a []byte key.
+	HS256 = "HS256"
+	// RS256 is the RSASSA-PKCS1-v1_5 using SHA-256 JWT signing algorithm, with a *rsa.PublicKey key.
+	RS256 = "RS256"
+	// ES256 is the ECDSA using P-256 and SHA-256 JWT signing algorithm, with a *ecdsa.PublicKey key.
+	ES256 = "ES256"
+)
+
+// JWK is a key to verify the signature of a JWT.
+// Key is a []byte secret for HS256, a *rsa.PublicKey for RS256 or a *ecdsa.PublicKey for ES256.
+// If KeyID is set, the key only verifies the tokens with the same `kid` header,
+// and if Algorithm is set, it only verifies the tokens signed with that algorithm.
+type JWK struct {
+	KeyID     string
+	Algorithm string
+	Key       interface{}
+}
+
+// JWTClaims are the claims of the payload of a verified JWT.
+type JWTClaims map[string]interface{}
+
+// JWTVerifier is an Authenticator that verifies the JWT of the `Authorization: Bearer` header,
+// its signature with one of the Keys, and its `exp`, `nbf`, `iss` and `aud` claims.
+// The `iss` claim is only checked if Issuer is set, and the `aud` claim if Audience is set.
+// Leeway is the clock skew allowed checking the `exp` and `nbf` claims.
+type JWTVerifier struct {
+	Keys     []JWK
+	Issuer   string
+	Audience string
+	Leeway   time.Duration
+}
+
+// NewJWTVerifier returns a JWTVerifier with the keys.
+func NewJWTVerifier(keys ...JWK) *JWTVerifier {
+	return &JWTVerifier{Keys: keys}
+}
+
+// NewBearerSecurityScheme creates a new security scheme of BearerSecurityType type that verifies the bearer token with the verifier,
+// responding with a 401 (Unauthorized) response when the token is missing or invalid.
+func NewBearerSecurityScheme(name string, verifier *JWTVerifier) *SecurityScheme {
+	s := NewSecurityScheme(name, BearerSecurityType, SecurityOperation{
+		Authenticator:                verifier,
+		FailedAuthenticationResponse: NewResponse(http.StatusUnauthorized).WithDescription("The bearer token is missing or invalid"),
+		FailedAuthorizationResponse:  NewResponse(http.StatusForbidden).WithDescription("The bearer token doesn't grant access to the resource"),
+	})
+	s.Parameter = NewHeaderParameter("Authorization", reflect.String)
+
+	return s
+}
+
+// Authenticate verifies the bearer token of the request, returning an ErrorAuthentication if it is missing or invalid.
+func (v *JWTVerifier) Authenticate(i Input) AuthError {
+	if _, err := v.Claims(i); err != nil {
+		return ErrorAuthentication{err.Error()}
+	}
+
+	return nil
+}
+
+// AuthenticateScopes verifies the bearer token of the request like Authenticate, and returns the granted scopes of its claims.
+func (v *JWTVerifier) AuthenticateScopes(i Input) ([]string, AuthError) {
+	claims, err := v.Claims(i)
+	if err != nil {
+		return nil, ErrorAuthentication{err.Error()}
+	}
+
+	return claims.Scopes(), nil
+}
+
+// Claims returns the claims of the verified bearer token of the request, so an Operation can get them.
+func (v *JWTVerifier) Claims(i Input) (JWTClaims, error) {
+	authorization := i.Request.Header.Get("Authorization")
+	if len(authorization) < 7 || !strings.EqualFold(authorization[:7], "Bearer ") {
+		return nil, &ErrorInvalidJWT{"the Authorization header has no bearer token"}
+	}
+
+	return v.Verify(strings.TrimSpace(authorization[7:]))
+}
+
+// Verify verifies the signature and the claims of the token, and returns its claims.
+func (v *JWTVerifier) Verify(token string) (JWTClaims, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, &ErrorInvalidJWT{"the token is malformed"}
+	}
+
+	header := struct {
+		Algorithm string `json:"alg"`
+		KeyID     string `json:"kid"`
+	}{}
+
+	if err := decodeJWTSegment(parts[0], &header); err != nil {
+		return nil, &ErrorInvalidJWT{"the token header is malformed"}
+	}
+
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, &ErrorInvalidJWT{"the token signature is malformed"}
+	}
+
+	if !v.verifySignature(header.Algorithm, header.KeyID, []byte(parts[0]+"."+parts[1]), signature) {
+		return nil, &ErrorInvalidJWT{"the token signature is invalid"}
+	}
+
+	claims := JWTClaims{}
+	if err := decodeJWTSegment(parts[1], &claims); err != nil {
+		return nil, &ErrorInvalidJWT{"the token payload is malformed"}
+	}
+
+	if err := v.verifyClaims(claims); err != nil {
+		return nil, err
+	}
+
+	return claims, nil
+}
+
+func decodeJWTSegment(segment string, v interface{}) error {
+	b, err := base64.RawURLEncoding.DecodeString(segment)
+	if err != nil {
+		return err
+	}
+
+	return json.Unmarshal(b, v)
+}
+
+// verifySignature reports whether one of the keys for the algorithm and key id verifies the signature.
+func (v *JWTVerifier) verifySignature(algorithm, keyID string, signingInput, signature []byte) bool {
+	hash := sha256.Sum256(signingInput)
+
+	for _, jwk := range v.Keys {
+		if jwk.KeyID != "" && keyID != "" && jwk.KeyID != keyID {
+			continue
+		}
+
+		if jwk.Algorithm != "" && jwk.Algorithm != algorithm {
+			continue
+		}
+
+		switch key := jwk.Key.(type) {
+		case []byte:
+			if algorithm != HS256 {
+				continue
+			}
+
+			mac := hmac.New(sha256.New, key)
+			mac.Write(signingInput)
+
+			if hmac.Equal(mac.Sum(nil), signature) {
+				return true
+			}
+		case *rsa.PublicKey:
+			if algorithm == RS256 && rsa.VerifyPKCS1v15(key, crypto.SHA256, hash[:], signature) == nil {
+				return true
+			}
+		case *ecdsa.PublicKey:
+			if algorithm != ES256 || key.Curve != elliptic.P256() || len(signature) != 64 {
+				continue
+			}
+
+			r := new(big.Int).SetBytes(signature[:32])
+			s := new(big.Int).SetBytes(signature[32:])
+
+			if ecdsa.Verify(key, hash[:], r, s) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// verifyClaims checks the `exp`, `nbf`, `iss` and `aud` claims.
+func (v *JWTVerifier) verifyClaims(claims JWTClaims) error {
+	now := time.Now()
+
+	if exp, ok := claims["exp"]; ok {
+		seconds, ok := exp.(float64)
+		if !ok {
+			return &ErrorInvalidJWT{"the exp claim is not a number"}
+		}
+
+		if now.After(time.Unix(int64(seconds), 0).Add(v.Leeway)) {
+			return &ErrorInvalidJWT{"the token is expired"}
+		}
+	}
+
+	if nbf, ok := claims["nbf"]; ok {
+		seconds, ok := nbf.(float64)
+		if !ok {
+			return &ErrorInvalidJWT{"the nbf claim is not a number"}
+		}
+
+		if now.Add(v.Leeway).Before(time.Unix(int64(seconds), 0)) {
+			return &ErrorInvalidJWT{"the token is not valid yet"}
+		}
+	}
+
+	if v.Issuer != "" && claims["iss"] != v.Issuer {
+		return &ErrorInvalidJWT{"the token issuer is not accepted"}
+	}
+
+	if v.Audience != "" && !claims.hasAudience(v.Audience) {
+		return &ErrorInvalidJWT{"the token audience is not accepted"}
+	}
+
+	return nil
+}
+
+// Scopes returns the granted scopes of the space-delimited `scope` claim (RFC 8693), or of the `scp` claim, a string or an array of strings.
+func (c JWTClaims) Scopes() []string {
+	scopes := []string{}
+
+	switch scp := c["scp"].(type) {
+	case string:
+		scopes = append(scopes, strings.Fields(scp)...)
+	case []interface{}:
+		for _, s := range scp {
+			if scope, ok := s.(string); ok {
+				scopes = append(scopes, scope)
+			}
+		}
+	}
+
+	if scope, ok := c["scope"].(string); ok {
+		scopes = append(scopes, strings.Fields(scope)...)
+	}
+
+	return scopes
+}
+
+// hasAudience reports whether the `aud` claim, a string or an array of strings, contains the audience.
+func (c JWTClaims) hasAudience(audience string) bool {
+	switch aud := c["aud"].(type) {
+	case string:
+		return aud == audience
+	case []interface{}:
+		for _, a := range aud {
+			if a == audience {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// LoadJWKS reads a JSON Web Key Set file (RFC 7517), and returns its keys.
+func LoadJWKS(path string) ([]JWK, error) {
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	return ParseJWKS(b)
+}
+
+// ParseJWKS parses a JSON Web Key Set (RFC 7517), and returns its RSA, P-256 EC and symmetric keys.
+// The keys of other types or curves, and the encryption keys are ignored.
+func ParseJWKS(b []byte) ([]JWK, error) {
+	set := struct {
+		Keys []struct {
+			Kty string `json:"kty"`
+			Kid string `json:"kid"`
+			Alg string `json:"alg"`
+			Use string `json:"use"`
+			Crv string `json:"crv"`
+			N   string `json:"n"`
+			E   string `json:"e"`
+			X   string `json:"x"`
+			Y   string `json:"y"`
+			K   string `json:"k"`
+		} `json:"keys"`
+	}{}
+
+	if err := json.Unmarshal(b, &set); err != nil {
+		return nil, err
+	}
+
+	keys := []JWK{}
+
+	for _, k := range set.Keys {
+		if k.Use == "enc" {
+			continue
+		}
+
+		jwk := JWK{KeyID: k.Kid, Algorithm: k.Alg}
+
+		switch k.Kty {
+		case "oct":
+			key, err := base64.RawURLEncoding.DecodeString(k.K)
+			if err != nil {
+				return nil, &ErrorInvalidJWK{k.Kid}
+			}
+
+			jwk.Key = key
+		case "RSA":
+			n, errN := base64.RawURLEncoding.DecodeString(k.N)
+			e, errE := base64.RawURLEncoding.DecodeString(k.E)
+
+			if errN != nil || errE != nil || len(e) == 0 || len(e) > 4 {
+				return nil, &ErrorInvalidJWK{k.Kid}
+			}
+
+			jwk.Key = &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: int(new(big.Int).SetBytes(e).Int64())}
+		case "EC":
+			if k.Crv != "P-256" {
+				continue
+			}
+
+			x, errX := base64.RawURLEncoding.DecodeString(k.X)
+			y, errY := base64.RawURLEncoding.DecodeString(k.Y)
+			key := &ecdsa.PublicKey{Curve: elliptic.P256(), X: new(big.Int).SetBytes(x), Y: new(big.Int).SetBytes(y)}
+
+			if errX != nil || errY != nil || !key.Curve.IsOnCurve(key.X, key.Y) {
+				return nil, &ErrorInvalidJWK{k.Kid}
+			}
+
+			jwk.Key = key
+		default:
+			continue
+		}
+
+		keys = append(keys, jwk)
+	}
+
+	return keys, nil
+}
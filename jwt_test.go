@@ -0,0 +1,222 @@
+package rest_test
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ehsoc/rest"
+)
+
+var (
+	hmacKey     = []byte("secret")
+	rsaKey, _   = rsa.GenerateKey(rand.Reader, 2048)
+	ecdsaKey, _ = ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+)
+
+func signJWT(t *testing.T, algorithm, keyID string, claims map[string]interface{}) string {
+	t.Helper()
+	header, _ := json.Marshal(map[string]string{"alg": algorithm, "typ": "JWT", "kid": keyID})
+	payload, _ := json.Marshal(claims)
+	signingInput := base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(payload)
+	hash := sha256.Sum256([]byte(signingInput))
+	var signature []byte
+	switch algorithm {
+	case rest.HS256:
+		mac := hmac.New(sha256.New, hmacKey)
+		mac.Write([]byte(signingInput))
+		signature = mac.Sum(nil)
+	case rest.RS256:
+		var err error
+		signature, err = rsa.SignPKCS1v15(rand.Reader, rsaKey, crypto.SHA256, hash[:])
+		assertNoErrorFatal(t, err)
+	case rest.ES256:
+		r, s, err := ecdsa.Sign(rand.Reader, ecdsaKey, hash[:])
+		assertNoErrorFatal(t, err)
+		signature = make([]byte, 64)
+		r.FillBytes(signature[:32])
+		s.FillBytes(signature[32:])
+	}
+	return signingInput + "." + base64.RawURLEncoding.EncodeToString(signature)
+}
+
+func TestJWTVerifier(t *testing.T) {
+	verifier := rest.NewJWTVerifier(
+		rest.JWK{Key: hmacKey},
+		rest.JWK{KeyID: "rsa", Key: &rsaKey.PublicKey},
+		rest.JWK{KeyID: "ec", Algorithm: rest.ES256, Key: &ecdsaKey.PublicKey},
+	)
+	verifier.Issuer = "https://issuer.example"
+	verifier.Audience = "cars"
+	verifier.Leeway = time.Minute
+	now := time.Now().Unix()
+	claims := func(overrides map[string]interface{}) map[string]interface{} {
+		c := map[string]interface{}{"sub": "1", "iss": "https://issuer.example", "aud": "cars", "exp": now + 60}
+		for k, v := range overrides {
+			if v == nil {
+				delete(c, k)
+				continue
+			}
+			c[k] = v
+		}
+		return c
+	}
+	tt := []struct {
+		name      string
+		algorithm string
+		keyID     string
+		claims    map[string]interface{}
+		wantErr   string
+	}{
+		{"HS256", rest.HS256, "", claims(nil), ""},
+		{"RS256", rest.RS256, "rsa", claims(nil), ""},
+		{"ES256", rest.ES256, "ec", claims(nil), ""},
+		{"RS256 without key id", rest.RS256, "", claims(nil), ""},
+		{"unknown key id", rest.RS256, "other", claims(nil), "the token signature is invalid"},
+		{"key of another algorithm", rest.ES256, "rsa", claims(nil), "the token signature is invalid"},
+		{"none algorithm", "none", "", claims(nil), "the token signature is invalid"},
+		{"audience array", rest.HS256, "", claims(map[string]interface{}{"aud": []string{"trucks", "cars"}}), ""},
+		{"no exp", rest.HS256, "", claims(map[string]interface{}{"exp": nil}), ""},
+		{"expired", rest.HS256, "", claims(map[string]interface{}{"exp": now - 120}), "the token is expired"},
+		{"expired within leeway", rest.HS256, "", claims(map[string]interface{}{"exp": now - 30}), ""},
+		{"not valid yet", rest.HS256, "", claims(map[string]interface{}{"nbf": now + 120}), "the token is not valid yet"},
+		{"invalid exp", rest.HS256, "", claims(map[string]interface{}{"exp": "tomorrow"}), "the exp claim is not a number"},
+		{"invalid issuer", rest.HS256, "", claims(map[string]interface{}{"iss": "https://other.example"}), "the token issuer is not accepted"},
+		{"invalid audience", rest.HS256, "", claims(map[string]interface{}{"aud": "trucks"}), "the token audience is not accepted"},
+		{"no audience", rest.HS256, "", claims(map[string]interface{}{"aud": nil}), "the token audience is not accepted"},
+	}
+	for _, test := range tt {
+		t.Run(test.name, func(t *testing.T) {
+			got, err := verifier.Verify(signJWT(t, test.algorithm, test.keyID, test.claims))
+			if test.wantErr == "" {
+				assertNoErrorFatal(t, err)
+				assertStringEqual(t, got["sub"].(string), "1")
+				return
+			}
+			if err == nil {
+				t.Fatalf("expecting error")
+			}
+			assertStringEqual(t, err.Error(), fmt.Sprintf("rest: invalid JWT: %s", test.wantErr))
+		})
+	}
+	t.Run("tampered payload", func(t *testing.T) {
+		token := signJWT(t, rest.HS256, "", claims(nil))
+		other := signJWT(t, rest.HS256, "", claims(map[string]interface{}{"sub": "2"}))
+		parts, otherParts := strings.Split(token, "."), strings.Split(other, ".")
+		_, err := verifier.Verify(parts[0] + "." + otherParts[1] + "." + parts[2])
+		assertTrue(t, err != nil)
+	})
+	t.Run("malformed", func(t *testing.T) {
+		_, err := verifier.Verify("abc")
+		assertStringEqual(t, err.Error(), "rest: invalid JWT: the token is malformed")
+	})
+}
+
+func TestNewBearerSecurityScheme(t *testing.T) {
+	scheme := rest.NewBearerSecurityScheme("bearer", rest.NewJWTVerifier(rest.JWK{Key: hmacKey}))
+	assertStringEqual(t, scheme.Type, rest.BearerSecurityType)
+	assertStringEqual(t, scheme.Parameter.Name, "Authorization")
+	m := rest.NewMethod("GET", moTest, mustGetJSONContentType()).WithSecurity(scheme)
+	token := signJWT(t, rest.HS256, "", map[string]interface{}{"sub": "1"})
+	tt := []struct {
+		authorization string
+		wantCode      int
+	}{
+		{"Bearer " + token, 200},
+		{"bearer " + token, 200},
+		{"Bearer " + token + "x", 401},
+		{"Basic dXNlcjpwYXNz", 401},
+		{"", 401},
+	}
+	for _, test := range tt {
+		t.Run(test.authorization, func(t *testing.T) {
+			req, _ := http.NewRequest("GET", "/", nil)
+			req.Header.Set("Authorization", test.authorization)
+			resp := httptest.NewRecorder()
+			m.ServeHTTP(resp, req)
+			assertResponseCode(t, resp, test.wantCode)
+		})
+	}
+}
+
+func TestLoadJWKS(t *testing.T) {
+	encode := func(b []byte) string {
+		return base64.RawURLEncoding.EncodeToString(b)
+	}
+	jwks := map[string]interface{}{
+		"keys": []map[string]string{
+			{"kty": "RSA", "kid": "rsa", "alg": "RS256", "use": "sig", "n": encode(rsaKey.N.Bytes()), "e": encode(big.NewInt(int64(rsaKey.E)).Bytes())},
+			{"kty": "EC", "kid": "ec", "crv": "P-256", "x": encode(ecdsaKey.X.Bytes()), "y": encode(ecdsaKey.Y.Bytes())},
+			{"kty": "oct", "kid": "hmac", "k": encode(hmacKey)},
+			{"kty": "RSA", "kid": "enc", "use": "enc", "n": encode(rsaKey.N.Bytes()), "e": "AQAB"},
+			{"kty": "OKP", "kid": "ed25519", "crv": "Ed25519", "x": "AA"},
+		},
+	}
+	file, err := ioutil.TempFile("", "jwks*.json")
+	assertNoErrorFatal(t, err)
+	defer os.Remove(file.Name())
+	json.NewEncoder(file).Encode(jwks)
+	file.Close()
+	keys, err := rest.LoadJWKS(file.Name())
+	assertNoErrorFatal(t, err)
+	if len(keys) != 3 {
+		t.Fatalf("got %d keys, want 3", len(keys))
+	}
+	verifier := rest.NewJWTVerifier(keys...)
+	for _, test := range []struct{ algorithm, keyID string }{{rest.RS256, "rsa"}, {rest.ES256, "ec"}, {rest.HS256, "hmac"}} {
+		_, err := verifier.Verify(signJWT(t, test.algorithm, test.keyID, map[string]interface{}{"sub": "1"}))
+		assertNoErrorFatal(t, err)
+	}
+	t.Run("malformed key", func(t *testing.T) {
+		_, err := rest.ParseJWKS([]byte(`{"keys":[{"kty":"EC","kid":"ec","crv":"P-256","x":"AA","y":"AA"}]}`))
+		assertStringEqual(t, err.Error(), "rest: the JWK 'ec' of the key set is malformed")
+	})
+	t.Run("missing file", func(t *testing.T) {
+		_, err := rest.LoadJWKS(file.Name() + ".missing")
+		assertTrue(t, err != nil)
+	})
+}
+
+func TestJWTClaimsScopes(t *testing.T) {
+	tt := []struct {
+		name   string
+		claims rest.JWTClaims
+		want   []string
+	}{
+		{"scope", rest.JWTClaims{"scope": "read:cars write:cars"}, []string{"read:cars", "write:cars"}},
+		{"scp array", rest.JWTClaims{"scp": []interface{}{"read:cars", "write:cars"}}, []string{"read:cars", "write:cars"}},
+		{"scp string", rest.JWTClaims{"scp": "read:cars"}, []string{"read:cars"}},
+		{"no scopes", rest.JWTClaims{}, []string{}},
+	}
+	for _, test := range tt {
+		t.Run(test.name, func(t *testing.T) {
+			assertStringSlice(t, test.claims.Scopes(), test.want)
+		})
+	}
+	t.Run("security scopes", func(t *testing.T) {
+		scheme := rest.NewBearerSecurityScheme("bearer", rest.NewJWTVerifier(rest.JWK{Key: hmacKey}))
+		m := rest.NewMethod("GET", moTest, mustGetJSONContentType()).WithSecurityScopes(scheme, "read:cars")
+		for scope, wantCode := range map[string]int{"read:cars": 200, "write:cars": 403} {
+			req, _ := http.NewRequest("GET", "/", nil)
+			req.Header.Set("Authorization", "Bearer "+signJWT(t, rest.HS256, "", map[string]interface{}{"scope": scope}))
+			resp := httptest.NewRecorder()
+			m.ServeHTTP(resp, req)
+			assertResponseCode(t, resp, wantCode)
+		}
+	})
+}
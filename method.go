@@ -1,10 +1,14 @@
 package rest
 
 import (
+	"bytes"
 	"context"
+	"errors"
 	"fmt"
 	"net/http"
+	"reflect"
 	"sort"
+	"strings"
 
 	"github.com/ehsoc/rest/encdec"
 )
@@ -21,7 +25,25 @@ type Method struct {
 	SecurityCollection []Security
 	http.Handler
 	ParameterCollection
-	validation     Validation
+	validation Validation
+	// parameterConstraintsResponse is the response when the request doesn't satisfy the declared parameter constraints.
+	parameterConstraintsResponse Response
+	strictRequestBody            bool
+	strictRequestBodyResponse    Response
+	// errorMappings maps the Operation errors to responses
+	errorMappings errorMappingCollection
+	// problemDetails enables the ProblemDetails body for the error responses without a body.
+	problemDetails bool
+	// corsPolicy is applied as the outermost middleware when it is not nil.
+	corsPolicy *CORSPolicy
+	// contentEncodings are the content codings of the responses and request bodies, nil means no content codings.
+	contentEncodings *ContentEncodings
+	// etag enables the ETag header of the successful GET and HEAD responses, nil means disabled.
+	etag *etagOptions
+	// ifMatch enables the If-Match precondition, nil means disabled.
+	ifMatch *ifMatchOptions
+	// cachePolicy is the policy of the Cache-Control header of the successful responses, nil means no header.
+	cachePolicy    *CachePolicy
 	negotiationMw  Middleware
 	securityMw     Middleware
 	validationMw   Middleware
@@ -37,6 +59,7 @@ func NewMethod(httpMethod string, methodOperation MethodOperation, contentTypes
 		contentTypes:    contentTypes,
 		Negotiator:      DefaultNegotiator{},
 	}
+	m.parameterConstraintsResponse = newParameterConstraintsResponse()
 	m.parameters = make(map[ParameterType]map[string]Parameter)
 	m.negotiationMw = m.negotiationMiddleware
 	m.securityMw = m.securityMiddleware
@@ -69,14 +92,23 @@ func (m *Method) buildHandler() {
 			m.Handler = m.middleware[i](m.Handler)
 		}
 	}
+	// apply the content codings to any response body
+	m.Handler = m.contentEncodingMiddleware(m.Handler)
+	// apply the CORS policy, so the headers are set for any response
+	if m.corsPolicy != nil {
+		m.Handler = m.corsMiddleware(m.Handler)
+	}
 }
 
 func (m *Method) negotiationMiddleware(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		responseContentType, encoder, err := m.Negotiator.NegotiateEncoder(r, &m.contentTypes)
 		if err != nil {
-			mutateResponseBody(&m.contentTypes.UnsupportedMediaTypeResponse, nil, false, err)
-			m.writeResponseFallBack(w, m.contentTypes.UnsupportedMediaTypeResponse)
+			resp := m.contentTypes.UnsupportedMediaTypeResponse
+			if errors.As(err, new(*ErrorNotAcceptable)) {
+				resp = m.contentTypes.NotAcceptableResponse
+			}
+			m.writeResponseFallBack(w, r, m.problemResponse(resp).render(nil, false, err))
 			return
 		}
 		ctx := context.WithValue(r.Context(), EncoderDecoderContextKey("encoder"), encoder)
@@ -84,11 +116,16 @@ func (m *Method) negotiationMiddleware(next http.Handler) http.Handler {
 		decoderContentType, decoder, err := m.Negotiator.NegotiateDecoder(r, &m.contentTypes)
 		ctx = context.WithValue(ctx, ContentTypeContextKey("decoder"), decoderContentType)
 		if err != nil && r.Body != http.NoBody && r.Body != nil {
-			mutateResponseBody(&m.contentTypes.UnsupportedMediaTypeResponse, nil, false, err)
-			writeResponse(ctx, w, m.contentTypes.UnsupportedMediaTypeResponse)
+			writeResponse(w, r.WithContext(ctx), m.problemResponse(m.contentTypes.UnsupportedMediaTypeResponse).render(nil, false, err))
+			return
+		}
+		if m.contentTypes.BodyRequired() && (r.Body == http.NoBody || r.Body == nil) {
+			writeResponse(w, r.WithContext(ctx), m.problemResponse(m.contentTypes.MissingRequestBodyResponse).render(nil, false, ErrorRequestBodyMissing))
 			return
 		}
 		ctx = context.WithValue(ctx, EncoderDecoderContextKey("decoder"), decoder)
+		ctx = context.WithValue(ctx, InputContextKey("body"), &requestBodyCache{strict: m.strictRequestBody})
+		ctx = context.WithValue(ctx, InputContextKey("responseheader"), w.Header())
 		w.Header().Add("Content-Type", responseContentType)
 		next.ServeHTTP(w, r.WithContext(ctx))
 	})
@@ -104,10 +141,13 @@ func (m *Method) securityMiddleware(next http.Handler) http.Handler {
 
 			var securityFailedResponse Response
 
+			var securityErr error
+
 			for _, s := range m.SecurityCollection {
 				resp, err := processSecurity(s, input)
 				if err != nil {
 					securityFailedResponse = resp
+					securityErr = err
 					continue
 				}
 
@@ -117,7 +157,7 @@ func (m *Method) securityMiddleware(next http.Handler) http.Handler {
 			}
 
 			if !passSecurity {
-				writeResponse(r.Context(), w, securityFailedResponse)
+				writeResponse(w, r, m.problemResponse(securityFailedResponse).render(nil, false, securityErr))
 				return
 			}
 		}
@@ -129,13 +169,31 @@ func (m *Method) validationMiddleware(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		decoder := mustGetDecoder(r.Context())
 		input := Input{r, m.ParameterCollection, m.RequestBody, decoder}
+		// Declared parameter constraints
+		err := m.checkParameterConstraints(r)
+		if err != nil {
+			writeResponse(w, r, m.problemResponse(m.parameterConstraintsResponse).render(nil, false, err))
+			return
+		}
+		// Strict request body
+		if m.strictRequestBody && m.RequestBody.Body != nil {
+			_, err := input.DecodeBody()
+			if err != nil {
+				response, tooLarge := m.decodedBodyTooLargeResponse(err)
+				if !tooLarge {
+					response = m.strictRequestBodyResponse
+				}
+
+				writeResponse(w, r, m.problemResponse(response).render(nil, false, err))
+				return
+			}
+		}
 		// Validation
 		// Method validation
 		if m.validation.Validator != nil {
 			err := m.validation.Validate(input)
 			if err != nil {
-				mutateResponseBody(&m.validation.Response, nil, false, err)
-				writeResponse(r.Context(), w, m.validation.Response)
+				writeResponse(w, r, m.problemResponse(m.validation.Response).render(nil, false, err))
 
 				return
 			}
@@ -145,8 +203,7 @@ func (m *Method) validationMiddleware(next http.Handler) http.Handler {
 			if p.validation.Validator != nil && p.validation.Response.code != 0 {
 				err := p.validation.Validate(input)
 				if err != nil {
-					mutateResponseBody(&p.validation.Response, nil, false, err)
-					writeResponse(r.Context(), w, p.validation.Response)
+					writeResponse(w, r, m.problemResponse(p.validation.Response).render(nil, false, err))
 					return
 				}
 			}
@@ -155,15 +212,15 @@ func (m *Method) validationMiddleware(next http.Handler) http.Handler {
 	})
 }
 
-func (m *Method) writeResponseFallBack(w http.ResponseWriter, response Response) {
+func (m *Method) writeResponseFallBack(w http.ResponseWriter, r *http.Request, response Response) {
 	_, encoder, err := m.contentTypes.GetDefaultEncoder()
-	// if no default encdec is set will only return the header code
-	if err != nil {
+	// if no default encdec is set will only return the header code, unless is a problem details response
+	if _, ok := response.MutableResponseBody.(*ProblemDetails); err != nil && !ok {
 		w.WriteHeader(response.Code())
 		return
 	}
 
-	write(w, encoder, response)
+	write(w, r, encoder, response)
 }
 
 func (m *Method) mainHandler(w http.ResponseWriter, r *http.Request) {
@@ -173,32 +230,86 @@ func (m *Method) mainHandler(w http.ResponseWriter, r *http.Request) {
 	}
 	input := Input{r, m.ParameterCollection, m.RequestBody, decoder}
 
+	// Precondition
+	if m.ifMatch != nil && !m.checkIfMatch(w, r, input) {
+		return
+	}
+
 	// Operation
 	entity, success, err := m.MethodOperation.Execute(input)
 	if err != nil {
-		errResponse := NewResponse(500)
-		mutateResponseBody(&errResponse, entity, success, err)
-		writeResponse(r.Context(), w, errResponse)
+		m.writeOperationError(w, r, entity, success, err)
+		return
+	}
+
+	if outcome, ok := entity.(Outcome); ok {
+		response, ok := m.MethodOperation.outcomeResponse(outcome)
+		if !ok {
+			panic(&ErrorOutcomeNotDefined{r.URL.Path + " " + m.HTTPMethod, outcome.Key, outcome.Code})
+		}
+
+		m.writeOperationResponse(w, r, m.problemResponse(response).render(outcome.Body, success, err))
 		return
 	}
 
 	if !success {
-		if m.MethodOperation.failResponse.disabled {
+		failResponse, ok := m.MethodOperation.response(FailOutcome)
+		if !ok {
 			panic(&ErrorFailResponseNotDefined{r.URL.Path + " " + m.HTTPMethod})
 		}
 
-		mutateResponseBody(&m.MethodOperation.failResponse, entity, success, err)
-		writeResponse(r.Context(), w, m.MethodOperation.failResponse)
+		writeResponse(w, r, m.problemResponse(failResponse).render(entity, success, err))
 		return
 	}
 
-	mutateResponseBody(&m.MethodOperation.successResponse, entity, success, err)
-	writeResponse(r.Context(), w, m.MethodOperation.successResponse)
+	successResponse, _ := m.MethodOperation.response(SuccessOutcome)
+	m.writeOperationResponse(w, r, successResponse.render(entity, success, err))
+}
+
+// writeOperationError writes the response of an error returned by the Operation, or by a function called before it like an ETagFunc:
+// the response of the matching error mapping, the parameter constraints response for a parameter parse error,
+// the If-Match precondition failed response for an ErrorPreconditionFailed error, a 400 (Bad Request) response for a request body that can't be decoded, or a 500 (Internal Server Error) response.
+func (m *Method) writeOperationError(w http.ResponseWriter, r *http.Request, entity interface{}, success bool, err error) {
+	if response, ok := m.errorMappings.response(err); ok {
+		writeResponse(w, r, m.problemResponse(response).render(entity, success, err))
+		return
+	}
+
+	var parseErr *ErrorParameterParse
+	if errors.As(err, &parseErr) {
+		writeResponse(w, r, m.problemResponse(m.parameterConstraintsResponse).render(entity, success, err))
+		return
+	}
+
+	var preconditionErr *ErrorPreconditionFailed
+	if m.ifMatch != nil && errors.As(err, &preconditionErr) {
+		writeResponse(w, r, m.problemResponse(m.ifMatch.preconditionFailedResponse).render(entity, success, err))
+		return
+	}
+
+	if response, ok := m.decodedBodyTooLargeResponse(err); ok {
+		writeResponse(w, r, m.problemResponse(response).render(entity, success, err))
+		return
+	}
+
+	var decodeErr *ErrorRequestBodyDecode
+	var requiredFieldsErr *ErrorRequestBodyRequiredFields
+	if errors.As(err, &decodeErr) || errors.As(err, &requiredFieldsErr) {
+		response := NewResponse(http.StatusBadRequest)
+		if m.strictRequestBody {
+			response = m.strictRequestBodyResponse
+		}
+
+		writeResponse(w, r, m.problemResponse(response).render(entity, success, err))
+		return
+	}
+
+	writeResponse(w, r, m.problemResponse(NewResponse(500)).render(entity, success, err))
 }
 
 func processSecurity(s Security, input Input) (Response, error) {
 	for _, ss := range s.SecuritySchemes {
-		response, err := processSecurityScheme(ss, input)
+		response, err := processSecurityScheme(ss, s.Scopes[ss.Name], input)
 		if err != nil {
 			return response, err
 		}
@@ -216,7 +327,10 @@ func mustGetDecoder(ctx context.Context) encdec.Decoder {
 	return decoder
 }
 
-func processSecurityScheme(ss *SecurityScheme, input Input) (Response, error) {
+func processSecurityScheme(ss *SecurityScheme, scopes []string, input Input) (Response, error) {
+	if len(scopes) > 0 {
+		return processScopes(ss, scopes, input)
+	}
 	err := ss.Authenticate(input)
 	if err != nil {
 		if authErr, ok := err.(AuthError); ok {
@@ -229,27 +343,179 @@ func processSecurityScheme(ss *SecurityScheme, input Input) (Response, error) {
 	return Response{}, nil
 }
 
-func writeResponse(ctx context.Context, w http.ResponseWriter, resp Response) {
-	encoder, ok := ctx.Value(EncoderDecoderContextKey("encoder")).(encdec.Encoder)
+// processScopes authenticates the request with a ScopedAuthenticator, and checks that the required scopes are granted.
+// The scopes can't be checked with an Authenticator that doesn't return them, so the request is not authorized.
+func processScopes(ss *SecurityScheme, scopes []string, input Input) (Response, error) {
+	scopedAuthenticator, ok := ss.Authenticator.(ScopedAuthenticator)
 	if !ok {
+		return ss.FailedAuthorizationResponse, scopesNotGranted(scopes)
+	}
+
+	granted, authErr := scopedAuthenticator.AuthenticateScopes(input)
+	if authErr != nil {
+		if authErr.isAuthorization() {
+			return ss.FailedAuthorizationResponse, authErr
+		}
+		return ss.FailedAuthenticationResponse, authErr
+	}
+
+	missing := []string{}
+	for _, scope := range scopes {
+		if !containsString(granted, scope) {
+			missing = append(missing, scope)
+		}
+	}
+
+	if len(missing) > 0 {
+		return ss.FailedAuthorizationResponse, scopesNotGranted(missing)
+	}
+
+	return Response{}, nil
+}
+
+func scopesNotGranted(scopes []string) ErrorAuthorization {
+	return ErrorAuthorization{fmt.Sprintf(msgErrScopesNotGranted, strings.Join(scopes, ", "))}
+}
+
+func writeResponse(w http.ResponseWriter, r *http.Request, resp Response) {
+	encoder, ok := r.Context().Value(EncoderDecoderContextKey("encoder")).(encdec.Encoder)
+	// a problem details response doesn't need the negotiated encoder
+	if _, isProblem := resp.MutableResponseBody.(*ProblemDetails); !ok && !isProblem {
 		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
 		return
 	}
 
-	write(w, encoder, resp)
+	write(w, r, encoder, resp)
 }
 
-func mutateResponseBody(response *Response, entity interface{}, success bool, err error) {
-	if response.MutableResponseBody != nil {
-		response.MutableResponseBody.Mutate(entity, success, err)
+func write(w http.ResponseWriter, r *http.Request, encoder encdec.Encoder, resp Response) {
+	if problem, ok := resp.MutableResponseBody.(*ProblemDetails); ok {
+		problem.complete(resp.Code(), r)
+		w.Header().Set("Content-Type", ProblemDetailsMediaType)
+		encoder = encdec.JSONEncoder{}
+	}
+	if resp.Body() == nil {
+		w.WriteHeader(resp.Code())
+		return
+	}
+
+	// a streaming encoder writes the items of an iterator or channel body as they are produced,
+	// until the request is canceled, other encoders get all the items in a slice.
+	if contextEncoder, ok := encoder.(encdec.ContextEncoder); ok && encdec.IsStream(resp.Body()) {
+		sw := &streamWriter{ResponseWriter: w, code: resp.Code()}
+		err := contextEncoder.EncodeContext(r.Context(), sw, resp.Body())
+		switch {
+		case err == nil:
+			sw.writeHeader()
+		case r.Context().Err() != nil:
+			// the request was canceled, there is no client to respond to
+		case !sw.wroteHeader:
+			http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		default:
+			// the status code was already sent, so the connection is aborted to let the client know
+			// that the body is incomplete
+			panic(http.ErrAbortHandler)
+		}
+
+		return
+	}
+
+	// the body is encoded before writing the status code, so an encoding error can still be a 500
+	buf := new(bytes.Buffer)
+	body, err := collectStream(r.Context(), resp.Body())
+	if err == nil {
+		err = encoder.Encode(buf, body)
+	}
+	if err != nil {
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
 	}
-}
 
-func write(w http.ResponseWriter, encoder encdec.Encoder, resp Response) {
 	w.WriteHeader(resp.Code())
-	if resp.Body() != nil {
-		encoder.Encode(w, resp.Body())
+	w.Write(buf.Bytes())
+}
+
+// streamWriter writes the status code on the first write or flush of a streaming encoder,
+// so an encoding error before writing any item can still be a 500 response.
+type streamWriter struct {
+	http.ResponseWriter
+	code        int
+	wroteHeader bool
+}
+
+func (s *streamWriter) writeHeader() {
+	if !s.wroteHeader {
+		s.wroteHeader = true
+		s.ResponseWriter.WriteHeader(s.code)
+	}
+}
+
+func (s *streamWriter) Write(b []byte) (int, error) {
+	s.writeHeader()
+	return s.ResponseWriter.Write(b)
+}
+
+// Flush implements http.Flusher, flushing the underlying writer if it is a flusher.
+func (s *streamWriter) Flush() {
+	s.writeHeader()
+
+	if flusher, ok := s.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+// collectStream returns a slice with the items of an encdec.Iterator or channel body, or the body itself.
+// The slice element type is the channel element type, or the type of the iterator items,
+// so the encoders that need a typed slice, like the encdec.CSVEncoder, can encode it.
+func collectStream(ctx context.Context, body interface{}) (interface{}, error) {
+	if !encdec.IsStream(body) {
+		return body, nil
+	}
+
+	var items reflect.Value
+
+	rv := reflect.ValueOf(body)
+	if rv.Kind() == reflect.Chan {
+		items = reflect.MakeSlice(reflect.SliceOf(rv.Type().Elem()), 0, 0)
+	}
+
+	_, err := encdec.EachItem(ctx, body, func(item interface{}) error {
+		itemValue := reflect.ValueOf(item)
+		if !items.IsValid() {
+			if item == nil {
+				items = reflect.MakeSlice(reflect.TypeOf([]interface{}{}), 0, 0)
+			} else {
+				items = reflect.MakeSlice(reflect.SliceOf(itemValue.Type()), 0, 0)
+			}
+		}
+
+		elemType := items.Type().Elem()
+		if item == nil {
+			itemValue = reflect.Zero(elemType)
+		}
+
+		// the iterator items of different types are collected in a []interface{}
+		if !itemValue.Type().AssignableTo(elemType) {
+			generic := reflect.MakeSlice(reflect.TypeOf([]interface{}{}), 0, items.Len()+1)
+			for i := 0; i < items.Len(); i++ {
+				generic = reflect.Append(generic, items.Index(i))
+			}
+			items = generic
+		}
+
+		items = reflect.Append(items, itemValue)
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if !items.IsValid() {
+		return []interface{}{}, nil
 	}
+
+	return items.Interface(), nil
 }
 
 // GetEncoderMediaTypes gets a string slice of the method's encoder media types
@@ -305,6 +571,34 @@ func (m *Method) WithValidation(v Validation) *Method {
 	return m
 }
 
+// WithParameterConstraintsResponse sets the response for a request that doesn't satisfy the declared parameter constraints,
+// and for an Operation that returns an ErrorParameterParse error.
+// The default response is a 400 (Bad Request) with a ParameterViolationsBody body listing every violation.
+func (m *Method) WithParameterConstraintsResponse(response Response) *Method {
+	m.parameterConstraintsResponse = response
+	return m
+}
+
+// WithStrictRequestBody enables the strict mode of the request body, in which the body is decoded before the operation,
+// and the given response is returned if the body can not be decoded, has fields that are not declared in the RequestBody type,
+// or a field tagged with `rest:"required"` is not present or is null. The fields present in the body are known if the decoder
+// can decode the body in a map, like the JSON and YAML ones, otherwise only a nil required pointer field is missing.
+// Unknown fields are rejected only if the negotiated decoder implements the encdec.StrictDecoder interface.
+func (m *Method) WithStrictRequestBody(response Response) *Method {
+	m.strictRequestBody = true
+	m.strictRequestBodyResponse = response
+	return m
+}
+
+// MapError maps the errors returned by the Operation that are matched by matcher to the given response,
+// instead of the default 500 (Internal Server Error) response.
+// The method mappings take precedence over the mappings inherited from the resources, and the last declared mapping
+// takes precedence over the previous ones.
+func (m *Method) MapError(matcher ErrorMatcher, response Response) *Method {
+	m.errorMappings = append(m.errorMappings, errorMapping{matcher, response})
+	return m
+}
+
 // OverwriteSecurityMiddleware replaces the core security middleware with the provided middleware for this method.
 func (m *Method) OverwriteCoreSecurityMiddleware(mid Middleware) *Method {
 	m.replaceSecurityMiddleware(mid)
@@ -327,14 +621,39 @@ func (m *Method) WithSecurity(s ...*SecurityScheme) *Method {
 	return m
 }
 
+// WithSecurityScopes adds a Security with the security scheme, requiring the scopes to be granted to the request.
+// The Authenticator of the scheme must be a ScopedAuthenticator returning the granted scopes,
+// and the FailedAuthorizationResponse is written when any of the scopes is not granted.
+// When more than one Security is defined it will follow an `or` logic with other Security definitions.
+func (m *Method) WithSecurityScopes(s *SecurityScheme, scopes ...string) *Method {
+	security := Security{SecuritySchemes: []*SecurityScheme{s}, Scopes: map[string][]string{s.Name: scopes}}
+	m.SecurityCollection = append(m.SecurityCollection, security)
+	return m
+}
+
 // Responses gets the response collection of the method.
 func (m *Method) Responses() []Response {
 	responses := make([]Response, 0)
-	if !m.MethodOperation.successResponse.disabled {
-		responses = append(responses, m.MethodOperation.successResponse)
+	// the outcomes with the same code are one response of the specification, with the body and headers
+	// of the first declared outcome, and the descriptions of all of them
+	descriptions := map[int][]string{}
+	for _, r := range m.MethodOperation.responses {
+		if r.response.disabled {
+			continue
+		}
+
+		code, description := r.response.code, r.response.description
+		if description != "" && !containsString(descriptions[code], description) {
+			descriptions[code] = append(descriptions[code], description)
+		}
+
+		if !hasResponseCode(responses, code) {
+			responses = append(responses, r.response)
+		}
 	}
-	if !m.MethodOperation.failResponse.disabled {
-		responses = append(responses, m.MethodOperation.failResponse)
+
+	for i := range responses {
+		responses[i].description = strings.Join(descriptions[responses[i].code], " or ")
 	}
 	if m.validation.Validator != nil && !m.validation.Response.disabled {
 		responses = append(responses, m.validation.Response)
@@ -345,5 +664,50 @@ func (m *Method) Responses() []Response {
 			responses = append(responses, p.validation.Response)
 		}
 	}
+
+	if m.hasParameterConstraints() && !hasResponseCode(responses, m.parameterConstraintsResponse.code) {
+		responses = append(responses, m.parameterConstraintsResponse)
+	}
+
+	if m.strictRequestBody && !hasResponseCode(responses, m.strictRequestBodyResponse.code) {
+		responses = append(responses, m.strictRequestBodyResponse)
+	}
+
+	if m.contentTypes.BodyRequired() && !hasResponseCode(responses, m.contentTypes.MissingRequestBodyResponse.code) {
+		responses = append(responses, m.contentTypes.MissingRequestBodyResponse)
+	}
+
+	// the last declared mapping takes precedence, so it goes first for the code
+	for i := len(m.errorMappings) - 1; i >= 0; i-- {
+		if !hasResponseCode(responses, m.errorMappings[i].response.code) {
+			responses = append(responses, m.errorMappings[i].response)
+		}
+	}
+
+	responses = m.contentEncodingResponses(responses)
+	responses = m.etagResponses(responses)
+	responses = m.cacheResponses(responses)
+
+	if m.problemDetails {
+		for _, resp := range m.problemResponses() {
+			if resp.code != 0 && !hasResponseCode(responses, resp.code) {
+				responses = append(responses, resp)
+			}
+		}
+
+		for i := range responses {
+			responses[i] = m.problemResponse(responses[i])
+		}
+	}
 	return responses
 }
+
+func hasResponseCode(responses []Response, code int) bool {
+	for _, r := range responses {
+		if r.code == code {
+			return true
+		}
+	}
+
+	return false
+}
@@ -1,15 +1,26 @@
 package rest
 
-// MethodOperation contains the operation, and its responses for success and failure.
+// Outcome keys of the responses declared with NewMethodOperation and WithFailResponse.
+const (
+	SuccessOutcome = "success"
+	FailOutcome    = "fail"
+)
+
+// MethodOperation contains the operation, and its responses for each outcome.
 // Operation Execute method will return a body (interface{}), success (bool), and err (error).
+// A success true value will select the SuccessOutcome response, and a false value the FailOutcome response,
+// unless the body is an Outcome, that selects the response by its key or code.
 type MethodOperation struct {
 	// Logic operation of the method
 	Operation
-	// Response if Operation Execute method function returns a success with true value.
-	successResponse Response
-	// Response if Operation Execute method function returns success with false value.
-	// Failure is not an error.
-	failResponse Response
+	// responses are the declared outcome responses, in declaration order.
+	responses []outcomeResponse
+}
+
+// outcomeResponse is a response declared for an outcome key.
+type outcomeResponse struct {
+	key      string
+	response Response
 }
 
 // NewMethodOperation returns a new MethodOperation instance.
@@ -20,11 +31,83 @@ type MethodOperation struct {
 // Please check if your operation returns a success false, if you don't define a failure response,
 // and your operation returns a success false, the HTTP Server could return a panic.
 func NewMethodOperation(operation Operation, successResponse Response) MethodOperation {
-	return MethodOperation{operation, successResponse, Response{disabled: true}}
+	return MethodOperation{operation, []outcomeResponse{{SuccessOutcome, successResponse}}}
 }
 
 // WithFailResponse sets the failResponse property
 func (m MethodOperation) WithFailResponse(failResponse Response) MethodOperation {
-	m.failResponse = failResponse
+	return m.WithResponse(FailOutcome, failResponse)
+}
+
+// WithResponse declares the response for the outcome key.
+// An Operation selects it returning an Outcome with the same key, or with the same response code.
+// If the key is already declared, the response will be replaced.
+// The responses of several keys can have the same code, they are listed by Method.Responses as a single response
+// with the body and headers of the first declared one, and the descriptions of all of them.
+func (m MethodOperation) WithResponse(key string, response Response) MethodOperation {
+	// copy on write, so copies of the MethodOperation don't share the responses
+	responses := make([]outcomeResponse, 0, len(m.responses)+1)
+	replaced := false
+
+	for _, r := range m.responses {
+		if r.key == key {
+			r.response = response
+			replaced = true
+		}
+
+		responses = append(responses, r)
+	}
+
+	if !replaced {
+		responses = append(responses, outcomeResponse{key, response})
+	}
+
+	m.responses = responses
+
 	return m
 }
+
+// response gets the response declared for the outcome key.
+func (m MethodOperation) response(key string) (Response, bool) {
+	for _, r := range m.responses {
+		if r.key == key && !r.response.disabled {
+			return r.response, true
+		}
+	}
+
+	return Response{}, false
+}
+
+// outcomeResponse gets the response selected by the outcome, by its key, or by its code if the key is empty.
+func (m MethodOperation) outcomeResponse(o Outcome) (Response, bool) {
+	if o.Key != "" {
+		return m.response(o.Key)
+	}
+
+	for _, r := range m.responses {
+		if r.response.code == o.Code && !r.response.disabled {
+			return r.response, true
+		}
+	}
+
+	return Response{}, false
+}
+
+// Outcome is a result of an Operation that selects one of the responses declared in the MethodOperation.
+// To select a response, return an Outcome as the body of the Operation Execute method,
+// the Outcome Body will be used as the operation result body.
+type Outcome struct {
+	Key  string
+	Code int
+	Body interface{}
+}
+
+// NewOutcome returns an Outcome that selects the response declared for the key.
+func NewOutcome(key string, body interface{}) Outcome {
+	return Outcome{Key: key, Body: body}
+}
+
+// NewStatusOutcome returns an Outcome that selects the first declared response with the code.
+func NewStatusOutcome(code int, body interface{}) Outcome {
+	return Outcome{Code: code, Body: body}
+}
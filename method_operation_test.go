@@ -1,6 +1,10 @@
 package rest_test
 
 import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
 	"testing"
 
 	"github.com/ehsoc/rest"
@@ -9,3 +13,115 @@ import (
 func TestNewMethodOperation(t *testing.T) {
 	rest.NewMethodOperation(&OperationStub{}, rest.NewResponse(0))
 }
+
+func TestOutcomes(t *testing.T) {
+	outcomeOperation := rest.OperationFunc(func(i rest.Input) (interface{}, bool, error) {
+		switch i.Request.URL.Query().Get("outcome") {
+		case "created":
+			return rest.NewOutcome("created", Car{ID: 1}), true, nil
+		case "accepted":
+			return rest.NewStatusOutcome(http.StatusAccepted, nil), true, nil
+		case "gone":
+			return rest.NewOutcome("gone", nil), false, nil
+		case "fail":
+			return nil, false, nil
+		case "undeclared":
+			return rest.NewOutcome("undeclared", nil), true, nil
+		}
+		return Car{ID: 2}, true, nil
+	})
+	mo := rest.NewMethodOperation(outcomeOperation, rest.NewResponse(200).WithOperationResultBody(Car{})).
+		WithFailResponse(rest.NewResponse(404)).
+		WithResponse("created", rest.NewResponse(201).WithOperationResultBody(Car{})).
+		WithResponse("accepted", rest.NewResponse(202)).
+		WithResponse("gone", rest.NewResponse(410))
+	m := rest.NewMethod("POST", mo, mustGetJSONContentType())
+	tt := []struct {
+		outcome  string
+		wantCode int
+		wantBody *Car
+	}{
+		{"", 200, &Car{ID: 2}},
+		{"created", 201, &Car{ID: 1}},
+		{"accepted", 202, nil},
+		{"gone", 410, nil},
+		{"fail", 404, nil},
+	}
+	for _, test := range tt {
+		t.Run(test.outcome, func(t *testing.T) {
+			req, _ := http.NewRequest("POST", "/?outcome="+test.outcome, nil)
+			resp := httptest.NewRecorder()
+			m.ServeHTTP(resp, req)
+			assertResponseCode(t, resp, test.wantCode)
+			if test.wantBody != nil {
+				got := Car{}
+				err := json.NewDecoder(resp.Body).Decode(&got)
+				assertNoErrorFatal(t, err)
+				if !reflect.DeepEqual(got, *test.wantBody) {
+					t.Errorf("got: %v want: %v", got, *test.wantBody)
+				}
+			}
+		})
+	}
+	t.Run("undeclared", func(t *testing.T) {
+		defer func() {
+			r := recover()
+			if _, ok := r.(*rest.ErrorOutcomeNotDefined); !ok {
+				t.Errorf("got: %T want: %T", r, &rest.ErrorOutcomeNotDefined{})
+			}
+		}()
+		req, _ := http.NewRequest("POST", "/?outcome=undeclared", nil)
+		m.ServeHTTP(httptest.NewRecorder(), req)
+	})
+	t.Run("responses", func(t *testing.T) {
+		codes := []int{}
+		for _, resp := range m.Responses() {
+			codes = append(codes, resp.Code())
+		}
+		if !reflect.DeepEqual(codes, []int{200, 404, 201, 202, 410}) {
+			t.Errorf("got: %v want: %v", codes, []int{200, 404, 201, 202, 410})
+		}
+	})
+}
+
+func TestWithResponseCopy(t *testing.T) {
+	mo := rest.NewMethodOperation(&OperationStub{}, rest.NewResponse(200))
+	first := mo.WithResponse("created", rest.NewResponse(201))
+	second := mo.WithResponse("created", rest.NewResponse(202))
+	replaced := first.WithResponse(rest.SuccessOutcome, rest.NewResponse(204))
+	codes := func(mo rest.MethodOperation) []int {
+		m := rest.NewMethod("GET", mo, mustGetJSONContentType())
+		codes := []int{}
+		for _, resp := range m.Responses() {
+			codes = append(codes, resp.Code())
+		}
+		return codes
+	}
+	assertCodes := func(got, want []int) {
+		t.Helper()
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("got: %v want: %v", got, want)
+		}
+	}
+	assertCodes(codes(mo), []int{200})
+	assertCodes(codes(first), []int{200, 201})
+	assertCodes(codes(second), []int{200, 202})
+	assertCodes(codes(replaced), []int{204, 201})
+}
+
+func TestOutcomesSameCode(t *testing.T) {
+	mo := rest.NewMethodOperation(&OperationStub{}, rest.NewResponse(200).WithDescription("Car updated").WithOperationResultBody(Car{})).
+		WithResponse("unchanged", rest.NewResponse(200).WithDescription("Car unchanged")).
+		WithResponse("replaced", rest.NewResponse(200).WithDescription("Car updated")).
+		WithResponse("created", rest.NewResponse(201))
+	m := rest.NewMethod("PUT", mo, mustGetJSONContentType())
+	responses := m.Responses()
+	if len(responses) != 2 {
+		t.Fatalf("got %d responses, want 2", len(responses))
+	}
+	assertStringEqual(t, responses[0].Description(), "Car updated or Car unchanged")
+	if _, ok := responses[0].Body().(Car); !ok {
+		t.Errorf("got body: %T want: %T", responses[0].Body(), Car{})
+	}
+	assertTrue(t, responses[1].Code() == 201)
+}
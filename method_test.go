@@ -2,14 +2,19 @@ package rest_test
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"errors"
+	"fmt"
+	"io"
+	"math"
 	"mime/multipart"
 	"net/http"
 	"net/http/httptest"
 	"net/textproto"
 	"reflect"
 	"sort"
+	"sync"
 	"testing"
 
 	"github.com/ehsoc/rest"
@@ -251,6 +256,29 @@ func TestOperations(t *testing.T) {
 			t.Errorf("got:%v want:%v", gotResponse, unsupportedMediaResponse.Body())
 		}
 	})
+	t.Run("not acceptable response in encoder negotiation", func(t *testing.T) {
+		responseBody := TestResponseBody{http.StatusNotAcceptable, "we can not write that"}
+		notAcceptableResponse := rest.NewResponse(http.StatusNotAcceptable).WithBody(responseBody)
+		ct := rest.NewContentTypes()
+		ct.NotAcceptableResponse = notAcceptableResponse
+		ct.Add("application/json", encdec.JSONEncoderDecoder{}, true)
+		operation := &OperationStub{}
+		mo := rest.NewMethodOperation(operation, rest.NewResponse(200))
+		method := rest.NewMethod(http.MethodGet, mo, ct)
+		request, _ := http.NewRequest(http.MethodGet, "/", nil)
+		request.Header.Set("Accept", "text/html, application/json;q=0")
+		response := httptest.NewRecorder()
+		method.ServeHTTP(response, request)
+		assertResponseCode(t, response, http.StatusNotAcceptable)
+		if operation.wasCall {
+			t.Errorf("was not expecting the operation execution")
+		}
+		gotResponse := TestResponseBody{}
+		encdec.JSONEncoderDecoder{}.Decode(response.Body, &gotResponse)
+		if !reflect.DeepEqual(gotResponse, responseBody) {
+			t.Errorf("got:%v want:%v", gotResponse, responseBody)
+		}
+	})
 	t.Run("GET id return entity on Body response", func(t *testing.T) {
 		successResponse := rest.NewResponse(200).WithOperationResultBody(Car{})
 		ct := rest.NewContentTypes()
@@ -868,3 +896,544 @@ func TestWithSecurity(t *testing.T) {
 	assertResponseCode(t, response, successResponse.Code())
 	assertTrue(t, auth.called)
 }
+
+func TestConcurrentResponseBody(t *testing.T) {
+	operation := rest.OperationFunc(func(i rest.Input) (interface{}, bool, error) {
+		brand, _ := i.GetQueryString("brand")
+		return Car{Brand: brand}, true, nil
+	})
+	mo := rest.NewMethodOperation(operation, rest.NewResponse(200).WithOperationResultBody(Car{}))
+	method := rest.NewMethod(http.MethodGet, mo, mustGetJSONContentType()).
+		WithParameter(rest.NewQueryParameter("brand", reflect.String))
+
+	var wg sync.WaitGroup
+
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+
+		go func(n int) {
+			defer wg.Done()
+
+			for j := 0; j < 20; j++ {
+				want := fmt.Sprintf("brand%d-%d", n, j)
+				request, _ := http.NewRequest(http.MethodGet, "/?brand="+want, nil)
+				response := httptest.NewRecorder()
+				method.ServeHTTP(response, request)
+				got := Car{}
+				json.NewDecoder(response.Body).Decode(&got)
+				if got.Brand != want {
+					t.Errorf("got: %v want: %v", got.Brand, want)
+				}
+			}
+		}(i)
+	}
+	wg.Wait()
+}
+
+func TestMutableResponseIsNotShared(t *testing.T) {
+	mutableResponseBody := &MutableBodyStub{}
+	successResponse := rest.NewResponse(200).WithMutableBody(mutableResponseBody)
+	m := rest.NewMethod("GET", rest.NewMethodOperation(&OperationStub{}, successResponse), mustGetCTS())
+	req, _ := http.NewRequest("GET", "/", nil)
+	resp := httptest.NewRecorder()
+	m.ServeHTTP(resp, req)
+	assertResponseCode(t, resp, 200)
+	if !reflect.DeepEqual(mutableResponseBody, &MutableBodyStub{}) {
+		t.Errorf("not expecting the declared body to be mutated, got: %#v", mutableResponseBody)
+	}
+}
+
+func TestParameterParseErrorResponse(t *testing.T) {
+	operation := rest.OperationFunc(func(i rest.Input) (interface{}, bool, error) {
+		_, err := i.GetQueryInt64("limit")
+		if err != nil {
+			return nil, false, err
+		}
+		return nil, true, nil
+	})
+	m := rest.NewMethod("GET", rest.NewMethodOperation(operation, rest.NewResponse(200)), mustGetCTS())
+	m.WithParameter(rest.NewQueryParameter("limit", reflect.Int))
+	t.Run("valid value", func(t *testing.T) {
+		req, _ := http.NewRequest("GET", "/?limit=10", nil)
+		resp := httptest.NewRecorder()
+		m.ServeHTTP(resp, req)
+		assertResponseCode(t, resp, 200)
+	})
+	t.Run("invalid value", func(t *testing.T) {
+		req, _ := http.NewRequest("GET", "/?limit=ten", nil)
+		resp := httptest.NewRecorder()
+		m.ServeHTTP(resp, req)
+		assertResponseCode(t, resp, 400)
+	})
+	t.Run("wrapped parse error", func(t *testing.T) {
+		operation := rest.OperationFunc(func(i rest.Input) (interface{}, bool, error) {
+			_, err := i.GetQueryInt64("limit")
+			return nil, false, fmt.Errorf("listing: %w", err)
+		})
+		m := rest.NewMethod("GET", rest.NewMethodOperation(operation, rest.NewResponse(200)), mustGetCTS())
+		m.WithParameter(rest.NewQueryParameter("limit", reflect.Int))
+		req, _ := http.NewRequest("GET", "/?limit=ten", nil)
+		resp := httptest.NewRecorder()
+		m.ServeHTTP(resp, req)
+		assertResponseCode(t, resp, 400)
+	})
+}
+
+func TestParameterConstraints(t *testing.T) {
+	newMethod := func() *rest.Method {
+		operation := rest.OperationFunc(func(i rest.Input) (interface{}, bool, error) {
+			return nil, true, nil
+		})
+		status := rest.NewQueryArrayParameter("status", []interface{}{"available", "pending", "sold"})
+		status.CollectionFormat = "pipes"
+		m := rest.NewMethod("GET", rest.NewMethodOperation(operation, rest.NewResponse(200)), mustGetJSONContentType())
+		m.WithParameter(rest.NewQueryParameter("limit", reflect.Int).AsRequired()).
+			WithParameter(rest.NewHeaderParameter("X-Debug", reflect.Bool)).
+			WithParameter(status)
+		return m
+	}
+	tt := []struct {
+		name           string
+		target         string
+		header         string
+		wantCode       int
+		wantViolations []string
+	}{
+		{"valid", "/?limit=10&status=available|sold", "true", 200, nil},
+		{"optional parameters", "/?limit=10", "", 200, nil},
+		{"missing required", "/", "", 400, []string{"limit"}},
+		{"invalid kind", "/?limit=ten", "", 400, []string{"limit"}},
+		{"invalid header", "/?limit=10", "yes please", 400, []string{"X-Debug"}},
+		{"enum", "/?limit=10&status=available|lost", "", 400, []string{"status"}},
+		{"every violation", "/?status=lost|gone", "yes", 400, []string{"X-Debug", "limit", "status", "status"}},
+	}
+	for _, test := range tt {
+		t.Run(test.name, func(t *testing.T) {
+			m := newMethod()
+			req, _ := http.NewRequest("GET", test.target, nil)
+			if test.header != "" {
+				req.Header.Set("X-Debug", test.header)
+			}
+			resp := httptest.NewRecorder()
+			m.ServeHTTP(resp, req)
+			assertResponseCode(t, resp, test.wantCode)
+			if test.wantCode != 400 {
+				return
+			}
+			body := rest.ParameterViolationsBody{}
+			err := json.NewDecoder(resp.Body).Decode(&body)
+			assertNoErrorFatal(t, err)
+			got := []string{}
+			for _, v := range body.Violations {
+				got = append(got, v.Name)
+			}
+			if !reflect.DeepEqual(got, test.wantViolations) {
+				t.Errorf("got: %v want: %v", got, test.wantViolations)
+			}
+		})
+	}
+	t.Run("uri parameter", func(t *testing.T) {
+		operation := rest.OperationFunc(func(i rest.Input) (interface{}, bool, error) {
+			return nil, true, nil
+		})
+		m := rest.NewMethod("GET", rest.NewMethodOperation(operation, rest.NewResponse(200)), mustGetJSONContentType())
+		m.WithParameter(rest.NewURIParameter("id", reflect.Int64))
+		ctx := context.WithValue(context.Background(), rest.InputContextKey("uriparamfunc"), func(r *http.Request, key string) string {
+			return "abc"
+		})
+		req, _ := http.NewRequest("GET", "/abc", nil)
+		resp := httptest.NewRecorder()
+		m.ServeHTTP(resp, req.WithContext(ctx))
+		assertResponseCode(t, resp, 400)
+	})
+	t.Run("custom response", func(t *testing.T) {
+		m := newMethod().WithParameterConstraintsResponse(rest.NewResponse(422))
+		req, _ := http.NewRequest("GET", "/", nil)
+		resp := httptest.NewRecorder()
+		m.ServeHTTP(resp, req)
+		assertResponseCode(t, resp, 422)
+	})
+	t.Run("violation error", func(t *testing.T) {
+		var gotErr error
+		m := newMethod().WithParameterConstraintsResponse(rest.NewResponse(400).WithMutableBody(MutableBodyFunc(func(v interface{}, success bool, err error) {
+			gotErr = err
+		})))
+		req, _ := http.NewRequest("GET", "/?limit=1&status=lost", nil)
+		m.ServeHTTP(httptest.NewRecorder(), req)
+		var enumErr *rest.ErrorParameterEnum
+		if !errors.As(gotErr.(*rest.ErrorParameterConstraints).Violations[0].Unwrap(), &enumErr) {
+			t.Fatalf("got: %T want: %T", gotErr, &rest.ErrorParameterEnum{})
+		}
+		if enumErr.Value != "lost" {
+			t.Errorf("got: %v want: %v", enumErr.Value, "lost")
+		}
+	})
+	t.Run("responses", func(t *testing.T) {
+		m := newMethod()
+		assertTrue(t, hasCode(m.Responses(), 400))
+		m = newMethod().WithParameterConstraintsResponse(rest.NewResponse(422))
+		assertTrue(t, hasCode(m.Responses(), 422))
+		operation := rest.OperationFunc(func(i rest.Input) (interface{}, bool, error) {
+			return nil, true, nil
+		})
+		m = rest.NewMethod("GET", rest.NewMethodOperation(operation, rest.NewResponse(200)), mustGetJSONContentType())
+		m.WithParameter(rest.NewQueryParameter("q", reflect.String))
+		assertTrue(t, !hasCode(m.Responses(), 400))
+	})
+}
+
+type MutableBodyFunc func(v interface{}, success bool, err error)
+
+func (f MutableBodyFunc) Mutate(v interface{}, success bool, err error) {
+	f(v, success, err)
+}
+
+func hasCode(responses []rest.Response, code int) bool {
+	for _, r := range responses {
+		if r.Code() == code {
+			return true
+		}
+	}
+	return false
+}
+
+type Engine struct {
+	Cylinders int `json:"cylinders" rest:"required"`
+}
+
+type StrictCar struct {
+	Brand  string  `json:"brand" rest:"required"`
+	Model  string  `json:"model"`
+	Engine *Engine `json:"engine"`
+}
+
+func TestDecodeBodyOnce(t *testing.T) {
+	validatorBody := make(chan interface{}, 1)
+	operation := rest.OperationFunc(func(i rest.Input) (interface{}, bool, error) {
+		body, err := i.DecodeBody()
+		if err != nil {
+			return nil, false, err
+		}
+		return body, true, nil
+	})
+	successResponse := rest.NewResponse(200).WithOperationResultBody(Car{})
+	m := rest.NewMethod("POST", rest.NewMethodOperation(operation, successResponse), mustGetJSONContentType())
+	m.WithRequestBody("car", Car{}).WithValidation(rest.Validation{
+		Validator: rest.ValidatorFunc(func(i rest.Input) error {
+			body, err := i.DecodeBody()
+			validatorBody <- body
+			return err
+		}),
+		Response: rest.NewResponse(400),
+	})
+	req, _ := http.NewRequest("POST", "/", bytes.NewBufferString(`{"id":1,"brand":"Fiat"}`))
+	req.Header.Set("Content-Type", "application/json")
+	resp := httptest.NewRecorder()
+	m.ServeHTTP(resp, req)
+	assertResponseCode(t, resp, 200)
+	want := Car{ID: 1, Brand: "Fiat"}
+	select {
+	case got := <-validatorBody:
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("got: %#v want: %#v", got, want)
+		}
+	default:
+		t.Errorf("expecting the validator to decode the body")
+	}
+	got := Car{}
+	json.NewDecoder(resp.Body).Decode(&got)
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got: %#v want: %#v", got, want)
+	}
+}
+
+func TestStrictRequestBody(t *testing.T) {
+	newMethod := func(strict bool) *rest.Method {
+		operation := rest.OperationFunc(func(i rest.Input) (interface{}, bool, error) {
+			_, err := i.DecodeBody()
+			return nil, true, err
+		})
+		m := rest.NewMethod("POST", rest.NewMethodOperation(operation, rest.NewResponse(201)), mustGetJSONContentType())
+		m.WithRequestBody("car", StrictCar{})
+		if strict {
+			m.WithStrictRequestBody(rest.NewResponse(422))
+		}
+		return m
+	}
+	tt := []struct {
+		name     string
+		strict   bool
+		body     string
+		wantCode int
+	}{
+		{"valid", true, `{"brand":"Fiat","engine":{"cylinders":4}}`, 201},
+		{"optional nested struct", true, `{"brand":"Fiat"}`, 201},
+		{"unknown field", true, `{"brand":"Fiat","wheels":4}`, 422},
+		{"missing required field", true, `{"model":"Uno"}`, 422},
+		{"missing required nested field", true, `{"brand":"Fiat","engine":{}}`, 422},
+		{"explicit zero values", true, `{"brand":"","engine":{"cylinders":0}}`, 201},
+		{"field name case", true, `{"Brand":"Fiat"}`, 201},
+		{"null required field", true, `{"brand":null}`, 422},
+		{"malformed body", true, `{"brand":`, 422},
+		{"not strict", false, `{"model":"Uno","wheels":4}`, 201},
+	}
+	for _, test := range tt {
+		t.Run(test.name, func(t *testing.T) {
+			req, _ := http.NewRequest("POST", "/", bytes.NewBufferString(test.body))
+			req.Header.Set("Content-Type", "application/json")
+			resp := httptest.NewRecorder()
+			newMethod(test.strict).ServeHTTP(resp, req)
+			assertResponseCode(t, resp, test.wantCode)
+		})
+	}
+	t.Run("required fields error", func(t *testing.T) {
+		var gotErr error
+		m := newMethod(false).WithStrictRequestBody(rest.NewResponse(400).WithMutableBody(MutableBodyFunc(func(v interface{}, success bool, err error) {
+			gotErr = err
+		})))
+		req, _ := http.NewRequest("POST", "/", bytes.NewBufferString(`{"engine":{}}`))
+		req.Header.Set("Content-Type", "application/json")
+		m.ServeHTTP(httptest.NewRecorder(), req)
+		requiredErr, ok := gotErr.(*rest.ErrorRequestBodyRequiredFields)
+		if !ok {
+			t.Fatalf("got: %T want: %T", gotErr, &rest.ErrorRequestBodyRequiredFields{})
+		}
+		want := []string{"brand", "engine.cylinders"}
+		if !reflect.DeepEqual(requiredErr.Fields, want) {
+			t.Errorf("got: %v want: %v", requiredErr.Fields, want)
+		}
+	})
+	t.Run("renamed required field", func(t *testing.T) {
+		type Pet struct {
+			ID int `json:"petId" rest:"required"`
+		}
+		operation := rest.OperationFunc(func(i rest.Input) (interface{}, bool, error) {
+			_, err := i.DecodeBody()
+			return nil, true, err
+		})
+		// a decoder that accepts unknown fields, so the required check is the one rejecting the body
+		ct := rest.NewContentTypes()
+		ct.AddDecoder("application/json", lenientJSONDecoder{}, true)
+		ct.AddEncoder("application/json", encdec.JSONEncoder{}, true)
+		m := rest.NewMethod("POST", rest.NewMethodOperation(operation, rest.NewResponse(201)), ct).
+			WithRequestBody("pet", Pet{}).
+			WithStrictRequestBody(rest.NewResponse(422))
+		for body, wantCode := range map[string]int{
+			`{"petId":1}`: 201,
+			`{"PETID":1}`: 201,
+			`{"id":1}`:    422,
+			`{"ID":1}`:    422,
+		} {
+			req, _ := http.NewRequest("POST", "/", bytes.NewBufferString(body))
+			req.Header.Set("Content-Type", "application/json")
+			resp := httptest.NewRecorder()
+			m.ServeHTTP(resp, req)
+			assertResponseCode(t, resp, wantCode)
+		}
+	})
+	t.Run("decoder without presence", func(t *testing.T) {
+		type XMLCar struct {
+			Brand *string `xml:"brand" rest:"required"`
+			Doors int     `xml:"doors" rest:"required"`
+		}
+		operation := rest.OperationFunc(func(i rest.Input) (interface{}, bool, error) {
+			_, err := i.DecodeBody()
+			return nil, true, err
+		})
+		ct := rest.NewContentTypes()
+		ct.Add("application/xml", encdec.XMLEncoderDecoder{}, true)
+		m := rest.NewMethod("POST", rest.NewMethodOperation(operation, rest.NewResponse(201)), ct).
+			WithRequestBody("car", XMLCar{}).
+			WithStrictRequestBody(rest.NewResponse(422))
+		for body, wantCode := range map[string]int{
+			"<XMLCar><brand>Fiat</brand><doors>0</doors></XMLCar>": 201,
+			"<XMLCar><doors>4</doors></XMLCar>":                    422,
+		} {
+			req, _ := http.NewRequest("POST", "/", bytes.NewBufferString(body))
+			req.Header.Set("Content-Type", "application/xml")
+			resp := httptest.NewRecorder()
+			m.ServeHTTP(resp, req)
+			assertResponseCode(t, resp, wantCode)
+		}
+	})
+	t.Run("responses", func(t *testing.T) {
+		assertTrue(t, hasCode(newMethod(true).Responses(), 422))
+		assertTrue(t, !hasCode(newMethod(false).Responses(), 422))
+	})
+}
+
+type lenientJSONDecoder struct{}
+
+func (lenientJSONDecoder) Decode(r io.Reader, v interface{}) error {
+	return json.NewDecoder(r).Decode(v)
+}
+
+func TestStreamBody(t *testing.T) {
+	operation := rest.OperationFunc(func(i rest.Input) (interface{}, bool, error) {
+		cars := make(chan Car)
+		go func() {
+			defer close(cars)
+			for _, id := range []int{1, 2} {
+				select {
+				case cars <- Car{ID: id}:
+				case <-i.Request.Context().Done():
+					return
+				}
+			}
+		}()
+		return cars, true, nil
+	})
+	ct := rest.NewContentTypes()
+	ct.AddEncoder("application/json", encdec.JSONEncoder{}, true)
+	ct.AddEncoder("application/x-ndjson", encdec.NDJSONEncoder{}, false)
+	m := rest.NewMethod("GET", rest.NewMethodOperation(operation, rest.NewResponse(200).WithOperationResultBody([]Car{})), ct)
+	tt := []struct {
+		accept string
+		want   string
+	}{
+		{"application/x-ndjson", "{\"id\":1,\"brand\":\"\",\"colors\":null}\n{\"id\":2,\"brand\":\"\",\"colors\":null}\n"},
+		{"application/json", "[{\"id\":1,\"brand\":\"\",\"colors\":null},{\"id\":2,\"brand\":\"\",\"colors\":null}]\n"},
+	}
+	for _, test := range tt {
+		t.Run(test.accept, func(t *testing.T) {
+			req, _ := http.NewRequest("GET", "/", nil)
+			req.Header.Set("Accept", test.accept)
+			resp := httptest.NewRecorder()
+			m.ServeHTTP(resp, req)
+			assertResponseCode(t, resp, 200)
+			assertStringEqual(t, resp.Body.String(), test.want)
+		})
+	}
+}
+
+func TestStreamBodyErrors(t *testing.T) {
+	newMethod := func(body func() interface{}) *rest.Method {
+		operation := rest.OperationFunc(func(i rest.Input) (interface{}, bool, error) {
+			return body(), true, nil
+		})
+		ct := rest.NewContentTypes()
+		ct.AddEncoder("application/x-ndjson", encdec.NDJSONEncoder{}, true)
+		return rest.NewMethod("GET", rest.NewMethodOperation(operation, rest.NewResponse(200).WithOperationResultBody([]Car{})), ct)
+	}
+	items := func(values ...interface{}) func() interface{} {
+		return func() interface{} {
+			c := make(chan interface{}, len(values))
+			for _, v := range values {
+				c <- v
+			}
+			close(c)
+			return c
+		}
+	}
+	tt := []struct {
+		name     string
+		body     func() interface{}
+		wantCode int
+		wantBody string
+	}{
+		{"empty stream", items(), 200, ""},
+		{"first item error", items(math.Inf(1), 1.0), 500, "Internal Server Error\n"},
+		{"not a stream", func() interface{} { return Car{ID: 1} }, 200, "{\"id\":1,\"brand\":\"\",\"colors\":null}\n"},
+		{"not a stream error", func() interface{} { return math.Inf(1) }, 500, "Internal Server Error\n"},
+	}
+	for _, test := range tt {
+		t.Run(test.name, func(t *testing.T) {
+			req, _ := http.NewRequest("GET", "/", nil)
+			resp := httptest.NewRecorder()
+			newMethod(test.body).ServeHTTP(resp, req)
+			assertResponseCode(t, resp, test.wantCode)
+			assertStringEqual(t, resp.Body.String(), test.wantBody)
+		})
+	}
+	t.Run("item error after writing", func(t *testing.T) {
+		req, _ := http.NewRequest("GET", "/", nil)
+		resp := httptest.NewRecorder()
+		defer func() {
+			if r := recover(); r != http.ErrAbortHandler {
+				t.Errorf("got: %v want: %v", r, http.ErrAbortHandler)
+			}
+			assertResponseCode(t, resp, 200)
+			assertStringEqual(t, resp.Body.String(), "1\n")
+		}()
+		newMethod(items(1.0, math.Inf(1))).ServeHTTP(resp, req)
+	})
+}
+
+type Row struct {
+	ID   int    `csv:"id"`
+	Name string `csv:"name"`
+}
+
+type rowIterator struct {
+	rows    []Row
+	current int
+	err     error
+}
+
+func (r *rowIterator) Next() bool {
+	if r.current >= len(r.rows) {
+		return false
+	}
+	r.current++
+	return true
+}
+
+func (r *rowIterator) Item() interface{} {
+	return r.rows[r.current-1]
+}
+
+func (r *rowIterator) Err() error {
+	return r.err
+}
+
+func TestCSVStreamBody(t *testing.T) {
+	var body func() interface{}
+	operation := rest.OperationFunc(func(i rest.Input) (interface{}, bool, error) {
+		return body(), true, nil
+	})
+	ct := rest.NewContentTypes()
+	ct.AddEncoder("text/csv", encdec.CSVEncoder{}, true)
+	m := rest.NewMethod("GET", rest.NewMethodOperation(operation, rest.NewResponse(200).WithOperationResultBody([]Row{})), ct)
+	tt := []struct {
+		name     string
+		body     func() interface{}
+		wantCode int
+		want     string
+	}{
+		{"channel", func() interface{} {
+			rows := make(chan Row, 2)
+			rows <- Row{1, "Fiat"}
+			rows <- Row{2, "Ford"}
+			close(rows)
+			return (<-chan Row)(rows)
+		}, 200, "id,name\n1,Fiat\n2,Ford\n"},
+		{"empty channel", func() interface{} {
+			rows := make(chan Row)
+			close(rows)
+			return rows
+		}, 200, "id,name\n"},
+		{"iterator", func() interface{} {
+			return &rowIterator{rows: []Row{{1, "Fiat"}}}
+		}, 200, "id,name\n1,Fiat\n"},
+		{"empty iterator", func() interface{} {
+			return &rowIterator{}
+		}, 200, ""},
+		{"iterator error", func() interface{} {
+			return &rowIterator{rows: []Row{{1, "Fiat"}}, err: errors.New("connection lost")}
+		}, 500, "Internal Server Error\n"},
+		{"not a struct slice", func() interface{} {
+			return "Fiat"
+		}, 500, "Internal Server Error\n"},
+	}
+	for _, test := range tt {
+		t.Run(test.name, func(t *testing.T) {
+			body = test.body
+			req, _ := http.NewRequest("GET", "/", nil)
+			req.Header.Set("Accept", "text/csv")
+			resp := httptest.NewRecorder()
+			m.ServeHTTP(resp, req)
+			assertResponseCode(t, resp, test.wantCode)
+			assertStringEqual(t, resp.Body.String(), test.want)
+		})
+	}
+}
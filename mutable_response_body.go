@@ -5,3 +5,11 @@ package rest
 type MutableResponseBody interface {
 	Mutate(operationResultBody interface{}, success bool, err error)
 }
+
+// MutableResponseBodyFactory is implemented by a MutableResponseBody that creates its own instances.
+// The handler creates a new body instance for every request, and mutates it instead of the declared one,
+// so the declared MutableResponseBody is never shared by concurrent requests.
+// If a MutableResponseBody doesn't implement this interface, a shallow copy of the declared value will be used.
+type MutableResponseBodyFactory interface {
+	NewResponseBody() MutableResponseBody
+}
@@ -0,0 +1,222 @@
+package rest
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/ehsoc/rest/httputil"
+)
+
+// collectionFormatSeparators are the separators of the array values for each CollectionFormat.
+// The multi format is not included, as every value is an element of the array.
+var collectionFormatSeparators = map[string]string{
+	"":      ",",
+	"csv":   ",",
+	"ssv":   " ",
+	"tsv":   "\t",
+	"pipes": "|",
+}
+
+// ParameterViolation describes a declared parameter constraint that is not satisfied by a request.
+type ParameterViolation struct {
+	Name    string        `json:"name" xml:"name"`
+	In      ParameterType `json:"in,omitempty" xml:"in,omitempty"`
+	Message string        `json:"message" xml:"message"`
+	err     error
+}
+
+// Unwrap returns the error of the violation:
+// ErrorParameterRequired, ErrorParameterParse or ErrorParameterEnum.
+func (v ParameterViolation) Unwrap() error {
+	return v.err
+}
+
+func newParameterViolation(p Parameter, err error) ParameterViolation {
+	return ParameterViolation{p.Name, p.HTTPType, err.Error(), err}
+}
+
+// ParameterViolationsBody is the MutableResponseBody of the default parameter constraints response.
+// It lists every parameter constraint violation found in the request.
+type ParameterViolationsBody struct {
+	Violations []ParameterViolation `json:"violations" xml:"violation"`
+}
+
+// Mutate takes the violations of an ErrorParameterConstraints error, or a single violation of an ErrorParameterParse error.
+func (b *ParameterViolationsBody) Mutate(v interface{}, success bool, err error) {
+	var constraintsErr *ErrorParameterConstraints
+	if errors.As(err, &constraintsErr) {
+		b.Violations = constraintsErr.Violations
+		return
+	}
+
+	var parseErr *ErrorParameterParse
+	if errors.As(err, &parseErr) {
+		b.Violations = []ParameterViolation{{Name: parseErr.Name, Message: parseErr.Error(), err: parseErr}}
+	}
+}
+
+// newParameterConstraintsResponse returns the default parameter constraints response.
+func newParameterConstraintsResponse() Response {
+	return NewResponse(http.StatusBadRequest).WithDescription("Invalid parameters").WithMutableBody(&ParameterViolationsBody{})
+}
+
+// splitCollectionValues splits the values of an array parameter according to the CollectionFormat.
+func splitCollectionValues(format string, values []string) []string {
+	sep, ok := collectionFormatSeparators[format]
+	if !ok {
+		return values
+	}
+
+	items := []string{}
+
+	for _, v := range values {
+		if v == "" {
+			continue
+		}
+
+		items = append(items, strings.Split(v, sep)...)
+	}
+
+	return items
+}
+
+func isArrayParameter(p Parameter) bool {
+	return p.Type == reflect.Array || p.Type == reflect.Slice
+}
+
+// isConvertibleKind returns true if a string value can be converted to the kind.
+func isConvertibleKind(kind reflect.Kind) bool {
+	return isIntKind(kind) || isUintKind(kind) || isFloatKind(kind) || kind == reflect.Bool
+}
+
+// hasConstraints returns true if the parameter declares a constraint that is checked on every request.
+func hasConstraints(p Parameter) bool {
+	if p.Required && p.HTTPType != URIParameter {
+		return true
+	}
+
+	return isConvertibleKind(p.Type) || len(p.EnumValues) > 0
+}
+
+// hasParameterConstraints returns true if any of the method parameters declares a constraint.
+func (m *Method) hasParameterConstraints() bool {
+	for _, p := range m.Parameters() {
+		if hasConstraints(p) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// requestValues gets the raw values of the parameter in the request, split according to the CollectionFormat
+// for array parameters. The ok result is false when the value can not be checked.
+func requestValues(r *http.Request, p Parameter) (values []string, ok bool) {
+	switch p.HTTPType {
+	case URIParameter:
+		getURIParamFunc, ok := r.Context().Value(InputContextKey("uriparamfunc")).(func(r *http.Request, key string) string)
+		if !ok {
+			return nil, false
+		}
+
+		values = []string{getURIParamFunc(r, p.Name)}
+	case HeaderParameter:
+		values = r.Header[http.CanonicalHeaderKey(p.Name)]
+	case QueryParameter:
+		values = r.URL.Query()[p.Name]
+	case FormDataParameter:
+		values, _ = httputil.GetFormValues(r, p.Name)
+	case FileParameter:
+		files, err := httputil.GetFiles(r, p.Name)
+		if err != nil {
+			return nil, true
+		}
+
+		values = make([]string, len(files))
+		for i, fh := range files {
+			values[i] = fh.Filename
+		}
+
+		return values, true
+	}
+
+	if isArrayParameter(p) {
+		return splitCollectionValues(p.CollectionFormat, values), true
+	}
+
+	if len(values) == 0 || values[0] == "" {
+		return nil, true
+	}
+
+	return values[:1], true
+}
+
+func isEnumValue(p Parameter, value string) bool {
+	for _, e := range p.EnumValues {
+		if fmt.Sprint(e) == value {
+			return true
+		}
+	}
+
+	return false
+}
+
+// checkParameterConstraints checks the request against the declared parameter constraints:
+// required parameters, values that must be converted to the declared Type,
+// and values that must be one of the declared EnumValues.
+// All the violations found are returned in an ErrorParameterConstraints error.
+func (m *Method) checkParameterConstraints(r *http.Request) error {
+	params := m.Parameters()
+	// Sorting for a consistent order of the violations
+	sort.Slice(params, func(i, j int) bool {
+		if params[i].HTTPType != params[j].HTTPType {
+			return params[i].HTTPType < params[j].HTTPType
+		}
+
+		return params[i].Name < params[j].Name
+	})
+
+	violations := []ParameterViolation{}
+
+	for _, p := range params {
+		if !hasConstraints(p) {
+			continue
+		}
+
+		values, ok := requestValues(r, p)
+		if !ok {
+			continue
+		}
+
+		if len(values) == 0 {
+			if p.Required {
+				violations = append(violations, newParameterViolation(p, &ErrorParameterRequired{p.Name, p.HTTPType}))
+			}
+
+			continue
+		}
+
+		for _, value := range values {
+			if !isArrayParameter(p) && isConvertibleKind(p.Type) {
+				if _, err := parseParameterValue(p, value); err != nil {
+					violations = append(violations, newParameterViolation(p, err))
+					continue
+				}
+			}
+
+			if len(p.EnumValues) > 0 && !isEnumValue(p, value) {
+				violations = append(violations, newParameterViolation(p, &ErrorParameterEnum{p.Name, value, p.EnumValues}))
+			}
+		}
+	}
+
+	if len(violations) > 0 {
+		return &ErrorParameterConstraints{violations}
+	}
+
+	return nil
+}
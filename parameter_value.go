@@ -0,0 +1,170 @@
+package rest
+
+import (
+	"reflect"
+	"strconv"
+)
+
+func isIntKind(kind reflect.Kind) bool {
+	switch kind {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return true
+	}
+
+	return false
+}
+
+func isUintKind(kind reflect.Kind) bool {
+	switch kind {
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return true
+	}
+
+	return false
+}
+
+func isFloatKind(kind reflect.Kind) bool {
+	return kind == reflect.Float32 || kind == reflect.Float64
+}
+
+// bitSize returns the size in bits of a numeric kind, or 0 for the platform dependent int and uint kinds.
+func bitSize(kind reflect.Kind) int {
+	switch kind {
+	case reflect.Int8, reflect.Uint8:
+		return 8
+	case reflect.Int16, reflect.Uint16:
+		return 16
+	case reflect.Int32, reflect.Uint32, reflect.Float32:
+		return 32
+	case reflect.Int64, reflect.Uint64, reflect.Float64:
+		return 64
+	}
+
+	return 0
+}
+
+// parseParameterValue converts the string value to the parameter declared Type.
+// Integer kinds are returned as int64, unsigned integer kinds as uint64, float kinds as float64,
+// and the Bool kind as bool. Any other kind will return the value as it is.
+func parseParameterValue(p Parameter, value string) (interface{}, error) {
+	var (
+		v   interface{}
+		err error
+	)
+
+	switch {
+	case isIntKind(p.Type):
+		v, err = strconv.ParseInt(value, 10, bitSize(p.Type))
+	case isUintKind(p.Type):
+		v, err = strconv.ParseUint(value, 10, bitSize(p.Type))
+	case isFloatKind(p.Type):
+		v, err = strconv.ParseFloat(value, bitSize(p.Type))
+	case p.Type == reflect.Bool:
+		v, err = strconv.ParseBool(value)
+	default:
+		v = value
+	}
+
+	if err != nil {
+		if numErr, ok := err.(*strconv.NumError); ok {
+			err = numErr.Err
+		}
+
+		return nil, &ErrorParameterParse{p.Name, value, p.Type, err}
+	}
+
+	return v, nil
+}
+
+// setParameterValue sets the string value into the value pointed by dst, converting it to the parameter declared Type.
+func setParameterValue(p Parameter, value string, dst reflect.Value) error {
+	if dst.Kind() == reflect.String {
+		dst.SetString(value)
+		return nil
+	}
+
+	if value == "" {
+		return nil
+	}
+
+	v, err := parseParameterValue(p, value)
+	if err != nil {
+		return err
+	}
+
+	rv := reflect.ValueOf(v)
+
+	switch {
+	case isIntKind(dst.Kind()) && isIntKind(p.Type):
+		if dst.OverflowInt(rv.Int()) {
+			return &ErrorParameterParse{p.Name, value, dst.Kind(), strconv.ErrRange}
+		}
+
+		dst.SetInt(rv.Int())
+	case isUintKind(dst.Kind()) && isUintKind(p.Type):
+		if dst.OverflowUint(rv.Uint()) {
+			return &ErrorParameterParse{p.Name, value, dst.Kind(), strconv.ErrRange}
+		}
+
+		dst.SetUint(rv.Uint())
+	case isFloatKind(dst.Kind()) && isFloatKind(p.Type):
+		dst.SetFloat(rv.Float())
+	case dst.Kind() == reflect.Bool && p.Type == reflect.Bool:
+		dst.SetBool(rv.Bool())
+	default:
+		return &ErrorParameterTypeMismatch{p.Name, p.Type, dst.Type().String()}
+	}
+
+	return nil
+}
+
+func parseInt64Value(p Parameter, value string) (int64, error) {
+	if !isIntKind(p.Type) {
+		return 0, &ErrorParameterTypeMismatch{p.Name, p.Type, "int64"}
+	}
+
+	if value == "" {
+		return 0, nil
+	}
+
+	v, err := parseParameterValue(p, value)
+	if err != nil {
+		return 0, err
+	}
+
+	return v.(int64), nil
+}
+
+func parseFloatValue(p Parameter, value string) (float64, error) {
+	if !isFloatKind(p.Type) {
+		return 0, &ErrorParameterTypeMismatch{p.Name, p.Type, "float64"}
+	}
+
+	if value == "" {
+		return 0, nil
+	}
+
+	v, err := parseParameterValue(p, value)
+	if err != nil {
+		return 0, err
+	}
+
+	return v.(float64), nil
+}
+
+func parseBoolValue(p Parameter, value string) (bool, error) {
+	if p.Type != reflect.Bool {
+		return false, &ErrorParameterTypeMismatch{p.Name, p.Type, "bool"}
+	}
+
+	if value == "" {
+		return false, nil
+	}
+
+	v, err := parseParameterValue(p, value)
+	if err != nil {
+		return false, err
+	}
+
+	return v.(bool), nil
+}
@@ -0,0 +1,83 @@
+package rest
+
+import "net/http"
+
+// ProblemDetailsMediaType is the media type of a problem details body, as defined in RFC 7807.
+const ProblemDetailsMediaType = "application/problem+json"
+
+// ProblemDetails is a MutableResponseBody that represents a problem details object, as defined in RFC 7807.
+// A response with a ProblemDetails body is always written with the ProblemDetailsMediaType content-type, using a JSON encoder.
+// The empty Status, Title and Instance properties will be set with the response code, the status text of the code,
+// and the request URI. For a response code lower than 500, Detail is set with the error message of the operation or validation,
+// so internal error messages are never sent to the client.
+type ProblemDetails struct {
+	Type     string `json:"type,omitempty"`
+	Title    string `json:"title,omitempty"`
+	Status   int    `json:"status,omitempty"`
+	Detail   string `json:"detail,omitempty"`
+	Instance string `json:"instance,omitempty"`
+	err      error
+}
+
+// NewProblemDetailsResponse returns a Response with the specified code and a ProblemDetails body.
+func NewProblemDetailsResponse(code int) Response {
+	return NewResponse(code).WithMutableBody(&ProblemDetails{})
+}
+
+// Mutate takes the error of the operation or validation, to be used as the Detail property.
+func (p *ProblemDetails) Mutate(v interface{}, success bool, err error) {
+	p.err = err
+}
+
+// complete sets the empty properties with the response code and the request.
+func (p *ProblemDetails) complete(code int, r *http.Request) {
+	if p.Status == 0 {
+		p.Status = code
+	}
+
+	if p.Title == "" {
+		p.Title = http.StatusText(code)
+	}
+
+	if p.Detail == "" && p.err != nil && code < http.StatusInternalServerError {
+		p.Detail = p.err.Error()
+	}
+
+	if p.Instance == "" && r != nil {
+		p.Instance = r.URL.RequestURI()
+	}
+}
+
+// problemResponse returns the response with a ProblemDetails body if the problem details responses are enabled
+// for the method, and the response is an error response without a body.
+func (m *Method) problemResponse(resp Response) Response {
+	if m.problemDetails && resp.code >= http.StatusBadRequest && resp.MutableResponseBody == nil {
+		resp.MutableResponseBody = &ProblemDetails{}
+	}
+
+	return resp
+}
+
+// problemResponses returns the core error responses that can be written by the method handler.
+func (m *Method) problemResponses() []Response {
+	responses := []Response{m.contentTypes.UnsupportedMediaTypeResponse, m.contentTypes.NotAcceptableResponse}
+
+	for _, s := range m.SecurityCollection {
+		for _, ss := range s.SecuritySchemes {
+			responses = append(responses, ss.FailedAuthenticationResponse, ss.FailedAuthorizationResponse)
+		}
+	}
+
+	return append(responses, NewResponse(http.StatusInternalServerError))
+}
+
+// setProblemDetails enables or disables the problem details responses for all the methods of the resources.
+func setProblemDetails(resources map[string]Resource, enabled bool) {
+	for _, resource := range resources {
+		for _, m := range resource.methods {
+			m.problemDetails = enabled
+		}
+
+		setProblemDetails(resource.resources, enabled)
+	}
+}
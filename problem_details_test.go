@@ -0,0 +1,158 @@
+package rest_test
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/ehsoc/rest"
+)
+
+// MethodGenStub serves the methods of the "car" resource, without routing.
+type MethodGenStub struct{}
+
+func (g MethodGenStub) GenerateAPISpec(w io.Writer, api rest.API) {}
+
+func (g MethodGenStub) GenerateServer(api rest.API) http.Handler {
+	methods := map[string]rest.Method{}
+	for _, r := range api.Resources() {
+		for _, m := range r.Methods() {
+			methods[m.HTTPMethod] = m
+		}
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		methods[r.Method].ServeHTTP(w, r)
+	})
+}
+
+func (g MethodGenStub) GetURIParam() func(*http.Request, string) string {
+	return func(r *http.Request, p string) string {
+		return ""
+	}
+}
+
+func newProblemDetailsAPI(enabled bool) rest.API {
+	api := rest.API{ProblemDetails: enabled}
+	api.Resource("car", func(r *rest.Resource) {
+		get := rest.NewMethodOperation(rest.OperationFunc(func(i rest.Input) (interface{}, bool, error) {
+			switch i.Request.URL.Query().Get("result") {
+			case "error":
+				return nil, false, errors.New("database connection refused")
+			case "fail":
+				return nil, false, nil
+			}
+			return Car{ID: 1}, true, nil
+		}), rest.NewResponse(200).WithOperationResultBody(Car{})).WithFailResponse(rest.NewResponse(404))
+		so := rest.SecurityOperation{
+			Authenticator: rest.AuthenticatorFunc(func(i rest.Input) rest.AuthError {
+				if i.Request.Header.Get("X-Key") == "" {
+					return rest.ErrorAuthentication{Message: "missing key"}
+				}
+				return nil
+			}),
+			FailedAuthenticationResponse: rest.NewResponse(401),
+		}
+		r.Get(get, mustGetJSONContentType()).
+			WithSecurity(rest.NewAPIKeySecurityScheme("key", rest.NewHeaderParameter("X-Key", reflect.String), so))
+		post := rest.NewMethodOperation(rest.OperationFunc(func(i rest.Input) (interface{}, bool, error) {
+			return nil, false, nil
+		}), rest.NewResponse(201)).WithFailResponse(rest.NewResponse(409).WithMutableBody(&rest.ProblemDetails{Type: "https://example.com/conflict"}))
+		r.Post(post, mustGetJSONContentType())
+	})
+	return api
+}
+
+func TestProblemDetails(t *testing.T) {
+	server := newProblemDetailsAPI(true).GenerateServer(MethodGenStub{})
+	tt := []struct {
+		name     string
+		method   string
+		target   string
+		header   http.Header
+		wantCode int
+		want     rest.ProblemDetails
+	}{
+		{"internal error", "GET", "/car?result=error", http.Header{"X-Key": {"k"}}, 500,
+			rest.ProblemDetails{Title: "Internal Server Error", Status: 500, Instance: "/car?result=error"}},
+		{"fail response", "GET", "/car?result=fail", http.Header{"X-Key": {"k"}}, 404,
+			rest.ProblemDetails{Title: "Not Found", Status: 404, Instance: "/car?result=fail"}},
+		{"security", "GET", "/car", nil, 401,
+			rest.ProblemDetails{Title: "Unauthorized", Status: 401, Detail: "missing key", Instance: "/car"}},
+		{"negotiation", "GET", "/car", http.Header{"X-Key": {"k"}, "Content-Type": {"text/csv"}}, 415,
+			rest.ProblemDetails{Title: "Unsupported Media Type", Status: 415, Detail: "unavailable decoder", Instance: "/car"}},
+		{"declared problem body", "POST", "/car", nil, 409,
+			rest.ProblemDetails{Type: "https://example.com/conflict", Title: "Conflict", Status: 409, Instance: "/car"}},
+	}
+	for _, test := range tt {
+		t.Run(test.name, func(t *testing.T) {
+			req, _ := http.NewRequest(test.method, test.target, nil)
+			if test.header.Get("Content-Type") != "" {
+				req, _ = http.NewRequest(test.method, test.target, strings.NewReader("a,b"))
+			}
+			for k, v := range test.header {
+				req.Header[k] = v
+			}
+			resp := httptest.NewRecorder()
+			server.ServeHTTP(resp, req)
+			assertResponseCode(t, resp, test.wantCode)
+			assertStringEqual(t, resp.Header().Get("Content-Type"), rest.ProblemDetailsMediaType)
+			got := rest.ProblemDetails{}
+			err := json.NewDecoder(resp.Body).Decode(&got)
+			assertNoErrorFatal(t, err)
+			if !reflect.DeepEqual(got, test.want) {
+				t.Errorf("got: %#v want: %#v", got, test.want)
+			}
+		})
+	}
+	t.Run("success response", func(t *testing.T) {
+		req, _ := http.NewRequest("GET", "/car", nil)
+		req.Header.Set("X-Key", "k")
+		resp := httptest.NewRecorder()
+		server.ServeHTTP(resp, req)
+		assertResponseCode(t, resp, 200)
+		assertStringEqual(t, resp.Header().Get("Content-Type"), "application/json")
+	})
+	t.Run("disabled", func(t *testing.T) {
+		server := newProblemDetailsAPI(false).GenerateServer(MethodGenStub{})
+		req, _ := http.NewRequest("GET", "/car?result=fail", nil)
+		req.Header.Set("X-Key", "k")
+		resp := httptest.NewRecorder()
+		server.ServeHTTP(resp, req)
+		assertResponseCode(t, resp, 404)
+		if resp.Body.Len() != 0 {
+			t.Errorf("not expecting a body, got: %s", resp.Body.String())
+		}
+	})
+}
+
+func TestProblemDetailsResponses(t *testing.T) {
+	api := newProblemDetailsAPI(true)
+	api.GenerateSpec(ioutil.Discard, MethodGenStub{})
+	for _, r := range api.Resources() {
+		for _, m := range r.Methods() {
+			if m.HTTPMethod != "GET" {
+				continue
+			}
+			codes := map[int]bool{}
+			for _, resp := range m.Responses() {
+				codes[resp.Code()] = true
+				if resp.Code() >= 400 {
+					if _, ok := resp.Body().(*rest.ProblemDetails); !ok {
+						t.Errorf("expecting a problem details body for response %d, got: %T", resp.Code(), resp.Body())
+					}
+				}
+			}
+			for _, code := range []int{200, 401, 404, 415, 500} {
+				if !codes[code] {
+					t.Errorf("expecting response %d", code)
+				}
+			}
+		}
+	}
+}
@@ -1,8 +1,215 @@
 package rest
 
+import (
+	"bytes"
+	"io"
+	"io/ioutil"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/ehsoc/rest/encdec"
+)
+
 // RequestBody represents a request body parameter
 type RequestBody struct {
 	Description string
 	Body        interface{}
 	Required    bool
 }
+
+// requestBodyCache holds the decoded request body, so the body is decoded once per request.
+// It is set in the InputContextKey("body") context value.
+type requestBodyCache struct {
+	strict  bool
+	decoded bool
+	value   interface{}
+	err     error
+}
+
+// decodeRequestBody decodes r into a new value of the type of body.
+// If strict is true, the decoder will reject unknown fields if it implements encdec.StrictDecoder,
+// and the fields tagged with `rest:"required"` must be present in the body.
+func decodeRequestBody(r io.Reader, decoder encdec.Decoder, body interface{}, strict bool) (interface{}, error) {
+	t := reflect.TypeOf(body)
+	isPtr := t.Kind() == reflect.Ptr
+
+	if isPtr {
+		t = t.Elem()
+	}
+
+	v := reflect.New(t)
+
+	var b []byte
+	if strict {
+		// the body is decoded twice, in the value and in a map with the fields present in the body
+		var err error
+		if b, err = ioutil.ReadAll(r); err != nil {
+			return nil, &ErrorRequestBodyDecode{err}
+		}
+
+		r = bytes.NewReader(b)
+	}
+
+	var err error
+	if strictDecoder, ok := decoder.(encdec.StrictDecoder); ok && strict {
+		err = strictDecoder.DecodeStrict(r, v.Interface())
+	} else {
+		err = decoder.Decode(r, v.Interface())
+	}
+
+	if err != nil {
+		return nil, &ErrorRequestBodyDecode{err}
+	}
+
+	if strict {
+		// a decoder that can't decode a map, like the XML one, gives no presence information
+		var fields interface{}
+		if decoder.Decode(bytes.NewReader(b), &fields) != nil || !isFieldsMap(fields) {
+			fields = nil
+		}
+
+		if missing := missingRequiredFields(v.Elem(), fields, ""); len(missing) > 0 {
+			return nil, &ErrorRequestBodyRequiredFields{missing}
+		}
+	}
+
+	if isPtr {
+		return v.Interface(), nil
+	}
+
+	return v.Elem().Interface(), nil
+}
+
+// isRequiredField returns true if the field has the `rest:"required"` tag.
+func isRequiredField(field reflect.StructField) bool {
+	for _, option := range strings.Split(field.Tag.Get("rest"), ",") {
+		if option == "required" {
+			return true
+		}
+	}
+
+	return false
+}
+
+// bodyFieldName returns the json name of the field.
+func bodyFieldName(field reflect.StructField) string {
+	name := strings.Split(field.Tag.Get("json"), ",")[0]
+	if name == "" || name == "-" {
+		return field.Name
+	}
+
+	return name
+}
+
+// isFieldsMap returns true if fields is a map decoded by the JSON or YAML decoders.
+func isFieldsMap(fields interface{}) bool {
+	switch fields.(type) {
+	case map[string]interface{}, map[interface{}]interface{}:
+		return true
+	}
+
+	return false
+}
+
+// fieldValue returns the value of the field in the decoded fields map, matching the json or yaml tag names,
+// or the field name if it has none, like the decoders do, without case sensitivity like the JSON decoder.
+// It returns false if the field is not present.
+func fieldValue(fields interface{}, field reflect.StructField) (interface{}, bool) {
+	names := []string{}
+	for _, tag := range []string{"json", "yaml"} {
+		if name := strings.Split(field.Tag.Get(tag), ",")[0]; name != "" && name != "-" {
+			names = append(names, name)
+		}
+	}
+
+	if len(names) == 0 {
+		names = append(names, field.Name)
+	}
+
+	matches := func(key interface{}) bool {
+		k, ok := key.(string)
+		if !ok {
+			return false
+		}
+
+		for _, name := range names {
+			if strings.EqualFold(k, name) {
+				return true
+			}
+		}
+
+		return false
+	}
+
+	switch m := fields.(type) {
+	case map[string]interface{}:
+		for k, v := range m {
+			if matches(k) {
+				return v, true
+			}
+		}
+	case map[interface{}]interface{}:
+		for k, v := range m {
+			if matches(k) {
+				return v, true
+			}
+		}
+	}
+
+	return nil, false
+}
+
+// missingRequiredFields returns the names of the required fields that are not present in the decoded fields map,
+// or that are null, including the fields of the nested structs that are present.
+// If fields is nil, the presence is unknown, and only a required pointer field that is nil is missing.
+func missingRequiredFields(v reflect.Value, fields interface{}, prefix string) []string {
+	if v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			return nil
+		}
+
+		v = v.Elem()
+	}
+
+	if v.Kind() != reflect.Struct {
+		return nil
+	}
+
+	missing := []string{}
+
+	for i := 0; i < v.NumField(); i++ {
+		field := v.Type().Field(i)
+		if field.PkgPath != "" {
+			continue
+		}
+
+		name := prefix + bodyFieldName(field)
+		fv := v.Field(i)
+
+		if fields == nil {
+			if isRequiredField(field) && fv.Kind() == reflect.Ptr && fv.IsNil() {
+				missing = append(missing, name)
+				continue
+			}
+
+			if _, ok := fv.Interface().(time.Time); !ok {
+				missing = append(missing, missingRequiredFields(fv, nil, name+".")...)
+			}
+
+			continue
+		}
+
+		value, present := fieldValue(fields, field)
+		if isRequiredField(field) && (!present || value == nil) {
+			missing = append(missing, name)
+			continue
+		}
+
+		if _, ok := fv.Interface().(time.Time); !ok && value != nil {
+			missing = append(missing, missingRequiredFields(fv, value, name+".")...)
+		}
+	}
+
+	return missing
+}
@@ -13,6 +13,8 @@ type Resource struct {
 	Description string
 	// a unique method key is defined by a combination of a path and a HTTP method.
 	methods map[string]*Method
+	// autoMethods are the enabled automatic methods (HEAD and OPTIONS).
+	autoMethods map[string]autoMethodOptions
 	ResourceCollection
 }
 
@@ -30,6 +32,7 @@ func NewResource(name string) Resource {
 	name = strings.TrimSpace(name)
 	r := Resource{}
 	r.methods = make(map[string]*Method)
+	r.autoMethods = make(map[string]autoMethodOptions)
 	r.resources = make(map[string]Resource)
 	r.path = name
 	return r
@@ -44,6 +47,7 @@ func NewResourceP(p Parameter) Resource {
 	}
 	r := Resource{}
 	r.methods = make(map[string]*Method)
+	r.autoMethods = make(map[string]autoMethodOptions)
 	r.resources = make(map[string]Resource)
 	r.path = "{" + strings.TrimSpace(p.Name) + "}"
 	return r
@@ -127,6 +131,17 @@ func (rs *Resource) Methods() []Method {
 		ms = append(ms, *m)
 	}
 
+	for httpMethod, options := range rs.autoMethods {
+		ms = append(ms, rs.autoMethod(httpMethod, options))
+	}
+
+	// the OPTIONS method answers the CORS preflight requests of the resource methods
+	for i := range ms {
+		if strings.ToUpper(ms[i].HTTPMethod) == http.MethodOptions {
+			ms[i].Handler = rs.preflightHandler(ms[i].Handler)
+		}
+	}
+
 	return ms
 }
 
@@ -142,18 +157,33 @@ func (rs *Resource) AddMethod(method *Method) {
 	rs.checkNilMethods()
 	// prepend resource middlewares to themethod
 	method.middleware = append(rs.middleware, method.middleware...)
+	// prepend resource error mappings to the method ones
+	method.errorMappings = append(append(errorMappingCollection{}, rs.errorMappings...), method.errorMappings...)
+	// set the resource CORS policy
+	method.corsPolicy = rs.corsPolicy
+	// set the resource content encodings and cache policy, unless the method has its own
+	if method.contentEncodings == nil {
+		method.contentEncodings = rs.contentEncodings
+	}
+	if method.cachePolicy == nil {
+		method.cachePolicy = rs.cachePolicy
+	}
 	// replace the core security middleware
 	if rs.overWriteCoreSecurityMiddleware != nil {
 		method.replaceSecurityMiddleware(rs.overWriteCoreSecurityMiddleware)
 	}
 	method.buildHandler()
 	rs.methods[strings.ToUpper(method.HTTPMethod)] = method
+	rs.addAutoMethods(strings.ToUpper(method.HTTPMethod))
 }
 
 func (rs *Resource) checkNilMethods() {
 	if rs.methods == nil {
 		rs.methods = make(map[string]*Method)
 	}
+	if rs.autoMethods == nil {
+		rs.autoMethods = make(map[string]autoMethodOptions)
+	}
 }
 
 // Use adds one or more middlewares to the resources's middleware stack.
@@ -9,6 +9,17 @@ type ResourceCollection struct {
 	middleware []Middleware
 	// overWriteCoreSecurityMiddleware value nil means default core middleware is applied
 	overWriteCoreSecurityMiddleware Middleware
+	// errorMappings is the collection of the error mappings to be inherited by the methods and sub-resources
+	errorMappings errorMappingCollection
+	// autoHead and autoOptions enable the automatic HEAD and OPTIONS methods
+	autoHead    bool
+	autoOptions bool
+	// corsPolicy is the CORS policy of the methods, nil means no CORS policy
+	corsPolicy *CORSPolicy
+	// contentEncodings are the content codings of the methods, nil means no content codings
+	contentEncodings *ContentEncodings
+	// cachePolicy is the cache policy of the methods, nil means no cache policy
+	cachePolicy *CachePolicy
 }
 
 // Resources returns the collection of the resource nodes.
@@ -57,10 +68,27 @@ func (rs *ResourceCollection) ResourceP(p Parameter, fn func(r *Resource)) {
 func (rs *ResourceCollection) addResource(r *Resource) {
 	// prepend middleware from parent
 	r.middleware = append(rs.middleware, r.middleware...)
+	// prepend error mappings from parent
+	r.errorMappings = append(append(errorMappingCollection{}, rs.errorMappings...), r.errorMappings...)
 	// pass the coreSecurityMiddleware if the new resource doesn't have one
 	if r.overWriteCoreSecurityMiddleware == nil {
 		r.overWriteCoreSecurityMiddleware = rs.overWriteCoreSecurityMiddleware
 	}
+	// pass the automatic methods options
+	r.autoHead = r.autoHead || rs.autoHead
+	r.autoOptions = r.autoOptions || rs.autoOptions
+	// pass the CORS policy if the new resource doesn't have one
+	if r.corsPolicy == nil {
+		r.corsPolicy = rs.corsPolicy
+	}
+	// pass the content encodings if the new resource doesn't have them
+	if r.contentEncodings == nil {
+		r.contentEncodings = rs.contentEncodings
+	}
+	// pass the cache policy if the new resource doesn't have one
+	if r.cachePolicy == nil {
+		r.cachePolicy = rs.cachePolicy
+	}
 	rs.checkMap()
 	rs.resources[r.path] = *r
 }
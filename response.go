@@ -1,5 +1,10 @@
 package rest
 
+import (
+	"net/http"
+	"reflect"
+)
+
 // Response represents a HTTP response.
 // MutableResponseBody is an interface that represents the Http body response,
 // that can mutate after a validation or an operation, taking the outputs of this methods as inputs.
@@ -8,6 +13,14 @@ type Response struct {
 	MutableResponseBody
 	description string
 	disabled    bool
+	headers     map[string]ResponseHeader
+}
+
+// ResponseHeader describes a header of a response, for the specification.
+// The values of the headers are set by the Operation, using the Input ResponseHeader method.
+type ResponseHeader struct {
+	Description string
+	Type        reflect.Kind
 }
 
 // NewResponse returns a Response with the specified code.
@@ -25,6 +38,10 @@ func (o *operationResponseBody) Mutate(v interface{}, success bool, err error) {
 	o.body = v
 }
 
+func (o *operationResponseBody) NewResponseBody() MutableResponseBody {
+	return &operationResponseBody{}
+}
+
 type staticResponseBody struct {
 	body interface{}
 }
@@ -33,6 +50,10 @@ func (s staticResponseBody) Mutate(v interface{}, success bool, err error) {
 
 }
 
+func (s staticResponseBody) NewResponseBody() MutableResponseBody {
+	return s
+}
+
 // WithBody will set a static body property.
 // It generates a dummy MutableResponseBody implementation under the hood, that will return the given 'body' parameter without change it.
 func (r Response) WithBody(body interface{}) Response {
@@ -62,6 +83,28 @@ func (r Response) WithDescription(description string) Response {
 	return r
 }
 
+// WithHeader declares a header of the response with the given name.
+// If a header with the same name is already declared, will be replaced.
+func (r Response) WithHeader(name string, header ResponseHeader) Response {
+	// copy on write, so copies of the Response don't share the headers
+	headers := make(map[string]ResponseHeader, len(r.headers)+1)
+	for k, v := range r.headers {
+		headers[k] = v
+	}
+	headers[http.CanonicalHeaderKey(name)] = header
+	r.headers = headers
+	return r
+}
+
+// Headers returns a copy of the declared headers of the response, with the canonical header name as key.
+func (r Response) Headers() map[string]ResponseHeader {
+	headers := make(map[string]ResponseHeader, len(r.headers))
+	for k, v := range r.headers {
+		headers[k] = v
+	}
+	return headers
+}
+
 // Code returns the code property
 func (r Response) Code() int {
 	return r.code
@@ -83,3 +126,38 @@ func (r Response) Body() interface{} {
 	}
 	return r.MutableResponseBody
 }
+
+// render returns a copy of the response with a new body instance, mutated with the given operation or validation results.
+// The receiver's body is never mutated, so the same Response can be safely rendered by concurrent requests.
+func (r Response) render(entity interface{}, success bool, err error) Response {
+	if r.MutableResponseBody == nil {
+		return r
+	}
+
+	r.MutableResponseBody = newResponseBody(r.MutableResponseBody)
+	r.MutableResponseBody.Mutate(entity, success, err)
+
+	return r
+}
+
+// newResponseBody creates a new instance of the given MutableResponseBody.
+// If it doesn't implement MutableResponseBodyFactory and is a pointer, a shallow copy of the pointed value is returned.
+func newResponseBody(body MutableResponseBody) MutableResponseBody {
+	if factory, ok := body.(MutableResponseBodyFactory); ok {
+		return factory.NewResponseBody()
+	}
+
+	v := reflect.ValueOf(body)
+	if v.Kind() != reflect.Ptr || v.IsNil() {
+		return body
+	}
+
+	newBody := reflect.New(v.Elem().Type())
+	newBody.Elem().Set(v.Elem())
+
+	if mutableBody, ok := newBody.Interface().(MutableResponseBody); ok {
+		return mutableBody
+	}
+
+	return body
+}
@@ -1,6 +1,9 @@
 package rest_test
 
 import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
 	"reflect"
 	"testing"
 
@@ -83,3 +86,73 @@ func TestWithOperationResultBody(t *testing.T) {
 		t.Errorf("got: %#v want: %#v", got, want)
 	}
 }
+
+type MutableBodyFactoryStub struct {
+	Message string
+}
+
+func (mr *MutableBodyFactoryStub) Mutate(v interface{}, success bool, err error) {
+	mr.Message += errorMessage
+}
+
+func (mr *MutableBodyFactoryStub) NewResponseBody() rest.MutableResponseBody {
+	return &MutableBodyFactoryStub{Message: "new:"}
+}
+
+func TestMutableResponseBodyFactory(t *testing.T) {
+	mutableResponseBody := &MutableBodyFactoryStub{}
+	successResponse := rest.NewResponse(200).WithMutableBody(mutableResponseBody)
+	m := rest.NewMethod("GET", rest.NewMethodOperation(&OperationStub{}, successResponse), mustGetCTS())
+	for i := 0; i < 2; i++ {
+		req, _ := http.NewRequest("GET", "/", nil)
+		resp := httptest.NewRecorder()
+		m.ServeHTTP(resp, req)
+		got := &MutableBodyFactoryStub{}
+		json.NewDecoder(resp.Body).Decode(got)
+		want := &MutableBodyFactoryStub{Message: "new:" + errorMessage}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("got: %#v want: %#v", got, want)
+		}
+	}
+	if mutableResponseBody.Message != "" {
+		t.Errorf("not expecting the declared body to be mutated, got: %v", mutableResponseBody.Message)
+	}
+}
+
+func TestWithHeader(t *testing.T) {
+	location := rest.ResponseHeader{Description: "URL of the new car", Type: reflect.String}
+	r := rest.NewResponse(201)
+	withLocation := r.WithHeader("location", location)
+	withRetry := withLocation.WithHeader("Retry-After", rest.ResponseHeader{Type: reflect.Int64})
+	if len(r.Headers()) != 0 {
+		t.Errorf("was not expecting headers, got: %v", r.Headers())
+	}
+	want := map[string]rest.ResponseHeader{"Location": location}
+	if !reflect.DeepEqual(withLocation.Headers(), want) {
+		t.Errorf("got: %v want: %v", withLocation.Headers(), want)
+	}
+	if len(withRetry.Headers()) != 2 {
+		t.Errorf("got: %v want: %v", len(withRetry.Headers()), 2)
+	}
+}
+
+func TestOperationResponseHeader(t *testing.T) {
+	operation := rest.OperationFunc(func(i rest.Input) (interface{}, bool, error) {
+		i.ResponseHeader().Set("Location", "/car/1")
+		return Car{ID: 1}, true, nil
+	})
+	successResponse := rest.NewResponse(201).WithOperationResultBody(Car{}).
+		WithHeader("Location", rest.ResponseHeader{Type: reflect.String})
+	m := rest.NewMethod("POST", rest.NewMethodOperation(operation, successResponse), mustGetJSONContentType())
+	req, _ := http.NewRequest("POST", "/", nil)
+	resp := httptest.NewRecorder()
+	m.ServeHTTP(resp, req)
+	assertResponseCode(t, resp, 201)
+	assertStringEqual(t, resp.Header().Get("Location"), "/car/1")
+	got := Car{}
+	err := json.NewDecoder(resp.Body).Decode(&got)
+	assertNoErrorFatal(t, err)
+	if got.ID != 1 {
+		t.Errorf("got: %v want: %v", got.ID, 1)
+	}
+}
@@ -5,6 +5,8 @@ package rest
 // this mean that the user need to pass all the SecuritySchemes defined in the same Security.
 type Security struct {
 	SecuritySchemes []*SecurityScheme
+	// Scopes are the scopes required by the method, by security scheme name.
+	Scopes map[string][]string
 }
 
 // SecurityScheme contains the authentication and authorization data, and methods.
@@ -34,6 +36,14 @@ type Authenticator interface {
 	Authenticate(Input) AuthError
 }
 
+// ScopedAuthenticator is an Authenticator that also returns the scopes granted to the request,
+// so the scopes required by a method with WithSecurityScopes can be checked.
+// AuthenticateScopes is called instead of Authenticate when the method requires scopes.
+type ScopedAuthenticator interface {
+	Authenticator
+	AuthenticateScopes(Input) ([]string, AuthError)
+}
+
 // The AuthenticatorFunc type is an adapter to allow the use of
 // ordinary functions as Authenticator. If f is a function
 // with the appropriate signature, AuthenticatorFunc(f) is a
@@ -1,7 +1,10 @@
 package rest_test
 
 import (
+	"net/http"
+	"net/http/httptest"
 	"reflect"
+	"strings"
 
 	"testing"
 
@@ -153,3 +156,79 @@ func TestWithOAuth2Flow(t *testing.T) {
 		t.Errorf("\ngot:\n %#v \nwant:\n %#v", got, want)
 	}
 }
+
+type scopedAuthenticator struct{}
+
+func (scopedAuthenticator) Authenticate(i rest.Input) rest.AuthError {
+	_, err := scopedAuthenticator{}.AuthenticateScopes(i)
+	return err
+}
+
+func (scopedAuthenticator) AuthenticateScopes(i rest.Input) ([]string, rest.AuthError) {
+	token := i.Request.Header.Get("Authorization")
+	if token == "" {
+		return nil, rest.ErrorAuthentication{Message: "missing token"}
+	}
+	return strings.Fields(token), nil
+}
+
+func TestWithSecurityScopes(t *testing.T) {
+	so := rest.SecurityOperation{
+		Authenticator:                scopedAuthenticator{},
+		FailedAuthenticationResponse: rest.NewResponse(401),
+		FailedAuthorizationResponse:  rest.NewResponse(403),
+	}
+	scheme := rest.NewOAuth2SecurityScheme("oauth", so).
+		WithImplicitOAuth2Flow("http://localhost/auth", map[string]string{"read:cars": "read", "write:cars": "write"})
+	m := rest.NewMethod("POST", moTest, mustGetJSONContentType()).WithSecurityScopes(scheme, "read:cars", "write:cars")
+	tt := []struct {
+		name     string
+		granted  string
+		wantCode int
+	}{
+		{"all scopes", "read:cars write:cars", 200},
+		{"more scopes", "admin read:cars write:cars", 200},
+		{"missing scope", "read:cars", 403},
+		{"not authenticated", "", 401},
+	}
+	for _, test := range tt {
+		t.Run(test.name, func(t *testing.T) {
+			req, _ := http.NewRequest("POST", "/", nil)
+			req.Header.Set("Authorization", test.granted)
+			resp := httptest.NewRecorder()
+			m.ServeHTTP(resp, req)
+			assertResponseCode(t, resp, test.wantCode)
+		})
+	}
+	t.Run("or logic", func(t *testing.T) {
+		m := rest.NewMethod("POST", moTest, mustGetJSONContentType()).
+			WithSecurityScopes(scheme, "admin").
+			WithSecurityScopes(scheme, "write:cars")
+		req, _ := http.NewRequest("POST", "/", nil)
+		req.Header.Set("Authorization", "write:cars")
+		resp := httptest.NewRecorder()
+		m.ServeHTTP(resp, req)
+		assertResponseCode(t, resp, 200)
+	})
+	t.Run("authenticator without scopes", func(t *testing.T) {
+		so := so
+		so.Authenticator = rest.AuthenticatorFunc(func(i rest.Input) rest.AuthError {
+			return nil
+		})
+		m := rest.NewMethod("POST", moTest, mustGetJSONContentType()).
+			WithSecurityScopes(rest.NewOAuth2SecurityScheme("oauth", so), "read:cars")
+		req, _ := http.NewRequest("POST", "/", nil)
+		resp := httptest.NewRecorder()
+		m.ServeHTTP(resp, req)
+		assertResponseCode(t, resp, 403)
+	})
+	t.Run("security", func(t *testing.T) {
+		want := rest.Security{
+			SecuritySchemes: []*rest.SecurityScheme{scheme},
+			Scopes:          map[string][]string{"oauth": {"read:cars", "write:cars"}},
+		}
+		if !reflect.DeepEqual(m.SecurityCollection, []rest.Security{want}) {
+			t.Errorf("\ngot:\n %#v \nwant:\n %#v", m.SecurityCollection, want)
+		}
+	})
+}
@@ -3,7 +3,6 @@ package petstore
 import (
 	"encoding/xml"
 	"log"
-	"strconv"
 
 	"github.com/ehsoc/rest"
 )
@@ -11,13 +10,11 @@ import (
 var PetStore = NewStore()
 
 func operationCreate(i rest.Input) (interface{}, bool, error) {
-	pet := Pet{}
-	body, _ := i.GetBody()
-	err := i.BodyDecoder.Decode(body, &pet)
+	body, err := i.DecodeBody()
 	if err != nil {
 		return nil, false, err
 	}
-	pet, err = PetStore.Create(pet)
+	pet, err := PetStore.Create(body.(Pet))
 	if err != nil {
 		return pet, false, err
 	}
@@ -25,14 +22,14 @@ func operationCreate(i rest.Input) (interface{}, bool, error) {
 }
 
 func operationUpdate(i rest.Input) (interface{}, bool, error) {
-	pet := Pet{}
-	body, _ := i.GetBody()
-	err := i.BodyDecoder.Decode(body, &pet)
+	body, err := i.DecodeBody()
 	if err != nil {
 		log.Println("error updating pet: ", err)
 		return nil, false, err
 	}
-	pet, err = PetStore.Update(strconv.FormatInt(pet.ID, 10), pet)
+	pet := body.(Pet)
+	// the store checks the If-Match header again, atomically with the update
+	pet, err = PetStore.Update(pet.ID, pet, i.Request.Header.Get("If-Match"))
 	if err != nil {
 		return pet, false, err
 	}
@@ -40,9 +37,8 @@ func operationUpdate(i rest.Input) (interface{}, bool, error) {
 }
 
 func operationGetPetByID(i rest.Input) (interface{}, bool, error) {
-	petID, err := i.GetURIParam("petId")
+	petID, err := i.GetURIParamInt64("petId")
 	if err != nil {
-		log.Fatal(err)
 		return nil, false, err
 	}
 	pet, err := PetStore.Get(petID)
@@ -53,13 +49,30 @@ func operationGetPetByID(i rest.Input) (interface{}, bool, error) {
 		}
 		return pet, false, err
 	}
+	etag, err := rest.EntityTag(pet, false)
+	if err != nil {
+		return pet, false, err
+	}
+	i.ResponseHeader().Set("ETag", etag)
 	return pet, true, nil
 }
 
+// currentPetETag is the entity tag of the stored pet with the ID of the request body.
+func currentPetETag(i rest.Input) (string, error) {
+	body, err := i.DecodeBody()
+	if err != nil {
+		return "", err
+	}
+	return PetStore.ETag(body.(Pet).ID)
+}
+
 func operationDeletePet(i rest.Input) (interface{}, bool, error) {
-	petID, _ := i.GetURIParam("petId")
+	petID, err := i.GetURIParamInt64("petId")
+	if err != nil {
+		return nil, false, err
+	}
 	log.Println("Deleting pet id:", petID)
-	err := PetStore.Delete(petID)
+	err = PetStore.Delete(petID)
 	if err != nil {
 		return nil, false, err
 	}
@@ -67,10 +80,13 @@ func operationDeletePet(i rest.Input) (interface{}, bool, error) {
 }
 
 func operationUploadImage(i rest.Input) (interface{}, bool, error) {
-	petID, _ := i.GetURIParam("petId")
+	petID, err := i.GetURIParamInt64("petId")
+	if err != nil {
+		return nil, false, err
+	}
 	log.Println("Uploading image pet id:", petID)
 	fb, _, _ := i.GetFormFile("file")
-	err := PetStore.UploadPhoto(petID, fb)
+	err = PetStore.UploadPhoto(petID, fb)
 	if err != nil {
 		return nil, false, err
 	}
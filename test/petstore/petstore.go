@@ -1,9 +1,6 @@
 package petstore
 
 import (
-	"bytes"
-	"io"
-	"io/ioutil"
 	"reflect"
 
 	"github.com/ehsoc/rest"
@@ -49,18 +46,11 @@ func GeneratePetStore() rest.API {
 		r.Put(update, ct).
 			WithRequestBody("Pet object that needs to be added to the store", Pet{}).
 			WithSummary("Update an existing pet").
+			WithIfMatch(currentPetETag).
 			WithValidation(rest.Validation{
 				Validator: rest.ValidatorFunc(func(input rest.Input) error {
-					pet := Pet{}
-					body, _ := input.GetBody()
-					respBody := new(bytes.Buffer)
-					cBody := io.TeeReader(body, respBody)
-					err := input.BodyDecoder.Decode(cBody, &pet)
-					if err != nil {
-						return err
-					}
-					input.Request.Body = ioutil.NopCloser(respBody)
-					return nil
+					_, err := input.DecodeBody()
+					return err
 				}),
 				Response: rest.NewResponse(400).WithDescription("Invalid ID supplied")})
 
@@ -85,19 +75,20 @@ func GeneratePetStore() rest.API {
 			r.Get(getByID, ct).
 				WithSummary("Find pet by ID").
 				WithDescription("Returns a single pet").
+				WithETag(false).
 				WithParameter(petIDURIParam).
 				WithSecurity(apiKeyScheme)
 			// Delete
 			deleteByID := rest.NewMethodOperation(rest.OperationFunc(operationDeletePet), rest.NewResponse(200)).WithFailResponse(notFoundResponse)
 			r.Delete(deleteByID, ct).
 				WithSummary("Deletes a pet").
+				MapError(rest.ErrorIs(ErrorPetNotFound), notFoundResponse).
 				WithParameter(
 					petIDURIParam.WithDescription("Pet id to delete").
 						WithValidation(
 							rest.Validation{
 								Validator: rest.ValidatorFunc(func(i rest.Input) error {
-									petID, _ := i.GetURIParam("petId")
-									_, err := getInt64Id(petID)
+									_, err := i.GetURIParamInt64("petId")
 									if err != nil {
 										return err
 									}
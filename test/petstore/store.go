@@ -4,10 +4,10 @@ import (
 	"errors"
 	"fmt"
 	"log"
-	"strconv"
 	"sync"
 	"time"
 
+	"github.com/ehsoc/rest"
 	"github.com/spf13/afero"
 )
 
@@ -28,19 +28,7 @@ func NewStore() *Store {
 	return &store
 }
 
-func getInt64Id(stringID string) (int64, error) {
-	id, err := strconv.Atoi(stringID)
-	if err != nil {
-		return 0, err
-	}
-	return int64(id), nil
-}
-
-func (s *Store) Get(petID string) (Pet, error) {
-	id, err := getInt64Id(petID)
-	if err != nil {
-		return Pet{}, err
-	}
+func (s *Store) Get(id int64) (Pet, error) {
 	log.Printf("searching pet id: %d\n", id)
 	if pet, ok := s.store[id]; ok {
 		log.Printf("pet found id: %d\n", id)
@@ -58,30 +46,45 @@ func (s *Store) Create(pet Pet) (Pet, error) {
 	return pet, nil
 }
 
-func (s *Store) Update(petID string, pet Pet) (Pet, error) {
-	id, err := getInt64Id(petID)
-	if err != nil {
-		return Pet{}, err
-	}
-	petFound, err := s.Get(petID)
+// ETag returns the entity tag of the stored pet, or an empty string if the pet doesn't exist.
+func (s *Store) ETag(id int64) (string, error) {
+	pet, err := s.Get(id)
 	if err != nil {
-		return petFound, err
+		if err == ErrorPetNotFound {
+			return "", nil
+		}
+		return "", err
 	}
+	return rest.EntityTag(pet, false)
+}
+
+// Update replaces the stored pet. If ifMatch is not empty, the pet is only replaced if its entity tag matches ifMatch,
+// comparing and replacing it under the same lock, so concurrent updates with the same If-Match header can't both succeed.
+func (s *Store) Update(id int64, pet Pet, ifMatch string) (Pet, error) {
 	s.mutex.Lock()
 	defer s.mutex.Unlock()
+	petFound, ok := s.store[id]
+	if !ok {
+		return Pet{}, ErrorPetNotFound
+	}
+	if ifMatch != "" {
+		etag, err := rest.EntityTag(petFound, false)
+		if err != nil {
+			return petFound, err
+		}
+		if !rest.ETagMatches(ifMatch, etag) {
+			return petFound, &rest.ErrorPreconditionFailed{IfMatch: ifMatch, ETag: etag}
+		}
+	}
 	s.store[id] = pet
 	return pet, nil
 }
 
-func (s *Store) Delete(petID string) error {
-	id, err := getInt64Id(petID)
-	if err != nil {
-		return err
-	}
+func (s *Store) Delete(id int64) error {
 	s.mutex.Lock()
 	defer s.mutex.Unlock()
 	if _, ok := s.store[id]; !ok {
-		return errors.New("pet not found")
+		return ErrorPetNotFound
 	}
 	delete(s.store, id)
 	return nil
@@ -95,16 +98,12 @@ func (s *Store) List() ([]Pet, error) {
 	return list, nil
 }
 
-func (s *Store) UploadPhoto(petID string, fileContent []byte) error {
-	id, err := getInt64Id(petID)
-	if err != nil {
-		return err
-	}
+func (s *Store) UploadPhoto(id int64, fileContent []byte) error {
 	s.mutex.Lock()
 	defer s.mutex.Unlock()
 
 	if pet, ok := s.store[id]; ok {
-		url := fmt.Sprintf("files/%s%d", petID, time.Now().UnixNano())
+		url := fmt.Sprintf("files/%d%d", id, time.Now().UnixNano())
 		err := afero.WriteFile(s.InMemoryFs, url, fileContent, 0655)
 		if err != nil {
 			return err